@@ -0,0 +1,139 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/internal/source"
+	"github.com/uwu-tools/peribolos/options/root"
+	"github.com/uwu-tools/peribolos/org"
+)
+
+// Access reports the effective permission of users on repos, and the org
+// role or team path granting it, for access reviews.
+//
+// The permissions are computed from the config, or from the live state of
+// the orgs of the config with --live.
+func Access() *cobra.Command {
+	o := root.NewOptions()
+	var user, repo string
+	var live bool
+
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Report the effective permission of users on repos and what grants it",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if o.Config == "" {
+				return errors.New("--config-path required")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return accessCmd(&o, user, repo, live)
+		},
+	}
+
+	o.AddFlags(cmd)
+	cmd.Flags().StringVar(
+		&user,
+		"user",
+		"",
+		"Only report the access of this user",
+	)
+	cmd.Flags().StringVar(
+		&repo,
+		"repo",
+		"",
+		"Only report the access to this repo",
+	)
+	cmd.Flags().BoolVar(
+		&live,
+		"live",
+		false,
+		"Report the access in the orgs on GitHub instead of the config",
+	)
+	return cmd
+}
+
+func accessCmd(o *root.Options, user, repo string, live bool) error {
+	cfg := loadResolvedConfig(o)
+	if live {
+		cfg = dumpOrgs(o, cfg)
+	}
+	orgs := make([]string, 0, len(cfg.Orgs))
+	for name := range cfg.Orgs {
+		orgs = append(orgs, name)
+	}
+	sort.Strings(orgs)
+
+	for _, name := range orgs {
+		for _, g := range config.EffectiveAccess(cfg.Orgs[name].Access(time.Now())) {
+			if user != "" && g.User != github.NormLogin(user) || repo != "" && g.Repo != repo {
+				continue
+			}
+			fmt.Printf("%s: %s\n", name, g)
+		}
+	}
+	return nil
+}
+
+// loadResolvedConfig returns the config with the sourced members of the
+// orgs resolved.
+func loadResolvedConfig(o *root.Options) config.FullConfig {
+	cfg := loadConfig(o, nil)
+	sources := sourceOptions(o)
+	for name, orgcfg := range cfg.Orgs {
+		if err := source.Resolve(&orgcfg, sources); err != nil {
+			logrus.WithError(err).Fatalf("Could not resolve the members of %s", name)
+		}
+		cfg.Orgs[name] = orgcfg
+	}
+	return cfg
+}
+
+// dumpOrgs returns the live state of the orgs of cfg, leaving out their
+// ignored teams and repos.
+func dumpOrgs(o *root.Options, cfg config.FullConfig) config.FullConfig {
+	client, err := ghclient.New(o.GithubOpts, o.GitHubEndpoints(), true)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting GitHub client.")
+	}
+	policy := loadPolicy(o)
+	out := config.FullConfig{Orgs: map[string]config.Config{}}
+	for name, orgcfg := range cfg.Orgs {
+		ignored, err := org.IgnoredBy(o.ForOrg(policy, name).Ignored, orgcfg)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Invalid %s ignore patterns", name)
+		}
+		dumped, err := org.Dump(client, name, o.IgnoreSecretTeams, o.GithubOpts.AppID, ignored)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Dump %s failed to collect current data.", name)
+		}
+		out.Orgs[name] = *dumped
+	}
+	return out
+}
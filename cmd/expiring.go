@@ -0,0 +1,44 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/peribolos/options/expiring"
+)
+
+func Expiring() *cobra.Command {
+	o := expiring.NewOptions()
+
+	cmd := &cobra.Command{
+		Use:   "expiring",
+		Short: "List memberships and team repo permissions expiring soon",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(os.Stdout, time.Now())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,54 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/peribolos/options/codeowners"
+)
+
+func Gen() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate files from the org config",
+	}
+
+	cmd.AddCommand(GenCodeOwners())
+	return cmd
+}
+
+func GenCodeOwners() *cobra.Command {
+	o := codeowners.NewOptions()
+
+	cmd := &cobra.Command{
+		Use:   "codeowners",
+		Short: "Render CODEOWNERS and OWNERS files from the code owners of the repos, or verify a CODEOWNERS file",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(os.Stdout, time.Now())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
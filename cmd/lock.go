@@ -0,0 +1,101 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/options/root"
+	"github.com/uwu-tools/peribolos/org"
+)
+
+// Lock writes the IDs of the users, teams and repos of the config to the
+// peribolos.lock lockfile next to it, which the sync uses to match renamed
+// users, teams and repos.
+//
+// An existing lockfile is only checked, unless --update is set.
+func Lock() *cobra.Command {
+	o := root.NewOptions()
+	var update bool
+
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Pin the IDs of the users, teams and repos of the config",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if o.Config == "" {
+				return errors.New("--config-path required")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lockCmd(&o, update)
+		},
+	}
+
+	o.AddFlags(cmd)
+	cmd.Flags().BoolVar(
+		&update,
+		"update",
+		false,
+		"Rewrite the lockfile with the current IDs instead of checking it",
+	)
+	return cmd
+}
+
+func lockCmd(o *root.Options, update bool) error {
+	client, err := ghclient.New(o.GithubOpts, o.GitHubEndpoints(), true)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting GitHub client.")
+	}
+
+	path := lockPath(o)
+	previous := loadLock(o)
+	_, statErr := os.Stat(path)
+	cfg := loadConfig(o, client)
+
+	var lock config.Lock
+	for name, orgcfg := range cfg.Orgs {
+		orgLock, err := org.Lock(client, name, orgcfg, previous.Orgs[name])
+		if err != nil {
+			logrus.WithError(err).Fatalf("Could not lock the IDs of %s", name)
+		}
+		if lock.Orgs == nil {
+			lock.Orgs = map[string]config.OrgLock{}
+		}
+		lock.Orgs[name] = orgLock
+	}
+
+	if statErr == nil && !update {
+		if !reflect.DeepEqual(previous, lock) {
+			return errors.New(config.LockFileName + " is out of date, run peribolos lock --update")
+		}
+		logrus.Infof("%s is up to date.", path)
+		return nil
+	}
+	if err := lock.Write(path); err != nil {
+		return err
+	}
+	logrus.Infof("Wrote %s", path)
+	return nil
+}
@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	proworg "sigs.k8s.io/prow/pkg/config/org"
 	"sigs.k8s.io/release-utils/version"
 
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/internal/journal"
+	"github.com/uwu-tools/peribolos/internal/source"
 	"github.com/uwu-tools/peribolos/internal/yaml"
 	"github.com/uwu-tools/peribolos/options/merge"
 	"github.com/uwu-tools/peribolos/options/root"
@@ -54,26 +58,40 @@ func New(o *root.Options) *cobra.Command {
 
 	// Add sub-commands.
 	cmd.AddCommand(Merge())
+	cmd.AddCommand(Access())
+	cmd.AddCommand(AccessReview())
+	cmd.AddCommand(Expiring())
+	cmd.AddCommand(Gen())
+	cmd.AddCommand(Lock())
+	cmd.AddCommand(Rollback())
 	cmd.AddCommand(version.Version())
 
 	return cmd
 }
 
 func rootCmd(o *root.Options) error {
-	githubClient, err := o.GithubOpts.GitHubClient(!o.Confirm)
+	client, err := ghclient.New(o.GithubOpts, o.GitHubEndpoints(), !o.Confirm)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting GitHub client.")
 	}
 
 	if o.Dump != "" {
-		ret, err := org.Dump(githubClient, o.Dump, o.IgnoreSecretTeams, o.GithubOpts.AppID)
+		ignored := o.ForOrg(loadPolicy(o), o.Dump).Ignored
+		if o.Config != "" {
+			// Leave out the resources of the ignore key of the org too.
+			cfg := loadConfig(o, client)
+			if ignored, err = org.IgnoredBy(ignored, cfg.Orgs[o.Dump]); err != nil {
+				logrus.WithError(err).Fatalf("Invalid %s ignore patterns", o.Dump)
+			}
+		}
+		ret, err := org.Dump(client, o.Dump, o.IgnoreSecretTeams, o.GithubOpts.AppID, ignored)
 		if err != nil {
 			logrus.WithError(err).Fatalf("Dump %s failed to collect current data.", o.Dump)
 		}
 		var output interface{}
 		if o.DumpFull {
-			output = proworg.FullConfig{
-				Orgs: map[string]proworg.Config{o.Dump: *ret},
+			output = config.FullConfig{
+				Orgs: map[string]config.Config{o.Dump: *ret},
 			}
 		} else {
 			output = ret
@@ -89,57 +107,155 @@ func rootCmd(o *root.Options) error {
 		return nil
 	}
 
-	// Check if the config path exists
-	fileInfo, err := os.Stat(o.Config)
-	if err != nil {
-		logrus.WithError(err).Fatalf("Could not retrieve file info for %s", o.Config)
+	if err := o.LoadApprovedChanges(); err != nil {
+		logrus.WithError(err).Fatal("Could not load approved changes")
 	}
 
-	var cfg proworg.FullConfig
-	var raw []byte
-	if fileInfo.IsDir() {
-		files, err := os.ReadDir(o.Config)
+	cfg := loadConfig(o, client)
+	lock := loadLock(o)
+
+	policy := loadPolicy(o)
+	sources := sourceOptions(o)
+	o.Journaled = loadJournal(o)
+	defer openJournal(o, client)()
+	for name, orgcfg := range cfg.Orgs {
+		if err := source.Resolve(&orgcfg, sources); err != nil {
+			logrus.WithError(err).Fatalf("Could not resolve the members of %s", name)
+		}
+		orgOpts := o.ForOrg(policy, name)
+		orgOpts.Locked = lock.Orgs[name]
+		ignored, err := org.IgnoredBy(orgOpts.Ignored, orgcfg)
 		if err != nil {
-			logrus.WithError(err).Fatalf("Could not %s directory", o.Config)
+			logrus.WithError(err).Fatalf("Invalid %s ignore patterns", name)
+		}
+		snapshot(o, client, name, ignored)
+		if err := org.Configure(orgOpts, client, name, orgcfg); err != nil {
+			logrus.Fatalf("Configuration failed: %v", err)
 		}
+	}
 
-		mergeOpts := merge.NewOptions()
-		mergeOpts.MergeTeams = true
-		configFileName := "org.yaml"
-		for _, f := range files {
-			if f.IsDir() {
-				orgName := f.Name()
-				configPath := filepath.Join(o.Config, orgName, configFileName)
+	logrus.Info("Finished syncing configuration.")
 
-				logrus.Infof("Adding config for org: %s", orgName)
-				mergeOpts.Orgs[orgName] = configPath
-			}
-		}
+	return nil
+}
 
-		mergedConfig, err := mergeOpts.Run()
-		if err != nil {
-			logrus.WithError(err).Fatal("Merging org configs")
-		}
+// loadConfig returns the --config-path file, or the merged configs of the
+// orgs in the --config-path directory, with their people resolved. Renamed
+// people are only followed with a client.
+func loadConfig(o *root.Options, client *ghclient.Client) config.FullConfig {
+	var users merge.UserLookup
+	if client != nil {
+		users = client
+	}
+	cfg, err := merge.LoadPath(o.Config, o.People, users)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load --config-path")
+	}
+	return *cfg
+}
+
+// lockPath returns the path of the lockfile next to the --config-path file,
+// or in the --config-path directory.
+func lockPath(o *root.Options) string {
+	if fileInfo, err := os.Stat(o.Config); err == nil && fileInfo.IsDir() {
+		return filepath.Join(o.Config, config.LockFileName)
+	}
+	return filepath.Join(filepath.Dir(o.Config), config.LockFileName)
+}
 
-		cfg = *mergedConfig
-	} else {
-		raw, err = os.ReadFile(o.Config)
+// loadLock returns the lockfile of the config, or an empty lock when there
+// is none.
+func loadLock(o *root.Options) config.Lock {
+	path := lockPath(o)
+	if _, err := os.Stat(path); err != nil {
+		return config.Lock{}
+	}
+	lock, err := config.LoadLock(path)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load the lockfile")
+	}
+	return *lock
+}
+
+// loadPolicy returns the --policy file, or nil when unset.
+func loadPolicy(o *root.Options) *config.Policy {
+	if o.Policy == "" {
+		return nil
+	}
+	policy, err := config.LoadPolicy(o.Policy)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load --policy file")
+	}
+	return policy
+}
+
+// sourceOptions returns the settings of the member sources.
+func sourceOptions(o *root.Options) source.Options {
+	opts := source.Options{
+		LDAP: source.LDAPOptions{URL: o.LDAPURL, BindDN: o.LDAPBindDN},
+	}
+	if o.LDAPPasswordPath != "" {
+		password, err := os.ReadFile(o.LDAPPasswordPath)
 		if err != nil {
-			logrus.WithError(err).Fatal("Could not read --config-path file")
+			logrus.WithError(err).Fatal("Could not read --ldap-password-path file")
 		}
+		opts.LDAP.Password = strings.TrimSpace(string(password))
+	}
+	return opts
+}
 
-		if err := yaml.Unmarshal(raw, &cfg); err != nil {
-			logrus.WithError(err).Fatal("Failed to load configuration")
-		}
+// loadJournal returns the last changes recorded in the journal, when
+// requested.
+func loadJournal(o *root.Options) map[journal.Key]journal.Entry {
+	if o.Journal == "" {
+		return nil
+	}
+	journaled, err := journal.Latest(o.Journal)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not read --journal file")
 	}
+	return journaled
+}
 
-	for name, orgcfg := range cfg.Orgs {
-		if err := org.Configure(*o, githubClient, name, orgcfg); err != nil {
-			logrus.Fatalf("Configuration failed: %v", err)
+// openJournal records the changes applied through client in the journal,
+// when requested. The returned func closes the journal.
+func openJournal(o *root.Options, client *ghclient.Client) func() {
+	if !o.Confirm || o.Journal == "" {
+		return func() {}
+	}
+	actor := "app:" + o.GithubOpts.AppID
+	if o.GithubOpts.AppID == "" {
+		user, err := client.BotUser()
+		if err != nil {
+			logrus.WithError(err).Fatal("Journal failed to get the bot user.")
 		}
+		actor = user.Login
 	}
+	j, err := journal.Open(o.Journal, actor, o.ConfigSHA)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not open --journal file")
+	}
+	client.SetJournal(j)
+	return func() {
+		if err := j.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close the journal")
+		}
+	}
+}
 
-	logrus.Info("Finished syncing configuration.")
-
-	return nil
+// snapshot records the state of the org before mutating it, when requested.
+// Ignored resources are left out of the snapshot.
+func snapshot(o *root.Options, client *ghclient.Client, orgName string, ignored config.Patterns) {
+	if !o.Confirm || o.SnapshotDir == "" {
+		return
+	}
+	s, err := org.TakeSnapshot(client, orgName, o.IgnoreSecretTeams, o.GithubOpts.AppID, ignored)
+	if err != nil {
+		logrus.WithError(err).Fatalf("Snapshot of %s failed to collect current data.", orgName)
+	}
+	path, err := s.Write(o.SnapshotDir)
+	if err != nil {
+		logrus.WithError(err).Fatalf("Snapshot of %s failed.", orgName)
+	}
+	logrus.Infof("Wrote snapshot of %s to %s", orgName, path)
 }
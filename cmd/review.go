@@ -0,0 +1,88 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/peribolos/options/review"
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+// AccessReview exports every access of the users of the orgs, with the
+// config file declaring it and the day it last changed according to git
+// blame, for auditors.
+//
+// With --live, the export also holds the access only found on GitHub, and
+// tells whether each access is declared, live or both.
+func AccessReview() *cobra.Command {
+	o := root.NewOptions()
+	r := review.NewOptions()
+
+	cmd := &cobra.Command{
+		Use:   "access-review",
+		Short: "Export the access of the users of the orgs as CSV or JSON for access reviews",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if o.Config == "" {
+				return errors.New("--config-path required")
+			}
+			return r.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return accessReviewCmd(&o, r)
+		},
+	}
+
+	o.AddFlags(cmd)
+	r.AddFlags(cmd)
+	return cmd
+}
+
+func accessReviewCmd(o *root.Options, r *review.Options) error {
+	now := time.Now()
+	cfg := loadResolvedConfig(o)
+	origin, err := review.NewOrigin(o.Config)
+	if err != nil {
+		return err
+	}
+	orgs := make([]string, 0, len(cfg.Orgs))
+	for name := range cfg.Orgs {
+		orgs = append(orgs, name)
+	}
+	sort.Strings(orgs)
+
+	var rows, live []review.Row
+	for _, name := range orgs {
+		rows = append(rows, review.Rows(name, cfg.Orgs[name], now, origin)...)
+	}
+	if r.Live {
+		dumped := dumpOrgs(o, cfg)
+		for _, name := range orgs {
+			live = append(live, review.Rows(name, dumped.Orgs[name], now, nil)...)
+		}
+	}
+	if err := r.Run(rows, live); err != nil {
+		return err
+	}
+	logrus.Infof("Exported the access of %d orgs.", len(orgs))
+	return nil
+}
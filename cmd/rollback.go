@@ -0,0 +1,91 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/options/root"
+	"github.com/uwu-tools/peribolos/org"
+)
+
+// Rollback restores an org to a snapshot written with --snapshot-dir.
+//
+// The snapshot is applied as the desired config, so the --fix-* flags
+// select what is restored and --confirm is required to mutate GitHub.
+func Rollback() *cobra.Command {
+	o := root.NewOptions()
+	var snapshotPath string
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore an org to a snapshot taken before a sync",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if snapshotPath == "" {
+				return errors.New("--snapshot required")
+			}
+			if o.Config != "" || o.Dump != "" {
+				return errors.New("--config-path and --dump cannot be used with rollback")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rollbackCmd(&o, snapshotPath)
+		},
+	}
+
+	o.AddFlags(cmd)
+	cmd.Flags().StringVar(
+		&snapshotPath,
+		"snapshot",
+		"",
+		"Path to the snapshot file to restore",
+	)
+	return cmd
+}
+
+func rollbackCmd(o *root.Options, snapshotPath string) error {
+	s, err := org.ReadSnapshot(snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	if err := o.LoadApprovedChanges(); err != nil {
+		return err
+	}
+
+	client, err := ghclient.New(o.GithubOpts, o.GitHubEndpoints(), !o.Confirm)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting GitHub client.")
+	}
+
+	logrus.Infof("Rolling back %s to the snapshot taken at %s", s.Org, s.Time)
+	defer openJournal(o, client)()
+	orgOpts := o.ForOrg(loadPolicy(o), s.Org)
+	rollback := s.RollbackConfig()
+	snapshot(o, client, s.Org, orgOpts.Ignored.Merge(rollback.Ignore))
+	if err := org.Configure(orgOpts, client, s.Org, rollback); err != nil {
+		logrus.Fatalf("Rollback failed: %v", err)
+	}
+
+	logrus.Info("Finished rolling back.")
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package blame reads when each line of a file tracked by git last
+// changed.
+package blame
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File returns the time of the commit which last changed each line of the
+// file at path, the first line first. Lines not committed yet are changed
+// at the time git blame runs.
+func File(path string) ([]time.Time, error) {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return Parse(bytes.NewReader(out))
+}
+
+// Parse reads the output of git blame --line-porcelain.
+func Parse(r io.Reader) ([]time.Time, error) {
+	var lines []time.Time
+	var committed time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			lines = append(lines, committed)
+		case strings.HasPrefix(line, "committer-time "):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(line, "committer-time "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid git blame line %q: %w", line, err)
+			}
+			committed = time.Unix(seconds, 0).UTC()
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// Last returns the latest of times, or the zero time when there are none.
+func Last(times ...time.Time) time.Time {
+	var last time.Time
+	for _, t := range times {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
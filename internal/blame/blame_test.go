@@ -0,0 +1,65 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package blame
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	out := `0123456789012345678901234567890123456789 1 1 2
+author Alice
+author-time 1767225600
+committer Alice
+committer-time 1767225600
+summary Add foo
+filename org.yaml
+	admins:
+0123456789012345678901234567890123456789 2 2
+author Alice
+author-time 1767225600
+committer Alice
+committer-time 1767225600
+summary Add foo
+filename org.yaml
+	- alice
+abcdefabcdefabcdefabcdefabcdefabcdefabcd 3 3 1
+author Bob
+author-time 1772323200
+committer Bob
+committer-time 1772409600
+summary Add bob
+filename org.yaml
+	- bob
+`
+	actual, err := Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []time.Time{
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected times (-want +got):\n%s", diff)
+	}
+}
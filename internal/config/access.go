@@ -0,0 +1,266 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// levelRank orders repo permission levels from the lowest.
+var levelRank = map[github.RepoPermissionLevel]int{
+	github.None:     0,
+	github.Read:     1,
+	github.Triage:   2,
+	github.Write:    3,
+	github.Maintain: 4,
+	github.Admin:    5,
+}
+
+// Level returns the permission level granted by permission, the base role
+// when it names a custom repository role, or none when it is unknown.
+func (c Config) Level(permission github.RepoPermissionLevel) github.RepoPermissionLevel {
+	if IsPermissionLevel(permission) {
+		return permission
+	}
+	if role, ok := c.CustomRepoRoles[string(permission)]; ok {
+		return role.BaseRole
+	}
+	return github.None
+}
+
+// OrgRoleLevels are the permission levels the predefined organization
+// roles grant on every repo of the org.
+var OrgRoleLevels = map[string]github.RepoPermissionLevel{
+	"all_repo_read":     github.Read,
+	"all_repo_triage":   github.Triage,
+	"all_repo_write":    github.Write,
+	"all_repo_maintain": github.Maintain,
+	"all_repo_admin":    github.Admin,
+}
+
+// Grants reports whether the have permission level includes want.
+func Grants(have, want github.RepoPermissionLevel) bool {
+	return levelRank[have] >= levelRank[want]
+}
+
+// RepoLevels returns the permission level each team has on repo at now,
+// including the access child teams inherit from their parent teams. Teams
+// without access are left out.
+func (c Config) RepoLevels(repo string, now time.Time) map[string]github.RepoPermissionLevel {
+	levels := map[string]github.RepoPermissionLevel{}
+	var walk func(teams map[string]Team, inherited github.RepoPermissionLevel)
+	walk = func(teams map[string]Team, inherited github.RepoPermissionLevel) {
+		for name, team := range teams {
+			level := inherited
+			if grant, ok := team.Repos[repo]; ok && (grant.Expires == nil || !grant.Expires.Passed(now)) {
+				if l := c.Level(grant.Permission); !Grants(level, l) {
+					level = l
+				}
+			}
+			if level != github.None {
+				levels[name] = level
+			}
+			walk(team.Children, level)
+		}
+	}
+	walk(c.Teams, github.None)
+	return levels
+}
+
+// Grant is a permission a user has on a repo, and where it comes from.
+type Grant struct {
+	User string
+	Repo string
+	// Permission is the permission level or custom repository role
+	// granted, and Level the permission level it amounts to.
+	Permission github.RepoPermissionLevel
+	Level      github.RepoPermissionLevel
+	// Team is the path of the team granting the permission to its
+	// maintainers and members, such as parent/child.
+	Team string
+	// Inherited is the ancestor team of Team declaring the permission,
+	// which GitHub grants to its child teams. It is empty when Team
+	// declares the permission.
+	Inherited string
+	// OrgRole is admin or member when the permission comes with the org
+	// role instead of a team, or the organization role assigned to Team
+	// granting the permission on every repo.
+	OrgRole string
+	// Collaborator is set when the user is a direct collaborator of Repo.
+	Collaborator bool
+}
+
+func (g Grant) String() string {
+	switch {
+	case g.OrgRole != "" && g.Team != "":
+		return fmt.Sprintf("%s has %s on %s via org role %s of team %s", g.User, g.Permission, g.Repo, g.OrgRole, g.Team)
+	case g.OrgRole == "admin":
+		return fmt.Sprintf("%s has %s on %s as org admin", g.User, g.Permission, g.Repo)
+	case g.OrgRole != "":
+		return fmt.Sprintf("%s has %s on %s as org %s by default", g.User, g.Permission, g.Repo, g.OrgRole)
+	case g.Collaborator:
+		return fmt.Sprintf("%s has %s on %s as collaborator", g.User, g.Permission, g.Repo)
+	case g.Inherited != "":
+		return fmt.Sprintf("%s has %s on %s via team %s, inherited from %s", g.User, g.Permission, g.Repo, g.Team, g.Inherited)
+	}
+	return fmt.Sprintf("%s has %s on %s via team %s", g.User, g.Permission, g.Repo, g.Team)
+}
+
+// Access returns every permission the users have on the repos at now:
+// admin on every repo for the org admins, the default repository
+// permission on every repo for the org members, the roles of the repo
+// collaborators, the permissions of the teams they are a maintainer or
+// member of, including the ones inherited from parent teams, and the
+// permission on every repo of the organization roles assigned to these
+// teams or their parents. The repos are the declared ones and the ones
+// teams have permissions on.
+//
+// The grants are sorted by user, repo and team.
+func (c Config) Access(now time.Time) []Grant {
+	effective := c.Effective(now)
+	repos := map[string]bool{}
+	for repo := range c.Repos {
+		repos[repo] = true
+	}
+
+	assigned := map[string][]string{}
+	for role, teams := range c.OrgRoles {
+		if _, ok := OrgRoleLevels[role]; !ok {
+			continue
+		}
+		for _, team := range teams {
+			assigned[team] = append(assigned[team], role)
+		}
+	}
+
+	var grants []Grant
+	type declared struct {
+		team       string
+		permission github.RepoPermissionLevel
+	}
+	type roleGrant struct {
+		login, role, team string
+	}
+	var roleGrants []roleGrant
+	var walk func(teams map[string]org.Team, path []string, ancestors map[string]declared, roles map[string]bool)
+	walk = func(teams map[string]org.Team, path []string, ancestors map[string]declared, roles map[string]bool) {
+		for name, team := range teams {
+			teamPath := append(append([]string(nil), path...), name)
+			// Child teams inherit the organization roles of their parents.
+			teamRoles := make(map[string]bool, len(roles))
+			for role := range roles {
+				teamRoles[role] = true
+			}
+			for _, role := range assigned[name] {
+				teamRoles[role] = true
+			}
+			inherited := make(map[string]declared, len(ancestors)+len(team.Repos))
+			for repo, d := range ancestors {
+				inherited[repo] = d
+			}
+			for repo, permission := range team.Repos {
+				repos[repo] = true
+				// A child team keeps the higher permission of its parent.
+				if d, ok := inherited[repo]; !ok || !Grants(c.Level(d.permission), c.Level(permission)) {
+					inherited[repo] = declared{team: name, permission: permission}
+				}
+			}
+			for _, login := range append(append([]string(nil), team.Maintainers...), team.Members...) {
+				for repo, d := range inherited {
+					g := Grant{
+						User:       github.NormLogin(login),
+						Repo:       repo,
+						Permission: d.permission,
+						Level:      c.Level(d.permission),
+						Team:       strings.Join(teamPath, "/"),
+					}
+					if d.team != name {
+						g.Inherited = d.team
+					}
+					grants = append(grants, g)
+				}
+				for role := range teamRoles {
+					roleGrants = append(roleGrants, roleGrant{login: github.NormLogin(login), role: role, team: strings.Join(teamPath, "/")})
+				}
+			}
+			walk(team.Children, teamPath, inherited, teamRoles)
+		}
+	}
+	walk(effective.Teams, nil, nil, nil)
+
+	for name, repo := range c.Repos {
+		for login, role := range repo.Collaborators {
+			grants = append(grants, Grant{User: github.NormLogin(login), Repo: name, Permission: role, Level: c.Level(role), Collaborator: true})
+		}
+	}
+	for repo := range repos {
+		for _, g := range roleGrants {
+			level := OrgRoleLevels[g.role]
+			grants = append(grants, Grant{User: g.login, Repo: repo, Permission: level, Level: level, Team: g.team, OrgRole: g.role})
+		}
+		for _, login := range effective.Admins {
+			grants = append(grants, Grant{User: github.NormLogin(login), Repo: repo, Permission: github.Admin, Level: github.Admin, OrgRole: "admin"})
+		}
+		if p := effective.Metadata.DefaultRepositoryPermission; p != nil && *p != github.None {
+			for _, login := range effective.Members {
+				grants = append(grants, Grant{User: github.NormLogin(login), Repo: repo, Permission: *p, Level: *p, OrgRole: "member"})
+			}
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		switch {
+		case a.User != b.User:
+			return a.User < b.User
+		case a.Repo != b.Repo:
+			return a.Repo < b.Repo
+		case a.OrgRole != b.OrgRole:
+			// The org roles first, then the teams.
+			return b.OrgRole == "" || a.OrgRole != "" && a.OrgRole < b.OrgRole
+		}
+		return a.Team < b.Team
+	})
+	return grants
+}
+
+// EffectiveAccess returns the grants of the highest permission level each
+// user has on each repo, which are the ones in effect, in the order of
+// grants.
+func EffectiveAccess(grants []Grant) []Grant {
+	type key struct{ user, repo string }
+	highest := map[key]github.RepoPermissionLevel{}
+	for _, g := range grants {
+		k := key{g.User, g.Repo}
+		if level, ok := highest[k]; !ok || !Grants(level, g.Level) {
+			highest[k] = g.Level
+		}
+	}
+	var out []Grant
+	for _, g := range grants {
+		if g.Level == highest[key{g.User, g.Repo}] {
+			out = append(out, g)
+		}
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+func TestRepoLevels(t *testing.T) {
+	raw := `
+custom_repo_roles:
+  reviewer:
+    base_role: triage
+teams:
+  parent:
+    repos:
+      widget: write
+    teams:
+      child:
+        repos:
+          widget: admin
+      reader:
+        repos:
+          widget: read
+  reviewers:
+    repos:
+      widget: reviewer
+  expired:
+    repos:
+      widget:
+        permission: admin
+        expires: 2026-05-31
+  other:
+    repos:
+      gadget: admin
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(raw), &cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	expected := map[string]github.RepoPermissionLevel{
+		"parent":    github.Write,
+		"child":     github.Admin,
+		"reader":    github.Write,
+		"reviewers": github.Triage,
+	}
+	if diff := cmp.Diff(expected, cfg.RepoLevels("widget", now)); diff != "" {
+		t.Errorf("unexpected levels (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccess(t *testing.T) {
+	raw := `
+default_repository_permission: read
+admins:
+- Owner
+members:
+- alice
+- bob
+- carol
+repos:
+  docs:
+    collaborators:
+      Dave: reviewer
+custom_repo_roles:
+  reviewer:
+    base_role: triage
+org_roles:
+  all_repo_triage: [parent]
+  security_manager: [temps]
+teams:
+  parent:
+    maintainers:
+    - alice
+    repos:
+      widget: write
+    teams:
+      child:
+        members:
+        - bob
+        repos:
+          widget: reviewer
+          gadget: admin
+  temps:
+    members:
+    - carol
+    repos:
+      widget:
+        permission: admin
+        expires: 2026-05-31
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(raw), &cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	grants := cfg.Access(now)
+	var actual []string
+	for _, g := range grants {
+		actual = append(actual, g.String())
+	}
+	expected := []string{
+		"alice has triage on docs via org role all_repo_triage of team parent",
+		"alice has read on docs as org member by default",
+		"alice has triage on gadget via org role all_repo_triage of team parent",
+		"alice has read on gadget as org member by default",
+		"alice has triage on widget via org role all_repo_triage of team parent",
+		"alice has read on widget as org member by default",
+		"alice has write on widget via team parent",
+		"bob has triage on docs via org role all_repo_triage of team parent/child",
+		"bob has read on docs as org member by default",
+		"bob has triage on gadget via org role all_repo_triage of team parent/child",
+		"bob has read on gadget as org member by default",
+		"bob has admin on gadget via team parent/child",
+		"bob has triage on widget via org role all_repo_triage of team parent/child",
+		"bob has read on widget as org member by default",
+		"bob has write on widget via team parent/child, inherited from parent",
+		"carol has read on docs as org member by default",
+		"carol has read on gadget as org member by default",
+		"carol has read on widget as org member by default",
+		"dave has reviewer on docs as collaborator",
+		"owner has admin on docs as org admin",
+		"owner has admin on gadget as org admin",
+		"owner has admin on widget as org admin",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected access (-want +got):\n%s", diff)
+	}
+
+	actual = nil
+	for _, g := range EffectiveAccess(grants) {
+		if g.User == "bob" {
+			actual = append(actual, g.String())
+		}
+	}
+	expected = []string{
+		"bob has triage on docs via org role all_repo_triage of team parent/child",
+		"bob has admin on gadget via team parent/child",
+		"bob has write on widget via team parent/child, inherited from parent",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected effective access (-want +got):\n%s", diff)
+	}
+}
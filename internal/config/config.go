@@ -0,0 +1,325 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package config defines the peribolos org configuration.
+//
+// The types embed the upstream prow org configuration and add the settings
+// peribolos manages on top of it. Fields declared here shadow the embedded
+// fields of the same name, both in Go and when (un)marshalling.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// FullConfig contains the configuration of every org peribolos manages.
+type FullConfig struct {
+	Orgs map[string]Config `json:"orgs,omitempty"`
+}
+
+// Config declares the desired state of an org.
+type Config struct {
+	org.Config  `json:",inline"`
+	OrgSettings `json:",inline"`
+
+	// Admins, Members and Teams may carry expiry dates, use Effective
+	// for the configuration in effect.
+	Admins  []Member        `json:"admins,omitempty"`
+	Members []Member        `json:"members,omitempty"`
+	Teams   map[string]Team `json:"teams,omitempty"`
+
+	// MembersFrom is the source of additional org members, resolved at
+	// sync time.
+	MembersFrom *Source `json:"members_from,omitempty"`
+
+	Repos map[string]Repo `json:"repos,omitempty"`
+
+	// Protected lists what the sync never removes nor demotes.
+	Protected *Protected `json:"protected,omitempty"`
+
+	// Ignore lists the teams, repos and team repo permissions managed by
+	// other tools, which are neither created, changed, deleted nor dumped.
+	Ignore *Patterns `json:"ignore,omitempty"`
+
+	// Webhooks are the org webhooks. They are not managed when nil.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+
+	// Actions is the GitHub Actions policy of the org.
+	Actions *ActionsPolicy `json:"actions,omitempty"`
+
+	// CustomRepoRoles are the custom repository roles of the org, which
+	// teams are granted by name like permission levels. They are not
+	// managed when nil.
+	CustomRepoRoles map[string]CustomRepoRole `json:"custom_repo_roles,omitempty"`
+
+	// OrgRoles are the teams assigned each organization role, such as
+	// security_manager. Roles missing from the map are not managed.
+	OrgRoles map[string][]string `json:"org_roles,omitempty"`
+}
+
+// OrgSettings declares the org settings missing from org.Metadata.
+// Settings left unset are not managed.
+type OrgSettings struct {
+	Blog            *string `json:"blog,omitempty"`
+	TwitterUsername *string `json:"twitter_username,omitempty"`
+
+	MembersCanCreatePublicRepositories   *bool `json:"members_can_create_public_repositories,omitempty"`
+	MembersCanCreatePrivateRepositories  *bool `json:"members_can_create_private_repositories,omitempty"`
+	MembersCanCreateInternalRepositories *bool `json:"members_can_create_internal_repositories,omitempty"`
+	MembersCanForkPrivateRepositories    *bool `json:"members_can_fork_private_repositories,omitempty"`
+	MembersCanCreatePages                *bool `json:"members_can_create_pages,omitempty"`
+	MembersCanCreatePublicPages          *bool `json:"members_can_create_public_pages,omitempty"`
+	MembersCanCreatePrivatePages         *bool `json:"members_can_create_private_pages,omitempty"`
+	WebCommitSignoffRequired             *bool `json:"web_commit_signoff_required,omitempty"`
+
+	// TwoFactorRequirementEnabled can only be changed in the GitHub UI,
+	// a mismatch is reported but not fixed.
+	TwoFactorRequirementEnabled *bool `json:"two_factor_requirement_enabled,omitempty"`
+
+	// Security defaults of the repos created in the org.
+	DependencyGraphEnabledForNewRepositories *bool `json:"dependency_graph_enabled_for_new_repositories,omitempty"`
+	SecretScanningEnabledForNewRepositories  *bool `json:"secret_scanning_enabled_for_new_repositories,omitempty"`
+}
+
+// Protected lists the users, teams and repos the sync refuses to remove
+// or demote, even when the config says so, unless protections are
+// explicitly overridden.
+type Protected struct {
+	// Users keep their org role and team roles.
+	Users []string `json:"users,omitempty"`
+	// Teams are not deleted and keep their repo permissions.
+	Teams []string `json:"teams,omitempty"`
+	// Repos keep the permissions teams have on them.
+	Repos []string `json:"repos,omitempty"`
+	// RepoRoles are custom repository roles which are not deleted.
+	RepoRoles []string `json:"repo_roles,omitempty"`
+}
+
+// CustomRepoRole declares a custom repository role, which grants the
+// permissions of its base role plus fine-grained permissions.
+type CustomRepoRole struct {
+	Description *string `json:"description,omitempty"`
+	// BaseRole is read, triage, write or maintain.
+	BaseRole github.RepoPermissionLevel `json:"base_role"`
+	// Permissions are the fine-grained permissions added to the base role,
+	// such as add_label or delete_alerts_code_scanning.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// IsPermissionLevel reports whether permission is one of the permission
+// levels GitHub grants without custom repository roles.
+func IsPermissionLevel(permission github.RepoPermissionLevel) bool {
+	switch permission {
+	case github.Read, github.Triage, github.Write, github.Maintain, github.Admin:
+		return true
+	}
+	return false
+}
+
+// ValidateRepoRoles returns an error if a custom repository role is
+// invalid or shadows a permission level. When the custom repository roles
+// are declared, team repo permissions must name one of them or a
+// permission level, as must the roles of repo collaborators.
+func (c Config) ValidateRepoRoles() error {
+	for name, role := range c.CustomRepoRoles {
+		if IsPermissionLevel(github.RepoPermissionLevel(name)) {
+			return fmt.Errorf("custom repo role %s shadows a permission level", name)
+		}
+		if role.BaseRole == github.Admin || !IsPermissionLevel(role.BaseRole) {
+			return fmt.Errorf("custom repo role %s has invalid base role %q", name, role.BaseRole)
+		}
+	}
+	if c.CustomRepoRoles == nil {
+		return nil
+	}
+	for name, repo := range c.Repos {
+		for login, role := range repo.Collaborators {
+			if _, ok := c.CustomRepoRoles[string(role)]; !ok && !IsPermissionLevel(role) {
+				return fmt.Errorf("collaborator %s has unknown role %s on repo %s", login, role, name)
+			}
+		}
+	}
+	var walk func(teams map[string]Team) error
+	walk = func(teams map[string]Team) error {
+		for name, team := range teams {
+			for repo, grant := range team.Repos {
+				if _, ok := c.CustomRepoRoles[string(grant.Permission)]; !ok && !IsPermissionLevel(grant.Permission) {
+					return fmt.Errorf("team %s has unknown role %s on repo %s", name, grant.Permission, repo)
+				}
+			}
+			if err := walk(team.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(c.Teams)
+}
+
+// Repo declares the desired state of a repository.
+type Repo struct {
+	org.Repo `json:",inline"`
+
+	// Delete marks the repo as a tombstone: it is deleted if it exists and
+	// --allow-repo-deletion is set, and it is never created.
+	Delete *bool `json:"delete,omitempty"`
+
+	// TransferTo is the user or org the repo is transferred to when
+	// --allow-repo-transfer is set.
+	TransferTo *string `json:"transfer_to,omitempty"`
+
+	OnCreate *RepoCreateOptions `json:"on_create,omitempty"`
+
+	// Webhooks are the repo webhooks. They are not managed when nil.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+
+	// Collaborators are the direct collaborators of the repo, by login, and
+	// their role, a permission level or a custom repository role. They are
+	// not managed when nil.
+	Collaborators map[string]github.RepoPermissionLevel `json:"collaborators,omitempty"`
+
+	// Actions overrides the GitHub Actions policy of the org for the repo.
+	Actions *ActionsPolicy `json:"actions,omitempty"`
+
+	// CodeOwners are the teams owning the paths of the repo, rendered by
+	// peribolos gen codeowners. Like in CODEOWNERS, the last matching
+	// path wins.
+	CodeOwners []CodeOwner `json:"code_owners,omitempty"`
+}
+
+// CodeOwner assigns the files matching a CODEOWNERS pattern, such as * or
+// /docs/, to teams of the org.
+type CodeOwner struct {
+	Path  string   `json:"path"`
+	Teams []string `json:"teams"`
+}
+
+// RepoCreateOptions declares how a missing repo is created.
+type RepoCreateOptions struct {
+	org.RepoCreateOptions `json:",inline"`
+
+	// Template is the owner/name of the template repository the repo is
+	// generated from.
+	Template *string `json:"template,omitempty"`
+
+	// IncludeAllBranches copies every branch of the template instead of
+	// only its default branch.
+	IncludeAllBranches *bool `json:"include_all_branches,omitempty"`
+
+	// ForkOf is the owner/name of the repository the repo is forked from.
+	ForkOf *string `json:"fork_of,omitempty"`
+}
+
+// Values of the ActionsPolicy settings.
+const (
+	ActionsAll       = "all"
+	ActionsNone      = "none"
+	ActionsSelected  = "selected"
+	ActionsLocalOnly = "local_only"
+
+	WorkflowPermissionsRead  = "read"
+	WorkflowPermissionsWrite = "write"
+)
+
+// ActionsPolicy declares the GitHub Actions permissions of an org or a repo.
+// Settings left unset are not managed.
+type ActionsPolicy struct {
+	// EnabledRepositories is all, none or selected. Org only.
+	EnabledRepositories *string `json:"enabled_repositories,omitempty"`
+
+	// SelectedRepositories are the repos allowed to run Actions when
+	// EnabledRepositories is selected. Org only.
+	SelectedRepositories []string `json:"selected_repositories,omitempty"`
+
+	// Enabled allows the repo to run Actions. Repo only.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// AllowedActions is all, local_only or selected.
+	AllowedActions *string `json:"allowed_actions,omitempty"`
+
+	// SelectedActions are the actions allowed when AllowedActions is selected.
+	SelectedActions *SelectedActions `json:"selected_actions,omitempty"`
+
+	// DefaultWorkflowPermissions of the GITHUB_TOKEN, read or write.
+	DefaultWorkflowPermissions *string `json:"default_workflow_permissions,omitempty"`
+
+	// CanApprovePullRequestReviews allows workflows to approve pull requests.
+	CanApprovePullRequestReviews *bool `json:"can_approve_pull_request_reviews,omitempty"`
+}
+
+// SelectedActions declares which actions and reusable workflows may run.
+type SelectedActions struct {
+	GithubOwnedAllowed *bool `json:"github_owned_allowed,omitempty"`
+	VerifiedAllowed    *bool `json:"verified_allowed,omitempty"`
+
+	// PatternsAllowed are owner/name@ref patterns of allowed actions,
+	// such as my-org/* or docker/login-action@*.
+	PatternsAllowed []string `json:"patterns_allowed,omitempty"`
+}
+
+// Webhook declares a webhook, webhooks are identified by their URL.
+type Webhook struct {
+	URL string `json:"url"`
+
+	// Events triggering the webhook, GitHub defaults to push.
+	Events []string `json:"events,omitempty"`
+
+	// ContentType of the payload, either json or form.
+	ContentType *string `json:"content_type,omitempty"`
+
+	Active *bool `json:"active,omitempty"`
+
+	// Secret used to sign payloads. GitHub never returns it, so it is
+	// not dumped and only sent when the webhook is created or edited.
+	Secret *SecretRef `json:"secret,omitempty"`
+}
+
+// SecretRef references a secret kept outside of the config.
+type SecretRef struct {
+	// File is the path of a file containing the secret, relative to the
+	// config file declaring it.
+	File string `json:"file,omitempty"`
+
+	// Env is the name of an environment variable containing the secret.
+	Env string `json:"env,omitempty"`
+}
+
+// Resolve returns the referenced secret.
+func (s SecretRef) Resolve() (string, error) {
+	switch {
+	case s.File != "" && s.Env != "":
+		return "", errors.New("secret must reference either a file or an env var, not both")
+	case s.File != "":
+		raw, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return strings.TrimSpace(string(raw)), nil
+	case s.Env != "":
+		secret, ok := os.LookupEnv(s.Env)
+		if !ok || secret == "" {
+			return "", fmt.Errorf("secret env var %s is not set", s.Env)
+		}
+		return secret, nil
+	}
+	return "", errors.New("secret must reference a file or an env var")
+}
@@ -0,0 +1,489 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+func TestUnmarshal(t *testing.T) {
+	yes := true
+	description := "a repo"
+	newOwner := "other-org"
+	template := "org/template-repo"
+	contentType := "json"
+	name := "foo"
+	blog := "https://blog.example.com"
+	all := ActionsAll
+	selected := ActionsSelected
+	read := WorkflowPermissionsRead
+	endOfYear := mustParseDate(t, "2026-12-31")
+	november := mustParseDate(t, "2026-11-01")
+	closed := org.Closed
+	notificationsDisabled := "notifications_disabled"
+
+	testCases := []struct {
+		description string
+		raw         string
+		expected    Config
+		expectError bool
+	}{
+		{
+			description: "upstream fields are loaded",
+			raw: `
+admins:
+- carl
+repos:
+  foo:
+    description: a repo
+`,
+			expected: Config{
+				Admins: NewMembers("carl"),
+				Repos: map[string]Repo{
+					"foo": {Repo: org.Repo{Description: &description}},
+				},
+			},
+		},
+		{
+			description: "org settings are loaded beside upstream metadata",
+			raw: `
+name: foo
+blog: https://blog.example.com
+members_can_create_public_repositories: true
+web_commit_signoff_required: true
+`,
+			expected: Config{
+				Config: org.Config{Metadata: org.Metadata{Name: &name}},
+				OrgSettings: OrgSettings{
+					Blog:                               &blog,
+					MembersCanCreatePublicRepositories: &yes,
+					WebCommitSignoffRequired:           &yes,
+				},
+			},
+		},
+		{
+			description: "repo lifecycle fields are loaded",
+			raw: `
+repos:
+  foo:
+    delete: true
+  bar:
+    transfer_to: other-org
+`,
+			expected: Config{
+				Repos: map[string]Repo{
+					"foo": {Delete: &yes},
+					"bar": {TransferTo: &newOwner},
+				},
+			},
+		},
+		{
+			description: "repo creation options are loaded",
+			raw: `
+repos:
+  foo:
+    on_create:
+      auto_init: true
+  bar:
+    on_create:
+      template: org/template-repo
+      include_all_branches: true
+`,
+			expected: Config{
+				Repos: map[string]Repo{
+					"foo": {OnCreate: &RepoCreateOptions{RepoCreateOptions: org.RepoCreateOptions{AutoInit: &yes}}},
+					"bar": {OnCreate: &RepoCreateOptions{Template: &template, IncludeAllBranches: &yes}},
+				},
+			},
+		},
+		{
+			description: "webhooks are loaded",
+			raw: `
+webhooks:
+- url: https://ci.example.com/hook
+  events: [push]
+  secret:
+    env: HOOK_SECRET
+repos:
+  foo:
+    webhooks:
+    - url: https://bot.example.com/hook
+      content_type: json
+      active: true
+`,
+			expected: Config{
+				Webhooks: []Webhook{
+					{URL: "https://ci.example.com/hook", Events: []string{"push"}, Secret: &SecretRef{Env: "HOOK_SECRET"}},
+				},
+				Repos: map[string]Repo{
+					"foo": {Webhooks: []Webhook{
+						{URL: "https://bot.example.com/hook", ContentType: &contentType, Active: &yes},
+					}},
+				},
+			},
+		},
+		{
+			description: "actions policies are loaded",
+			raw: `
+actions:
+  enabled_repositories: all
+  allowed_actions: selected
+  selected_actions:
+    github_owned_allowed: true
+    patterns_allowed: [my-org/*]
+  default_workflow_permissions: read
+repos:
+  foo:
+    actions:
+      enabled: true
+      can_approve_pull_request_reviews: true
+`,
+			expected: Config{
+				Actions: &ActionsPolicy{
+					EnabledRepositories: &all,
+					AllowedActions:      &selected,
+					SelectedActions: &SelectedActions{
+						GithubOwnedAllowed: &yes,
+						PatternsAllowed:    []string{"my-org/*"},
+					},
+					DefaultWorkflowPermissions: &read,
+				},
+				Repos: map[string]Repo{
+					"foo": {Actions: &ActionsPolicy{Enabled: &yes, CanApprovePullRequestReviews: &yes}},
+				},
+			},
+		},
+		{
+			description: "memberships and team repos may expire",
+			raw: `
+members:
+- anne
+- login: bob
+  expires: 2026-12-31
+teams:
+  responders:
+    maintainers:
+    - login: carl
+      expires: 2026-11-01
+    repos:
+      website: write
+      incident:
+        permission: admin
+        expires: 2026-12-31
+`,
+			expected: Config{
+				Members: []Member{{Login: "anne"}, {Login: "bob", Expires: &endOfYear}},
+				Teams: map[string]Team{
+					"responders": {
+						Maintainers: []Member{{Login: "carl", Expires: &november}},
+						Repos: map[string]TeamRepo{
+							"website":  {Permission: github.Write},
+							"incident": {Permission: github.Admin, Expires: &endOfYear},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "protected users, teams and repos are loaded",
+			raw: `
+protected:
+  users: [release-bot]
+  teams: [security]
+  repos: [infra]
+`,
+			expected: Config{
+				Protected: &Protected{
+					Users: []string{"release-bot"},
+					Teams: []string{"security"},
+					Repos: []string{"infra"},
+				},
+			},
+		},
+		{
+			description: "invalid expiry dates are rejected",
+			raw: `
+admins:
+- login: carl
+  expires: next week
+`,
+			expectError: true,
+		},
+		{
+			description: "unknown member fields are rejected",
+			raw: `
+admins:
+- login: carl
+  until: 2026-12-31
+`,
+			expectError: true,
+		},
+		{
+			description: "custom repo roles and org roles are loaded",
+			raw: `
+custom_repo_roles:
+  triage-plus:
+    base_role: triage
+    permissions: [add_label]
+org_roles:
+  security_manager: [security]
+teams:
+  security:
+    repos:
+      foo: triage-plus
+      bar: {permission: triage-plus, expires: 2026-12-31}
+`,
+			expected: Config{
+				CustomRepoRoles: map[string]CustomRepoRole{
+					"triage-plus": {BaseRole: github.Triage, Permissions: []string{"add_label"}},
+				},
+				OrgRoles: map[string][]string{"security_manager": {"security"}},
+				Teams: map[string]Team{
+					"security": {
+						Repos: map[string]TeamRepo{
+							"foo": {Permission: "triage-plus"},
+							"bar": {Permission: "triage-plus", Expires: &endOfYear},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "team settings are loaded beside upstream metadata",
+			raw: `
+teams:
+  reviewers:
+    privacy: closed
+    notification_setting: notifications_disabled
+    review_assignment:
+      algorithm: load_balance
+      member_count: 2
+      notify_team: true
+      excluded_members: [anne]
+`,
+			expected: Config{
+				Teams: map[string]Team{
+					"reviewers": {
+						Team: org.Team{TeamMetadata: org.TeamMetadata{Privacy: &closed}},
+						TeamSettings: TeamSettings{
+							NotificationSetting: &notificationsDisabled,
+							ReviewAssignment: &ReviewAssignment{
+								Algorithm:       LoadBalance,
+								MemberCount:     2,
+								NotifyTeam:      true,
+								ExcludedMembers: []string{"anne"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "unknown fields are rejected",
+			raw: `
+repos:
+  foo:
+    unknown: true
+`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var actual Config
+			err := yaml.Unmarshal([]byte(tc.raw), &actual)
+			switch {
+			case err != nil && !tc.expectError:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && tc.expectError:
+				t.Error("expected error, got none")
+			case err == nil:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected config (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	yes := true
+	expires := mustParseDate(t, "2026-12-31")
+	in := Config{
+		Members: []Member{{Login: "anne"}, {Login: "bob", Expires: &expires}, {Login: "carol", ID: 1234}},
+		Teams: map[string]Team{
+			"responders": {
+				Repos: map[string]TeamRepo{
+					"incident": {Permission: github.Admin, Expires: &expires},
+				},
+			},
+		},
+		Repos: map[string]Repo{
+			"foo": {Delete: &yes},
+		},
+	}
+	raw, err := yaml.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var out Config
+	if err := yaml.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if diff := cmp.Diff(in, out); diff != "" {
+		t.Errorf("config changed after round trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateRepoRoles(t *testing.T) {
+	testCases := []struct {
+		description string
+		config      Config
+		expectError bool
+	}{
+		{
+			description: "undeclared roles are left to the org",
+			config: Config{
+				Teams: map[string]Team{
+					"team": {Repos: map[string]TeamRepo{"repo": {Permission: "triage-plus"}}},
+				},
+			},
+		},
+		{
+			description: "declared roles are granted",
+			config: Config{
+				CustomRepoRoles: map[string]CustomRepoRole{"triage-plus": {BaseRole: github.Triage}},
+				Teams: map[string]Team{
+					"team": {
+						Repos: map[string]TeamRepo{"repo": {Permission: github.Write}},
+						Children: map[string]Team{
+							"child": {Repos: map[string]TeamRepo{"repo": {Permission: "triage-plus"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "unknown roles are rejected once roles are declared",
+			config: Config{
+				CustomRepoRoles: map[string]CustomRepoRole{},
+				Teams: map[string]Team{
+					"team": {
+						Children: map[string]Team{
+							"child": {Repos: map[string]TeamRepo{"repo": {Permission: "triage-plus"}}},
+						},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			description: "roles shadowing a permission level are rejected",
+			config: Config{
+				CustomRepoRoles: map[string]CustomRepoRole{"write": {BaseRole: github.Read}},
+			},
+			expectError: true,
+		},
+		{
+			description: "admin base role is rejected",
+			config: Config{
+				CustomRepoRoles: map[string]CustomRepoRole{"superuser": {BaseRole: github.Admin}},
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := tc.config.ValidateRepoRoles()
+			if err != nil && !tc.expectError {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err == nil && tc.expectError {
+				t.Error("expected error, got none")
+			}
+		})
+	}
+}
+
+func TestSecretRefResolve(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secret")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret: %v", err)
+	}
+	t.Setenv("PERIBOLOS_TEST_SECRET", "from-env")
+
+	testCases := []struct {
+		description string
+		ref         SecretRef
+		expected    string
+		expectError bool
+	}{
+		{
+			description: "file is read and trimmed",
+			ref:         SecretRef{File: file},
+			expected:    "from-file",
+		},
+		{
+			description: "env var is read",
+			ref:         SecretRef{Env: "PERIBOLOS_TEST_SECRET"},
+			expected:    "from-env",
+		},
+		{
+			description: "missing file",
+			ref:         SecretRef{File: filepath.Join(dir, "missing")},
+			expectError: true,
+		},
+		{
+			description: "unset env var",
+			ref:         SecretRef{Env: "PERIBOLOS_TEST_UNSET"},
+			expectError: true,
+		},
+		{
+			description: "both file and env",
+			ref:         SecretRef{File: file, Env: "PERIBOLOS_TEST_SECRET"},
+			expectError: true,
+		},
+		{
+			description: "neither file nor env",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := tc.ref.Resolve()
+			switch {
+			case err != nil && !tc.expectError:
+				t.Errorf("unexpected error: %v", err)
+			case err == nil && tc.expectError:
+				t.Error("expected error, got none")
+			case err == nil && actual != tc.expected:
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
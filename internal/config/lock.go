@@ -0,0 +1,120 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+// LockFileName is the name of the lockfile, written next to the config.
+const LockFileName = "peribolos.lock"
+
+const lockHeader = "# Generated by peribolos lock, refresh with peribolos lock --update.\n"
+
+// Lock pins the IDs resolved for the users, teams and repos of each org,
+// so that renamed users, teams and repos are still matched by ID.
+type Lock struct {
+	Orgs map[string]OrgLock `json:"orgs,omitempty"`
+}
+
+// OrgLock pins the IDs of an org by their name in the config.
+type OrgLock struct {
+	// Users are the user IDs by normalized login.
+	Users map[string]int `json:"users,omitempty"`
+	// Teams are the team IDs by team name.
+	Teams map[string]int `json:"teams,omitempty"`
+	// Repos are the repo IDs by repo name.
+	Repos map[string]int `json:"repos,omitempty"`
+}
+
+// LoadLock reads the lockfile at path.
+func LoadLock(path string) (*Lock, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	var l Lock
+	if err := yaml.Unmarshal(raw, &l); err != nil {
+		return nil, fmt.Errorf("failed to load lockfile %s: %w", path, err)
+	}
+	return &l, nil
+}
+
+// Write writes the lockfile to path.
+func (l Lock) Write(path string) error {
+	raw, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	return os.WriteFile(path, append([]byte(lockHeader), raw...), 0o644)
+}
+
+// Logins returns the logins of the admins, members and team members,
+// keyed by normalized login.
+func (c Config) Logins() map[string]Member {
+	logins := map[string]Member{}
+	add := func(members []Member) {
+		for _, m := range members {
+			if prev, ok := logins[github.NormLogin(m.Login)]; !ok || prev.ID == 0 {
+				logins[github.NormLogin(m.Login)] = m
+			}
+		}
+	}
+	var walk func(teams map[string]Team)
+	walk = func(teams map[string]Team) {
+		for _, team := range teams {
+			add(team.Maintainers)
+			add(team.Members)
+			walk(team.Children)
+		}
+	}
+	add(c.Admins)
+	add(c.Members)
+	walk(c.Teams)
+	return logins
+}
+
+// PinUserIDs sets the user IDs of the admins, members and team members
+// declared without one, keyed by normalized login.
+func (c *Config) PinUserIDs(ids map[string]int) {
+	if len(ids) == 0 {
+		return
+	}
+	pin := func(members []Member) {
+		for i, m := range members {
+			if id, ok := ids[github.NormLogin(m.Login)]; ok && m.ID == 0 {
+				members[i].ID = id
+			}
+		}
+	}
+	var walk func(teams map[string]Team)
+	walk = func(teams map[string]Team) {
+		for _, team := range teams {
+			pin(team.Maintainers)
+			pin(team.Members)
+			walk(team.Children)
+		}
+	}
+	pin(c.Admins)
+	pin(c.Members)
+	walk(c.Teams)
+}
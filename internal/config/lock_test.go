@@ -0,0 +1,76 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLockRoundTrip(t *testing.T) {
+	lock := Lock{
+		Orgs: map[string]OrgLock{
+			"org": {
+				Users: map[string]int{"anne": 1},
+				Teams: map[string]int{"team": 2},
+				Repos: map[string]int{"repo": 3},
+			},
+		},
+	}
+	path := filepath.Join(t.TempDir(), LockFileName)
+	if err := lock.Write(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(lock, *actual); diff != "" {
+		t.Errorf("unexpected lock (-want +got):\n%s", diff)
+	}
+}
+
+func TestPinUserIDs(t *testing.T) {
+	cfg := Config{
+		Admins:  []Member{{Login: "Anne"}},
+		Members: []Member{{Login: "bob", ID: 7}, {Login: "carol"}},
+		Teams: map[string]Team{
+			"parent": {
+				Children: map[string]Team{
+					"child": {Members: []Member{{Login: "dave"}}},
+				},
+			},
+		},
+	}
+	cfg.PinUserIDs(map[string]int{"anne": 1, "bob": 2, "dave": 4})
+	expected := Config{
+		Admins:  []Member{{Login: "Anne", ID: 1}},
+		Members: []Member{{Login: "bob", ID: 7}, {Login: "carol"}},
+		Teams: map[string]Team{
+			"parent": {
+				Children: map[string]Team{
+					"child": {Members: []Member{{Login: "dave", ID: 4}}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, cfg); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
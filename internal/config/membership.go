@@ -0,0 +1,548 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// DateLayout is the layout of expiry dates.
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day in UTC, written as 2026-12-31.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a date written as 2026-12-31.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+	}
+	return Date{Time: t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// Passed reports whether the day is over at now. Access expiring on a
+// date is kept until the end of that day.
+func (d Date) Passed(now time.Time) bool {
+	return !now.Before(d.AddDate(0, 0, 1))
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid date %s, want YYYY-MM-DD", data)
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Member is an org admin, org member, team maintainer or team member.
+// It is written either as a plain login or as an object carrying an
+// expiry date or a user ID:
+//
+//	members:
+//	- alice
+//	- login: bob
+//	  expires: 2026-12-31
+//	- login: carol
+//	  id: 1234
+type Member struct {
+	Login string `json:"login"`
+	// Expires is the last day of the membership. The sync removes the
+	// membership once the day is over.
+	Expires *Date `json:"expires,omitempty"`
+	// ID is the GitHub user ID, which is kept when the login is renamed.
+	ID int `json:"id,omitempty"`
+	// Source names the source the member was resolved from, it is empty
+	// for members declared in the config.
+	Source string `json:"-"`
+}
+
+// NewMembers returns members without an expiry date.
+func NewMembers(logins ...string) []Member {
+	if logins == nil {
+		return nil
+	}
+	members := make([]Member, 0, len(logins))
+	for _, login := range logins {
+		members = append(members, Member{Login: login})
+	}
+	return members
+}
+
+func (m Member) MarshalJSON() ([]byte, error) {
+	if m.Expires == nil && m.ID == 0 {
+		return json.Marshal(m.Login)
+	}
+	type member Member
+	return json.Marshal(member(m))
+}
+
+func (m *Member) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		*m = Member{}
+		return json.Unmarshal(data, &m.Login)
+	}
+	type member Member
+	var out member
+	if err := strictUnmarshal(data, &out); err != nil {
+		return err
+	}
+	if out.Login == "" {
+		return fmt.Errorf("member %s has no login", data)
+	}
+	*m = Member(out)
+	return nil
+}
+
+// TeamRepo is the permission of a team on a repo. It is written either
+// as a plain permission level or as an object carrying an expiry date:
+//
+//	repos:
+//	  website: write
+//	  incident: {permission: admin, expires: 2026-12-31}
+//
+// The permission is either a permission level or the name of a custom
+// repository role of the org.
+type TeamRepo struct {
+	Permission github.RepoPermissionLevel `json:"permission"`
+	// Expires is the last day of the grant. The sync removes the grant
+	// once the day is over.
+	Expires *Date `json:"expires,omitempty"`
+}
+
+func (r TeamRepo) MarshalJSON() ([]byte, error) {
+	if r.Expires == nil {
+		return json.Marshal(r.Permission)
+	}
+	type teamRepo TeamRepo
+	return json.Marshal(teamRepo(r))
+}
+
+// UnmarshalJSON reads the permission as a plain string, since it may name
+// a custom repository role which github.RepoPermissionLevel rejects.
+func (r *TeamRepo) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var permission string
+		if err := json.Unmarshal(data, &permission); err != nil {
+			return err
+		}
+		*r = TeamRepo{Permission: github.RepoPermissionLevel(permission)}
+		return nil
+	}
+	var out struct {
+		Permission string `json:"permission"`
+		Expires    *Date  `json:"expires,omitempty"`
+	}
+	if err := strictUnmarshal(data, &out); err != nil {
+		return err
+	}
+	*r = TeamRepo{Permission: github.RepoPermissionLevel(out.Permission), Expires: out.Expires}
+	return nil
+}
+
+// Team declares the desired state of a team, allowing its memberships and
+// repo permissions to expire.
+type Team struct {
+	org.Team `json:",inline"`
+
+	Maintainers []Member            `json:"maintainers,omitempty"`
+	Members     []Member            `json:"members,omitempty"`
+	Children    map[string]Team     `json:"teams,omitempty"`
+	Repos       map[string]TeamRepo `json:"repos,omitempty"`
+
+	// IdPGroups are the names of the identity provider groups the team
+	// membership is synchronized with, instead of declaring members. The
+	// mapping is not managed when nil, and an empty list unlinks the team.
+	IdPGroups []string `json:"idp_groups,omitempty"`
+
+	// MembersFrom is the source of additional team members, resolved at
+	// sync time.
+	MembersFrom *Source `json:"members_from,omitempty"`
+
+	TeamSettings `json:",inline"`
+}
+
+// TeamSettings declares the team settings missing from org.Team. Settings
+// left unset are not managed.
+type TeamSettings struct {
+	// NotificationSetting is notifications_enabled or
+	// notifications_disabled.
+	NotificationSetting *string `json:"notification_setting,omitempty"`
+	// Permission is the default permission of the repos added to the team
+	// without one, pull or push.
+	Permission *string `json:"permission,omitempty"`
+	// ReviewAssignment routes the review requests of the team to some of
+	// its members.
+	ReviewAssignment *ReviewAssignment `json:"review_assignment,omitempty"`
+}
+
+// ReviewAssignment declares the code review assignment of a team.
+type ReviewAssignment struct {
+	// Enabled turns the assignment off when false, it is on otherwise.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Algorithm is round_robin, the default, or load_balance.
+	Algorithm string `json:"algorithm,omitempty"`
+	// MemberCount is the number of members assigned each review, 1 by
+	// default.
+	MemberCount int `json:"member_count,omitempty"`
+	// NotifyTeam keeps notifying the whole team besides the assigned
+	// members.
+	NotifyTeam bool `json:"notify_team,omitempty"`
+	// ExcludedMembers are never assigned reviews.
+	ExcludedMembers []string `json:"excluded_members,omitempty"`
+}
+
+// Review assignment algorithms.
+const (
+	RoundRobin  = "round_robin"
+	LoadBalance = "load_balance"
+)
+
+// Validate returns an error if a setting has an unknown value.
+func (s TeamSettings) Validate() error {
+	if s.NotificationSetting != nil && *s.NotificationSetting != "notifications_enabled" && *s.NotificationSetting != "notifications_disabled" {
+		return fmt.Errorf("unknown notification_setting %q", *s.NotificationSetting)
+	}
+	if s.Permission != nil && *s.Permission != "pull" && *s.Permission != "push" {
+		return fmt.Errorf("unknown permission %q, must be pull or push", *s.Permission)
+	}
+	if a := s.ReviewAssignment; a != nil {
+		if a.Algorithm != "" && a.Algorithm != RoundRobin && a.Algorithm != LoadBalance {
+			return fmt.Errorf("unknown review_assignment algorithm %q", a.Algorithm)
+		}
+		if a.MemberCount < 0 {
+			return fmt.Errorf("negative review_assignment member_count %d", a.MemberCount)
+		}
+	}
+	return nil
+}
+
+// TeamSettings returns the settings of every team, children included,
+// which declares some.
+func (c Config) TeamSettings() (map[string]TeamSettings, error) {
+	settings := map[string]TeamSettings{}
+	var walk func(teams map[string]Team) error
+	walk = func(teams map[string]Team) error {
+		for name, team := range teams {
+			if team.TeamSettings != (TeamSettings{}) {
+				if err := team.TeamSettings.Validate(); err != nil {
+					return fmt.Errorf("team %s: %w", name, err)
+				}
+				settings[name] = team.TeamSettings
+			}
+			if err := walk(team.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(c.Teams); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// LinkedTeams returns the IdP groups of every team, children included,
+// whose groups are declared. Teams linked to groups must not declare
+// members nor maintainers.
+func (c Config) LinkedTeams() (map[string][]string, error) {
+	linked := map[string][]string{}
+	var walk func(teams map[string]Team) error
+	walk = func(teams map[string]Team) error {
+		for name, team := range teams {
+			if team.IdPGroups != nil {
+				if len(team.IdPGroups) > 0 && (len(team.Members) > 0 || len(team.Maintainers) > 0) {
+					return fmt.Errorf("team %s is synchronized with IdP groups and cannot declare members nor maintainers", name)
+				}
+				linked[name] = team.IdPGroups
+			}
+			if err := walk(team.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(c.Teams); err != nil {
+		return nil, err
+	}
+	return linked, nil
+}
+
+// NewTeams converts teams without expiry dates.
+func NewTeams(teams map[string]org.Team) map[string]Team {
+	if teams == nil {
+		return nil
+	}
+	out := make(map[string]Team, len(teams))
+	for name, team := range teams {
+		out[name] = NewTeam(team)
+	}
+	return out
+}
+
+// NewTeam converts a team without expiry dates.
+func NewTeam(team org.Team) Team {
+	out := Team{
+		Team:        team,
+		Maintainers: NewMembers(team.Maintainers...),
+		Members:     NewMembers(team.Members...),
+		Children:    NewTeams(team.Children),
+	}
+	if team.Repos != nil {
+		out.Repos = make(map[string]TeamRepo, len(team.Repos))
+		for repo, permission := range team.Repos {
+			out.Repos[repo] = TeamRepo{Permission: permission}
+		}
+	}
+	out.Team.Maintainers, out.Team.Members, out.Team.Children, out.Team.Repos = nil, nil, nil, nil
+	return out
+}
+
+// Effective returns the org configuration in effect at now, leaving out
+// the memberships and repo permissions which expired.
+func (c Config) Effective(now time.Time) org.Config {
+	out := c.Config
+	out.Admins = activeLogins(c.Admins, now)
+	out.Members = activeLogins(c.Members, now)
+	out.Teams = effectiveTeams(c.Teams, now)
+	return out
+}
+
+func effectiveTeams(teams map[string]Team, now time.Time) map[string]org.Team {
+	if teams == nil {
+		return nil
+	}
+	out := make(map[string]org.Team, len(teams))
+	for name, team := range teams {
+		out[name] = team.Effective(now)
+	}
+	return out
+}
+
+// Effective returns the team configuration in effect at now.
+func (t Team) Effective(now time.Time) org.Team {
+	out := t.Team
+	out.Maintainers = activeLogins(t.Maintainers, now)
+	out.Members = activeLogins(t.Members, now)
+	out.Children = effectiveTeams(t.Children, now)
+	if t.Repos != nil {
+		out.Repos = make(map[string]github.RepoPermissionLevel, len(t.Repos))
+		for repo, grant := range t.Repos {
+			if grant.Expires == nil || !grant.Expires.Passed(now) {
+				out.Repos[repo] = grant.Permission
+			}
+		}
+	}
+	return out
+}
+
+func activeLogins(members []Member, now time.Time) []string {
+	if members == nil {
+		return nil
+	}
+	logins := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.Expires == nil || !m.Expires.Passed(now) {
+			logins = append(logins, m.Login)
+		}
+	}
+	return logins
+}
+
+// Expiry is a membership or repo permission carrying an expiry date.
+type Expiry struct {
+	// Team is the full path of the team, parents first, or empty for an
+	// org role.
+	Team []string
+	// Login is the user, or empty for a team repo permission.
+	Login string
+	// Repo is the repo of a team repo permission.
+	Repo string
+	// Role is admin, member or maintainer for a user, or the permission
+	// level for a repo.
+	Role    string
+	Expires Date
+}
+
+func (e Expiry) String() string {
+	var target string
+	switch {
+	case e.Repo != "":
+		target = fmt.Sprintf("%s permission of team %s on repo %s", e.Role, teamPath(e.Team), e.Repo)
+	case len(e.Team) > 0:
+		target = fmt.Sprintf("%s %s of team %s", e.Role, e.Login, teamPath(e.Team))
+	default:
+		target = fmt.Sprintf("org %s %s", e.Role, e.Login)
+	}
+	return fmt.Sprintf("%s expires %s", target, e.Expires)
+}
+
+func teamPath(path []string) string {
+	return strings.Join(path, "/")
+}
+
+// Expiries returns every entry of the org carrying an expiry date, the
+// earliest first.
+func (c Config) Expiries() []Expiry {
+	var out []Expiry
+	add := func(team []string, role string, members []Member) {
+		for _, m := range members {
+			if m.Expires != nil {
+				out = append(out, Expiry{Team: team, Login: m.Login, Role: role, Expires: *m.Expires})
+			}
+		}
+	}
+	var walk func(parents []string, teams map[string]Team)
+	walk = func(parents []string, teams map[string]Team) {
+		for name, team := range teams {
+			path := append(append([]string{}, parents...), name)
+			add(path, github.RoleMaintainer, team.Maintainers)
+			add(path, github.RoleMember, team.Members)
+			for repo, grant := range team.Repos {
+				if grant.Expires != nil {
+					out = append(out, Expiry{Team: path, Repo: repo, Role: string(grant.Permission), Expires: *grant.Expires})
+				}
+			}
+			walk(path, team.Children)
+		}
+	}
+	add(nil, github.RoleAdmin, c.Admins)
+	add(nil, github.RoleMember, c.Members)
+	walk(nil, c.Teams)
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Expires.Equal(out[j].Expires.Time) {
+			return out[i].Expires.Before(out[j].Expires.Time)
+		}
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+// UserIDs returns the logins of the admins, members and team members
+// declared with a user ID, by ID.
+func (c Config) UserIDs() (map[int]string, error) {
+	ids := map[int]string{}
+	var err error
+	add := func(members []Member) {
+		for _, m := range members {
+			if m.ID == 0 || err != nil {
+				continue
+			}
+			if login, ok := ids[m.ID]; ok && github.NormLogin(login) != github.NormLogin(m.Login) {
+				err = fmt.Errorf("user ID %d is declared for both %s and %s", m.ID, login, m.Login)
+			}
+			ids[m.ID] = m.Login
+		}
+	}
+	var walk func(teams map[string]Team)
+	walk = func(teams map[string]Team) {
+		for _, team := range teams {
+			add(team.Maintainers)
+			add(team.Members)
+			walk(team.Children)
+		}
+	}
+	add(c.Admins)
+	add(c.Members)
+	walk(c.Teams)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Rename replaces the renamed logins of the admins, members, protected
+// users, teams and repo collaborators, keyed by normalized login.
+func (c *Config) Rename(renames map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+	rename := func(login string) string {
+		if renamed, ok := renames[github.NormLogin(login)]; ok {
+			return renamed
+		}
+		return login
+	}
+	renameMembers := func(members []Member) {
+		for i := range members {
+			members[i].Login = rename(members[i].Login)
+		}
+	}
+	var walk func(teams map[string]Team)
+	walk = func(teams map[string]Team) {
+		for _, team := range teams {
+			renameMembers(team.Maintainers)
+			renameMembers(team.Members)
+			walk(team.Children)
+		}
+	}
+	renameMembers(c.Admins)
+	renameMembers(c.Members)
+	if c.Protected != nil {
+		for i, user := range c.Protected.Users {
+			c.Protected.Users[i] = rename(user)
+		}
+	}
+	walk(c.Teams)
+	for name, repo := range c.Repos {
+		repo.Collaborators = renameCollaborators(repo.Collaborators, rename)
+		c.Repos[name] = repo
+	}
+}
+
+// renameCollaborators returns the collaborators with their logins
+// replaced by rename.
+func renameCollaborators(collaborators map[string]github.RepoPermissionLevel, rename func(string) string) map[string]github.RepoPermissionLevel {
+	if collaborators == nil {
+		return nil
+	}
+	out := make(map[string]github.RepoPermissionLevel, len(collaborators))
+	for login, role := range collaborators {
+		out[rename(login)] = role
+	}
+	return out
+}
+
+func strictUnmarshal(data []byte, out interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(out)
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+func mustParseDate(t *testing.T, s string) Date {
+	t.Helper()
+	d, err := ParseDate(s)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", s, err)
+	}
+	return d
+}
+
+func TestDatePassed(t *testing.T) {
+	d := mustParseDate(t, "2026-12-31")
+	testCases := []struct {
+		now      string
+		expected bool
+	}{
+		{now: "2026-12-30T23:59:59Z", expected: false},
+		{now: "2026-12-31T23:59:59Z", expected: false},
+		{now: "2027-01-01T00:00:00Z", expected: true},
+	}
+	for _, tc := range testCases {
+		now, err := time.Parse(time.RFC3339, tc.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := d.Passed(now); actual != tc.expected {
+			t.Errorf("Passed(%s) = %t, want %t", tc.now, actual, tc.expected)
+		}
+	}
+}
+
+func TestEffective(t *testing.T) {
+	past := mustParseDate(t, "2026-01-31")
+	future := mustParseDate(t, "2026-12-31")
+	now := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	in := Config{
+		Admins:  []Member{{Login: "admin"}, {Login: "intern", Expires: &past}},
+		Members: []Member{{Login: "anne"}, {Login: "responder", Expires: &future}},
+		Teams: map[string]Team{
+			"parent": {
+				Members: []Member{{Login: "anne"}, {Login: "intern", Expires: &past}},
+				Repos: map[string]TeamRepo{
+					"website":  {Permission: github.Write},
+					"incident": {Permission: github.Admin, Expires: &past},
+					"hotfix":   {Permission: github.Maintain, Expires: &future},
+				},
+				Children: map[string]Team{
+					"child": {
+						Maintainers: []Member{{Login: "intern", Expires: &past}},
+					},
+				},
+			},
+		},
+	}
+	expected := org.Config{
+		Admins:  []string{"admin"},
+		Members: []string{"anne", "responder"},
+		Teams: map[string]org.Team{
+			"parent": {
+				Members: []string{"anne"},
+				Repos: map[string]github.RepoPermissionLevel{
+					"website": github.Write,
+					"hotfix":  github.Maintain,
+				},
+				Children: map[string]org.Team{
+					"child": {Maintainers: []string{}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, in.Effective(now)); diff != "" {
+		t.Errorf("unexpected effective config (-want +got):\n%s", diff)
+	}
+}
+
+func TestExpiries(t *testing.T) {
+	soon := mustParseDate(t, "2026-06-10")
+	later := mustParseDate(t, "2026-12-31")
+	in := Config{
+		Admins: []Member{{Login: "admin"}, {Login: "responder", Expires: &later}},
+		Teams: map[string]Team{
+			"parent": {
+				Children: map[string]Team{
+					"child": {
+						Members: []Member{{Login: "intern", Expires: &soon}},
+						Repos: map[string]TeamRepo{
+							"incident": {Permission: github.Admin, Expires: &later},
+						},
+					},
+				},
+			},
+		},
+	}
+	var actual []string
+	for _, e := range in.Expiries() {
+		actual = append(actual, e.String())
+	}
+	expected := []string{
+		"member intern of team parent/child expires 2026-06-10",
+		"admin permission of team parent/child on repo incident expires 2026-12-31",
+		"org admin responder expires 2026-12-31",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected expiries (-want +got):\n%s", diff)
+	}
+}
+
+func TestLinkedTeams(t *testing.T) {
+	testCases := []struct {
+		name     string
+		teams    map[string]Team
+		expected map[string][]string
+		err      bool
+	}{
+		{
+			name: "collects the groups of teams and children",
+			teams: map[string]Team{
+				"eng": {
+					IdPGroups: []string{"Engineering"},
+					Children: map[string]Team{
+						"sre":     {IdPGroups: []string{"SRE", "Oncall"}},
+						"interns": {Members: []Member{{Login: "anne"}}},
+					},
+				},
+				"legacy": {IdPGroups: []string{}},
+				"docs":   {Members: []Member{{Login: "anne"}}},
+			},
+			expected: map[string][]string{
+				"eng":    {"Engineering"},
+				"sre":    {"SRE", "Oncall"},
+				"legacy": {},
+			},
+		},
+		{
+			name: "rejects linked teams declaring members",
+			teams: map[string]Team{
+				"eng": {IdPGroups: []string{"Engineering"}, Maintainers: []Member{{Login: "anne"}}},
+			},
+			err: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Config{Teams: tc.teams}.LinkedTeams()
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Error("failed to receive an error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected linked teams (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestUserIDs(t *testing.T) {
+	in := Config{
+		Admins:  []Member{{Login: "anne", ID: 1}},
+		Members: []Member{{Login: "bob"}},
+		Teams: map[string]Team{
+			"parent": {
+				Maintainers: []Member{{Login: "Anne", ID: 1}},
+				Children: map[string]Team{
+					"child": {Members: []Member{{Login: "carol", ID: 3}}},
+				},
+			},
+		},
+	}
+	actual, err := in.UserIDs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[int]string{1: "anne", 3: "carol"}, actual, cmp.Comparer(func(a, b string) bool {
+		return github.NormLogin(a) == github.NormLogin(b)
+	})); diff != "" {
+		t.Errorf("unexpected user IDs (-want +got):\n%s", diff)
+	}
+
+	in.Members = []Member{{Login: "bob", ID: 3}}
+	if _, err := in.UserIDs(); err == nil {
+		t.Error("failed to receive an error for a user ID declared for two logins")
+	}
+}
+
+func TestRename(t *testing.T) {
+	in := Config{
+		Admins:    []Member{{Login: "Anne", ID: 1}},
+		Members:   []Member{{Login: "bob"}},
+		Protected: &Protected{Users: []string{"anne"}},
+		Teams: map[string]Team{
+			"parent": {
+				Children: map[string]Team{
+					"child": {Maintainers: []Member{{Login: "anne", ID: 1}}},
+				},
+			},
+		},
+	}
+	in.Rename(map[string]string{"anne": "anne-renamed"})
+	expected := Config{
+		Admins:    []Member{{Login: "anne-renamed", ID: 1}},
+		Members:   []Member{{Login: "bob"}},
+		Protected: &Protected{Users: []string{"anne-renamed"}},
+		Teams: map[string]Team{
+			"parent": {
+				Children: map[string]Team{
+					"child": {Maintainers: []Member{{Login: "anne-renamed", ID: 1}}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, in); diff != "" {
+		t.Errorf("unexpected renamed config (-want +got):\n%s", diff)
+	}
+}
+
+func TestTeamSettings(t *testing.T) {
+	disabled := "notifications_disabled"
+	admin := "admin"
+	testCases := []struct {
+		name     string
+		teams    map[string]Team
+		expected map[string]TeamSettings
+		err      bool
+	}{
+		{
+			name: "collects the settings of teams and children",
+			teams: map[string]Team{
+				"eng": {
+					TeamSettings: TeamSettings{NotificationSetting: &disabled},
+					Children: map[string]Team{
+						"sre":  {TeamSettings: TeamSettings{ReviewAssignment: &ReviewAssignment{Algorithm: LoadBalance}}},
+						"docs": {},
+					},
+				},
+			},
+			expected: map[string]TeamSettings{
+				"eng": {NotificationSetting: &disabled},
+				"sre": {ReviewAssignment: &ReviewAssignment{Algorithm: LoadBalance}},
+			},
+		},
+		{
+			name:  "rejects unknown permissions",
+			teams: map[string]Team{"eng": {TeamSettings: TeamSettings{Permission: &admin}}},
+			err:   true,
+		},
+		{
+			name:  "rejects unknown algorithms",
+			teams: map[string]Team{"eng": {TeamSettings: TeamSettings{ReviewAssignment: &ReviewAssignment{Algorithm: "random"}}}},
+			err:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Config{Teams: tc.teams}.TeamSettings()
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Error("failed to receive an error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected team settings (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
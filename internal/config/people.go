@@ -0,0 +1,130 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+// People maps stable person IDs, such as emails or employee IDs, to their
+// GitHub account:
+//
+//	anne@example.com:
+//	  login: anne
+//	  id: 1234
+//
+// Admins, members, maintainers, protected users and repo collaborators may
+// then be written as person IDs instead of logins.
+type People map[string]Person
+
+// Person is the GitHub account of a person.
+type Person struct {
+	Login string `json:"login"`
+	// ID is the GitHub user ID, which is kept when the login is renamed.
+	ID int `json:"id,omitempty"`
+}
+
+// LoadPeople reads and validates the people mapping at path.
+func LoadPeople(path string) (People, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read people: %w", err)
+	}
+	var p People
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("failed to load people %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid people %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Validate returns an error when a person has no login, or when two people
+// share a login or a user ID.
+func (p People) Validate() error {
+	logins := map[string]string{}
+	ids := map[int]string{}
+	for person, account := range p {
+		if account.Login == "" {
+			return fmt.Errorf("person %s has no login", person)
+		}
+		login := github.NormLogin(account.Login)
+		if other, ok := logins[login]; ok {
+			return fmt.Errorf("people %s and %s share login %s", other, person, account.Login)
+		}
+		logins[login] = person
+		if account.ID == 0 {
+			continue
+		}
+		if other, ok := ids[account.ID]; ok {
+			return fmt.Errorf("people %s and %s share user ID %d", other, person, account.ID)
+		}
+		ids[account.ID] = person
+	}
+	return nil
+}
+
+// Resolve replaces the person IDs referenced by the admins, members,
+// protected users, teams and repo collaborators of cfg with their logins,
+// recording their user IDs.
+func (p People) Resolve(cfg *Config) {
+	p.resolveMembers(cfg.Admins)
+	p.resolveMembers(cfg.Members)
+	if cfg.Protected != nil {
+		for i, user := range cfg.Protected.Users {
+			cfg.Protected.Users[i] = p.login(user)
+		}
+	}
+	p.resolveTeams(cfg.Teams)
+	for name, repo := range cfg.Repos {
+		repo.Collaborators = renameCollaborators(repo.Collaborators, p.login)
+		cfg.Repos[name] = repo
+	}
+}
+
+func (p People) resolveTeams(teams map[string]Team) {
+	for _, team := range teams {
+		p.resolveMembers(team.Maintainers)
+		p.resolveMembers(team.Members)
+		p.resolveTeams(team.Children)
+	}
+}
+
+func (p People) resolveMembers(members []Member) {
+	for i, m := range members {
+		if account, ok := p[m.Login]; ok {
+			members[i].Login = account.Login
+			if m.ID == 0 {
+				members[i].ID = account.ID
+			}
+		}
+	}
+}
+
+// login returns the login of the person, or s when it is not a person ID.
+func (p People) login(s string) string {
+	if account, ok := p[s]; ok {
+		return account.Login
+	}
+	return s
+}
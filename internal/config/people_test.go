@@ -0,0 +1,123 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadPeople(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected People
+		err      bool
+	}{
+		{
+			name:    "people with and without user IDs",
+			content: "anne@example.com:\n  login: anne\n  id: 1\nE1234:\n  login: bob\n",
+			expected: People{
+				"anne@example.com": {Login: "anne", ID: 1},
+				"E1234":            {Login: "bob"},
+			},
+		},
+		{
+			name:    "person without login",
+			content: "anne@example.com:\n  id: 1\n",
+			err:     true,
+		},
+		{
+			name:    "people sharing a login",
+			content: "anne@example.com:\n  login: anne\nE1234:\n  login: Anne\n",
+			err:     true,
+		},
+		{
+			name:    "people sharing a user ID",
+			content: "anne@example.com:\n  login: anne\n  id: 1\nE1234:\n  login: bob\n  id: 1\n",
+			err:     true,
+		},
+		{
+			name:    "unknown field",
+			content: "anne@example.com:\n  login: anne\n  email: anne@example.com\n",
+			err:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "people.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := LoadPeople(path)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Error("failed to receive an error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected people (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestPeopleResolve(t *testing.T) {
+	expires := mustParseDate(t, "2026-12-31")
+	people := People{
+		"anne@example.com": {Login: "anne", ID: 1},
+		"E1234":            {Login: "bob"},
+	}
+	cfg := Config{
+		Admins:    []Member{{Login: "anne@example.com"}},
+		Members:   []Member{{Login: "E1234", Expires: &expires}, {Login: "carl"}},
+		Protected: &Protected{Users: []string{"anne@example.com", "robot"}},
+		Teams: map[string]Team{
+			"parent": {
+				Maintainers: []Member{{Login: "anne@example.com"}},
+				Children: map[string]Team{
+					"child": {Members: []Member{{Login: "E1234"}}},
+				},
+			},
+		},
+	}
+	people.Resolve(&cfg)
+
+	expected := Config{
+		Admins:    []Member{{Login: "anne", ID: 1}},
+		Members:   []Member{{Login: "bob", Expires: &expires}, {Login: "carl"}},
+		Protected: &Protected{Users: []string{"anne", "robot"}},
+		Teams: map[string]Team{
+			"parent": {
+				Maintainers: []Member{{Login: "anne", ID: 1}},
+				Children: map[string]Team{
+					"child": {Members: []Member{{Login: "bob"}}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, cfg); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+// Resources which may be managed by a policy, named after their --fix-*
+// flag.
+const (
+	ResourceOrg         = "org"
+	ResourceOrgMembers  = "org-members"
+	ResourceTeams       = "teams"
+	ResourceTeamMembers = "team-members"
+	ResourceTeamRepos   = "team-repos"
+	ResourceRepos       = "repos"
+	ResourceWebhooks    = "webhooks"
+)
+
+// Repo lifecycle changes which may be allowed by a policy, named after their
+// --allow-* flag.
+const (
+	AllowRepoArchival = "repo-archival"
+	AllowRepoPublish  = "repo-publish"
+	AllowRepoDeletion = "repo-deletion"
+	AllowRepoTransfer = "repo-transfer"
+)
+
+var (
+	resources = []string{ResourceOrg, ResourceOrgMembers, ResourceTeams, ResourceTeamMembers, ResourceTeamRepos, ResourceRepos, ResourceWebhooks}
+	allows    = []string{AllowRepoArchival, AllowRepoPublish, AllowRepoDeletion, AllowRepoTransfer}
+)
+
+// Policy declares which resources of each org a run manages:
+//
+//	default:
+//	  managed: [org, org-members]
+//	orgs:
+//	  kubernetes:
+//	    managed: [org, org-members, teams, team-members, team-repos, repos]
+//	    read_only:
+//	      teams: [legacy-*]
+//	    ignored:
+//	      repos: [sandbox-*]
+//	    allow: [repo-archival]
+type Policy struct {
+	// Default is the policy of the orgs missing from Orgs.
+	Default *OrgPolicy           `json:"default,omitempty"`
+	Orgs    map[string]OrgPolicy `json:"orgs,omitempty"`
+}
+
+// OrgPolicy declares which resources of an org a run manages. Resources
+// which are not managed are read-only.
+type OrgPolicy struct {
+	// Managed are the resources reconciled with the config.
+	Managed []string `json:"managed,omitempty"`
+	// ReadOnly are the teams and repos which are never created, changed
+	// nor deleted, although they remain visible to the run.
+	ReadOnly Patterns `json:"read_only,omitempty"`
+	// Ignored are the teams and repos hidden from the run, as if they were
+	// neither declared in the config nor present in the org.
+	Ignored Patterns `json:"ignored,omitempty"`
+	// Allow are the repo lifecycle changes allowed.
+	Allow []string `json:"allow,omitempty"`
+}
+
+// Patterns are case-insensitive glob patterns of team and repo names.
+type Patterns struct {
+	Teams []string `json:"teams,omitempty"`
+	Repos []string `json:"repos,omitempty"`
+	// TeamRepos match the permissions of teams on repos, written as
+	// team/repo.
+	TeamRepos []string `json:"team_repos,omitempty"`
+}
+
+// Merge returns the patterns of p and other.
+func (p Patterns) Merge(other *Patterns) Patterns {
+	if other == nil {
+		return p
+	}
+	return Patterns{
+		Teams:     append(slices.Clip(p.Teams), other.Teams...),
+		Repos:     append(slices.Clip(p.Repos), other.Repos...),
+		TeamRepos: append(slices.Clip(p.TeamRepos), other.TeamRepos...),
+	}
+}
+
+// Empty reports whether p matches nothing.
+func (p Patterns) Empty() bool {
+	return len(p.Teams) == 0 && len(p.Repos) == 0 && len(p.TeamRepos) == 0
+}
+
+// Validate returns an error when a pattern is invalid.
+func (p Patterns) Validate() error {
+	for _, patterns := range [][]string{p.Teams, p.Repos, p.TeamRepos} {
+		for _, pattern := range patterns {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("invalid pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadPolicy reads and validates the policy at path.
+func LoadPolicy(path string) (*Policy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var p Policy
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("failed to load policy %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Validate returns an error when a policy names an unknown resource or an
+// invalid pattern.
+func (p Policy) Validate() error {
+	if p.Default != nil {
+		if err := p.Default.validate(); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	for name, op := range p.Orgs {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("org %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (p OrgPolicy) validate() error {
+	for _, r := range p.Managed {
+		if !slices.Contains(resources, r) {
+			return fmt.Errorf("unknown resource %q, must be one of %v", r, resources)
+		}
+	}
+	for _, r := range []string{ResourceTeamMembers, ResourceTeamRepos} {
+		if p.Manages(r) && !p.Manages(ResourceTeams) {
+			return fmt.Errorf("managing %s requires managing %s", r, ResourceTeams)
+		}
+	}
+	for _, a := range p.Allow {
+		if !slices.Contains(allows, a) {
+			return fmt.Errorf("unknown change %q, must be one of %v", a, allows)
+		}
+	}
+	if err := p.ReadOnly.Validate(); err != nil {
+		return fmt.Errorf("read_only: %w", err)
+	}
+	if err := p.Ignored.Validate(); err != nil {
+		return fmt.Errorf("ignored: %w", err)
+	}
+	return nil
+}
+
+// For returns the policy of the org, falling back to the default policy.
+func (p Policy) For(orgName string) (OrgPolicy, bool) {
+	if op, ok := p.Orgs[orgName]; ok {
+		return op, true
+	}
+	if p.Default != nil {
+		return *p.Default, true
+	}
+	return OrgPolicy{}, false
+}
+
+// Manages reports whether the resource is managed.
+func (p OrgPolicy) Manages(resource string) bool {
+	return slices.Contains(p.Managed, resource)
+}
+
+// Allows reports whether the repo lifecycle change is allowed.
+func (p OrgPolicy) Allows(change string) bool {
+	return slices.Contains(p.Allow, change)
+}
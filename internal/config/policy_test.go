@@ -0,0 +1,138 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   string
+		expected *Policy
+		err      bool
+	}{
+		{
+			name: "per org and default policies",
+			policy: `
+default:
+  managed: [org]
+orgs:
+  kubernetes:
+    managed: [org, org-members, teams, team-members]
+    read_only:
+      teams: [legacy-*]
+    ignored:
+      repos: [sandbox-*]
+    allow: [repo-archival]
+`,
+			expected: &Policy{
+				Default: &OrgPolicy{Managed: []string{ResourceOrg}},
+				Orgs: map[string]OrgPolicy{
+					"kubernetes": {
+						Managed:  []string{ResourceOrg, ResourceOrgMembers, ResourceTeams, ResourceTeamMembers},
+						ReadOnly: Patterns{Teams: []string{"legacy-*"}},
+						Ignored:  Patterns{Repos: []string{"sandbox-*"}},
+						Allow:    []string{AllowRepoArchival},
+					},
+				},
+			},
+		},
+		{
+			name:   "reject unknown resources",
+			policy: "orgs: {kubernetes: {managed: [everything]}}",
+			err:    true,
+		},
+		{
+			name:   "reject team members without teams",
+			policy: "default: {managed: [team-members]}",
+			err:    true,
+		},
+		{
+			name:   "reject unknown changes",
+			policy: "default: {allow: [repo-rename]}",
+			err:    true,
+		},
+		{
+			name:   "reject invalid patterns",
+			policy: "default: {ignored: {repos: ['[']}}",
+			err:    true,
+		},
+		{
+			name:   "reject unknown fields",
+			policy: "default: {fix: [org]}",
+			err:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(tc.policy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := LoadPolicy(path)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Error("failed to receive error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected policy (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	p := Policy{
+		Default: &OrgPolicy{Managed: []string{ResourceOrg}},
+		Orgs:    map[string]OrgPolicy{"kubernetes": {Managed: []string{ResourceRepos}}},
+	}
+	if op, ok := p.For("kubernetes"); !ok || !op.Manages(ResourceRepos) || op.Manages(ResourceOrg) {
+		t.Errorf("unexpected kubernetes policy %#v", op)
+	}
+	if op, ok := p.For("other"); !ok || !op.Manages(ResourceOrg) {
+		t.Errorf("unexpected default policy %#v", op)
+	}
+	if _, ok := (Policy{}).For("other"); ok {
+		t.Error("expected no policy without a default")
+	}
+}
+
+func TestPatternsMerge(t *testing.T) {
+	policy := Patterns{Teams: []string{"bots-*"}}
+	merged := policy.Merge(&Patterns{Teams: []string{"idp-*"}, TeamRepos: []string{"sig-*/website"}})
+	expected := Patterns{Teams: []string{"bots-*", "idp-*"}, TeamRepos: []string{"sig-*/website"}}
+	if diff := cmp.Diff(expected, merged); diff != "" {
+		t.Errorf("unexpected patterns (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(Patterns{Teams: []string{"bots-*"}}, policy); diff != "" {
+		t.Errorf("merge changed the receiver (-want +got):\n%s", diff)
+	}
+	if !(Patterns{}).Empty() || merged.Empty() {
+		t.Error("unexpected Empty")
+	}
+}
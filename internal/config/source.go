@@ -0,0 +1,98 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import "errors"
+
+// Source declares where members are listed outside of the config, either
+// a CSV or JSON file or an LDAP group:
+//
+//	members_from:
+//	  file: hr/platform.csv
+//	  column: github
+//
+//	members_from:
+//	  ldap: cn=platform,ou=groups,dc=example,dc=com
+//	  attribute: member
+//	  login_attribute: githubLogin
+type Source struct {
+	// File is the path of a CSV file with a header row, or of a JSON file
+	// holding a list of logins or of objects.
+	File string `json:"file,omitempty"`
+	// Column is the CSV column or JSON field holding the logins, login by
+	// default.
+	Column string `json:"column,omitempty"`
+
+	// LDAP is the DN of an LDAP group.
+	LDAP string `json:"ldap,omitempty"`
+	// Attribute is the attribute of the group listing its members,
+	// memberUid by default.
+	Attribute string `json:"attribute,omitempty"`
+	// LoginAttribute is the attribute of the member entries holding the
+	// logins, when Attribute lists the DNs of the members.
+	LoginAttribute string `json:"login_attribute,omitempty"`
+}
+
+// Validate returns an error unless exactly one source is declared.
+func (s Source) Validate() error {
+	switch {
+	case s.File == "" && s.LDAP == "":
+		return errors.New("members_from requires file or ldap")
+	case s.File != "" && s.LDAP != "":
+		return errors.New("members_from cannot declare both file and ldap")
+	case s.File != "" && (s.Attribute != "" || s.LoginAttribute != ""):
+		return errors.New("attribute and login_attribute require ldap")
+	case s.LDAP != "" && s.Column != "":
+		return errors.New("column requires file")
+	}
+	return nil
+}
+
+// String names the source in logs, such as file hr/platform.csv.
+func (s Source) String() string {
+	if s.File != "" {
+		return "file " + s.File
+	}
+	return "ldap " + s.LDAP
+}
+
+// MemberSources returns the source of the org members and, by team name,
+// of the team members resolved from a source, by login.
+func (c Config) MemberSources() (map[string]string, map[string]map[string]string) {
+	teams := map[string]map[string]string{}
+	var walk func(teams map[string]Team, sources map[string]map[string]string)
+	walk = func(teams map[string]Team, sources map[string]map[string]string) {
+		for name, team := range teams {
+			if from := sourcedMembers(team.Members); len(from) > 0 {
+				sources[name] = from
+			}
+			walk(team.Children, sources)
+		}
+	}
+	walk(c.Teams, teams)
+	return sourcedMembers(c.Members), teams
+}
+
+func sourcedMembers(members []Member) map[string]string {
+	from := map[string]string{}
+	for _, m := range members {
+		if m.Source != "" {
+			from[m.Login] = m.Source
+		}
+	}
+	return from
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSourceValidate(t *testing.T) {
+	testCases := []struct {
+		source Source
+		err    bool
+	}{
+		{source: Source{File: "hr.csv", Column: "github"}},
+		{source: Source{LDAP: "cn=eng", Attribute: "member", LoginAttribute: "uid"}},
+		{source: Source{}, err: true},
+		{source: Source{File: "hr.csv", LDAP: "cn=eng"}, err: true},
+		{source: Source{File: "hr.csv", Attribute: "member"}, err: true},
+		{source: Source{LDAP: "cn=eng", Column: "github"}, err: true},
+	}
+	for _, tc := range testCases {
+		if err := tc.source.Validate(); (err != nil) != tc.err {
+			t.Errorf("Validate(%+v) = %v, want error %t", tc.source, err, tc.err)
+		}
+	}
+}
+
+func TestMemberSources(t *testing.T) {
+	cfg := Config{
+		Members: []Member{{Login: "anne"}, {Login: "bob", Source: "file hr.csv"}},
+		Teams: map[string]Team{
+			"parent": {
+				Members: []Member{{Login: "anne"}},
+				Children: map[string]Team{
+					"child": {Members: []Member{{Login: "bob", Source: "ldap cn=eng"}}},
+				},
+			},
+		},
+	}
+	orgSources, teamSources := cfg.MemberSources()
+	if diff := cmp.Diff(map[string]string{"bob": "file hr.csv"}, orgSources); diff != "" {
+		t.Errorf("unexpected org sources (-want +got):\n%s", diff)
+	}
+	expected := map[string]map[string]string{"child": {"bob": "ldap cn=eng"}}
+	if diff := cmp.Diff(expected, teamSources); diff != "" {
+		t.Errorf("unexpected team sources (-want +got):\n%s", diff)
+	}
+}
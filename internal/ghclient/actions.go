@@ -0,0 +1,245 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OrgActionsPermissions are the GitHub Actions permissions of an org.
+type OrgActionsPermissions struct {
+	// EnabledRepositories is all, none or selected.
+	EnabledRepositories string `json:"enabled_repositories"`
+	// AllowedActions is all, local_only or selected.
+	AllowedActions string `json:"allowed_actions,omitempty"`
+}
+
+// RepoActionsPermissions are the GitHub Actions permissions of a repo.
+type RepoActionsPermissions struct {
+	Enabled bool `json:"enabled"`
+	// AllowedActions is all, local_only or selected.
+	AllowedActions string `json:"allowed_actions,omitempty"`
+}
+
+// SelectedActions are the actions allowed when AllowedActions is selected.
+type SelectedActions struct {
+	GithubOwnedAllowed bool     `json:"github_owned_allowed"`
+	VerifiedAllowed    bool     `json:"verified_allowed"`
+	PatternsAllowed    []string `json:"patterns_allowed"`
+}
+
+// WorkflowPermissions are the default permissions of the GITHUB_TOKEN.
+type WorkflowPermissions struct {
+	// DefaultWorkflowPermissions is read or write.
+	DefaultWorkflowPermissions   string `json:"default_workflow_permissions"`
+	CanApprovePullRequestReviews bool   `json:"can_approve_pull_request_reviews"`
+}
+
+// ActionsRepo is a repo allowed to run Actions by an org selecting repositories.
+type ActionsRepo struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func orgActionsPath(org, suffix string) string {
+	return fmt.Sprintf("/orgs/%s/actions/permissions%s", url.PathEscape(org), suffix)
+}
+
+func repoActionsPath(owner, repo, suffix string) string {
+	return fmt.Sprintf("/repos/%s/%s/actions/permissions%s", url.PathEscape(owner), url.PathEscape(repo), suffix)
+}
+
+// GetOrgActionsPermissions returns the Actions permissions of org.
+//
+// See https://docs.github.com/en/rest/actions/permissions#get-github-actions-permissions-for-an-organization
+func (c *Client) GetOrgActionsPermissions(org string) (*OrgActionsPermissions, error) {
+	c.logger.Infof("GetOrgActionsPermissions(%s)", org)
+	var perms OrgActionsPermissions
+	if err := c.request(org, http.MethodGet, orgActionsPath(org, ""), nil, &perms); err != nil {
+		return nil, err
+	}
+	return &perms, nil
+}
+
+// EditOrgActionsPermissions sets the Actions permissions of org.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-github-actions-permissions-for-an-organization
+func (c *Client) EditOrgActionsPermissions(org string, perms OrgActionsPermissions) error {
+	c.logger.Infof("EditOrgActionsPermissions(%s, %+v)", org, perms)
+	return c.request(org, http.MethodPut, orgActionsPath(org, ""), perms, nil)
+}
+
+// ListOrgActionsRepos returns the repos allowed to run Actions when org
+// enables selected repositories.
+//
+// See https://docs.github.com/en/rest/actions/permissions#list-selected-repositories-enabled-for-github-actions-in-an-organization
+func (c *Client) ListOrgActionsRepos(org string) ([]ActionsRepo, error) {
+	c.logger.Infof("ListOrgActionsRepos(%s)", org)
+	var repos []ActionsRepo
+	for page := 1; ; page++ {
+		var resp struct {
+			TotalCount   int           `json:"total_count"`
+			Repositories []ActionsRepo `json:"repositories"`
+		}
+		path := fmt.Sprintf("%s?per_page=%d&page=%d", orgActionsPath(org, "/repositories"), perPage, page)
+		if err := c.request(org, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		repos = append(repos, resp.Repositories...)
+		if len(resp.Repositories) < perPage || len(repos) >= resp.TotalCount {
+			return repos, nil
+		}
+	}
+}
+
+// SetOrgActionsRepos replaces the repos allowed to run Actions when org
+// enables selected repositories.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-selected-repositories-enabled-for-github-actions-in-an-organization
+func (c *Client) SetOrgActionsRepos(org string, ids []int) error {
+	c.logger.Infof("SetOrgActionsRepos(%s, %v)", org, ids)
+	body := struct {
+		SelectedRepositoryIDs []int `json:"selected_repository_ids"`
+	}{SelectedRepositoryIDs: ids}
+	if body.SelectedRepositoryIDs == nil {
+		body.SelectedRepositoryIDs = []int{}
+	}
+	return c.request(org, http.MethodPut, orgActionsPath(org, "/repositories"), body, nil)
+}
+
+// GetOrgSelectedActions returns the actions org allows when it allows
+// selected actions.
+//
+// See https://docs.github.com/en/rest/actions/permissions#get-allowed-actions-and-reusable-workflows-for-an-organization
+func (c *Client) GetOrgSelectedActions(org string) (*SelectedActions, error) {
+	c.logger.Infof("GetOrgSelectedActions(%s)", org)
+	var selected SelectedActions
+	if err := c.request(org, http.MethodGet, orgActionsPath(org, "/selected-actions"), nil, &selected); err != nil {
+		return nil, err
+	}
+	return &selected, nil
+}
+
+// EditOrgSelectedActions sets the actions org allows when it allows
+// selected actions.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-allowed-actions-and-reusable-workflows-for-an-organization
+func (c *Client) EditOrgSelectedActions(org string, selected SelectedActions) error {
+	c.logger.Infof("EditOrgSelectedActions(%s, %+v)", org, selected)
+	return c.request(org, http.MethodPut, orgActionsPath(org, "/selected-actions"), selected, nil)
+}
+
+// GetOrgWorkflowPermissions returns the default workflow permissions of org.
+//
+// See https://docs.github.com/en/rest/actions/permissions#get-default-workflow-permissions-for-an-organization
+func (c *Client) GetOrgWorkflowPermissions(org string) (*WorkflowPermissions, error) {
+	c.logger.Infof("GetOrgWorkflowPermissions(%s)", org)
+	var perms WorkflowPermissions
+	if err := c.request(org, http.MethodGet, orgActionsPath(org, "/workflow"), nil, &perms); err != nil {
+		return nil, err
+	}
+	return &perms, nil
+}
+
+// EditOrgWorkflowPermissions sets the default workflow permissions of org.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-default-workflow-permissions-for-an-organization
+func (c *Client) EditOrgWorkflowPermissions(org string, perms WorkflowPermissions) error {
+	c.logger.Infof("EditOrgWorkflowPermissions(%s, %+v)", org, perms)
+	return c.request(org, http.MethodPut, orgActionsPath(org, "/workflow"), perms, nil)
+}
+
+// GetRepoActionsPermissions returns the Actions permissions of owner/repo.
+//
+// See https://docs.github.com/en/rest/actions/permissions#get-github-actions-permissions-for-a-repository
+func (c *Client) GetRepoActionsPermissions(owner, repo string) (*RepoActionsPermissions, error) {
+	c.logger.Infof("GetRepoActionsPermissions(%s, %s)", owner, repo)
+	var perms RepoActionsPermissions
+	if err := c.request(owner, http.MethodGet, repoActionsPath(owner, repo, ""), nil, &perms); err != nil {
+		return nil, err
+	}
+	return &perms, nil
+}
+
+// EditRepoActionsPermissions sets the Actions permissions of owner/repo.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-github-actions-permissions-for-a-repository
+func (c *Client) EditRepoActionsPermissions(owner, repo string, perms RepoActionsPermissions) error {
+	c.logger.Infof("EditRepoActionsPermissions(%s, %s, %+v)", owner, repo, perms)
+	return c.request(owner, http.MethodPut, repoActionsPath(owner, repo, ""), perms, nil)
+}
+
+// GetRepoSelectedActions returns the actions owner/repo allows when it
+// allows selected actions.
+//
+// See https://docs.github.com/en/rest/actions/permissions#get-allowed-actions-and-reusable-workflows-for-a-repository
+func (c *Client) GetRepoSelectedActions(owner, repo string) (*SelectedActions, error) {
+	c.logger.Infof("GetRepoSelectedActions(%s, %s)", owner, repo)
+	var selected SelectedActions
+	if err := c.request(owner, http.MethodGet, repoActionsPath(owner, repo, "/selected-actions"), nil, &selected); err != nil {
+		return nil, err
+	}
+	return &selected, nil
+}
+
+// EditRepoSelectedActions sets the actions owner/repo allows when it allows
+// selected actions.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-allowed-actions-and-reusable-workflows-for-a-repository
+func (c *Client) EditRepoSelectedActions(owner, repo string, selected SelectedActions) error {
+	c.logger.Infof("EditRepoSelectedActions(%s, %s, %+v)", owner, repo, selected)
+	return c.request(owner, http.MethodPut, repoActionsPath(owner, repo, "/selected-actions"), selected, nil)
+}
+
+// GetRepoWorkflowPermissions returns the default workflow permissions of
+// owner/repo.
+//
+// See https://docs.github.com/en/rest/actions/permissions#get-default-workflow-permissions-for-a-repository
+func (c *Client) GetRepoWorkflowPermissions(owner, repo string) (*WorkflowPermissions, error) {
+	c.logger.Infof("GetRepoWorkflowPermissions(%s, %s)", owner, repo)
+	var perms WorkflowPermissions
+	if err := c.request(owner, http.MethodGet, repoActionsPath(owner, repo, "/workflow"), nil, &perms); err != nil {
+		return nil, err
+	}
+	return &perms, nil
+}
+
+// EditRepoWorkflowPermissions sets the default workflow permissions of
+// owner/repo.
+//
+// See https://docs.github.com/en/rest/actions/permissions#set-default-workflow-permissions-for-a-repository
+func (c *Client) EditRepoWorkflowPermissions(owner, repo string, perms WorkflowPermissions) error {
+	c.logger.Infof("EditRepoWorkflowPermissions(%s, %s, %+v)", owner, repo, perms)
+	return c.request(owner, http.MethodPut, repoActionsPath(owner, repo, "/workflow"), perms, nil)
+}
+
+// GetRepoID returns the numeric ID of owner/repo.
+//
+// See https://docs.github.com/en/rest/repos/repos#get-a-repository
+func (c *Client) GetRepoID(owner, repo string) (int, error) {
+	c.logger.Infof("GetRepoID(%s, %s)", owner, repo)
+	var resp struct {
+		ID int `json:"id"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+	if err := c.request(owner, http.MethodGet, path, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
@@ -0,0 +1,395 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ghclient extends the prow GitHub client with the REST endpoints
+// peribolos needs that the upstream client does not provide.
+package ghclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/prow/pkg/config/secret"
+	"sigs.k8s.io/prow/pkg/flagutil"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/journal"
+)
+
+const (
+	defaultEndpoint = "https://api.github.com"
+	apiVersion      = "2022-11-28"
+	perPage         = 100
+
+	// The retry settings match the defaults of the prow client.
+	maxRetries   = 8
+	initialDelay = 2 * time.Second
+	maxSleepTime = 2 * time.Minute
+)
+
+// Client is a prow GitHub client extended with additional endpoints.
+type Client struct {
+	github.Client
+
+	endpoint   string
+	graphqlURL string
+	// token returns the token authenticating requests to an org, which is
+	// the installation token of the org with GitHub App authentication.
+	token        func(org string) (string, error)
+	dryRun       bool
+	http         *http.Client
+	throttle     *rate.Limiter
+	initialDelay time.Duration
+	logger       *logrus.Entry
+	journal      *journal.Journal
+}
+
+// Endpoints are the REST and GraphQL API endpoints of the
+// --github-endpoint and --github-graphql-endpoint flags, which
+// flagutil.GitHubOptions does not export. Empty endpoints default to
+// github.com.
+type Endpoints struct {
+	REST    string
+	GraphQL string
+}
+
+// EndpointsFromFlags returns the endpoints of the GitHub flags registered
+// in fs by flagutil.GitHubOptions. The first of several REST endpoints is
+// used, as the additional endpoints do not fall back on the others.
+func EndpointsFromFlags(fs *flag.FlagSet) Endpoints {
+	var endpoints Endpoints
+	if f := fs.Lookup("github-endpoint"); f != nil {
+		endpoints.REST, _, _ = strings.Cut(f.Value.String(), ",")
+	}
+	if f := fs.Lookup("github-graphql-endpoint"); f != nil {
+		endpoints.GraphQL = f.Value.String()
+	}
+	return endpoints
+}
+
+// New returns the prow GitHub client of opts extended with additional
+// endpoints. The additional endpoints are sent to endpoints with the token
+// of --github-token-path or the installation tokens of the GitHub App, and
+// do not mutate anything if dryRun is set.
+func New(opts flagutil.GitHubOptions, endpoints Endpoints, dryRun bool) (*Client, error) {
+	client, err := opts.GitHubClient(dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct GitHub client: %w", err)
+	}
+	if endpoints.REST == "" {
+		endpoints.REST = defaultEndpoint
+	}
+	if endpoints.GraphQL == "" {
+		endpoints.GraphQL = graphqlEndpoint(endpoints.REST) + "/graphql"
+	}
+	token, err := tokenGenerator(opts, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	c := newClient(client, endpoints.REST, token, dryRun)
+	c.graphqlURL = endpoints.GraphQL
+	if opts.ThrottleHourlyTokens > 0 {
+		c.throttle = rate.NewLimiter(rate.Limit(float64(opts.ThrottleHourlyTokens)/time.Hour.Seconds()), opts.ThrottleAllowBurst)
+	}
+	return c, nil
+}
+
+// tokenGenerator returns the token authenticating the requests to an org:
+// the installation token of the org with GitHub App authentication, the
+// --github-token-path token otherwise, or none.
+func tokenGenerator(opts flagutil.GitHubOptions, endpoints Endpoints) (github.TokenGenerator, error) {
+	switch {
+	case opts.AppPrivateKeyPath != "":
+		key, err := secret.AddWithParser(opts.AppPrivateKeyPath, func(raw []byte) (*rsa.PrivateKey, error) {
+			return jwt.ParseRSAPrivateKeyFromPEM(raw)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add GitHub App private key to secret agent: %w", err)
+		}
+		// The client is only used for its installation tokens.
+		token, _, _, err := github.NewClientFromOptions(logrus.Fields{}, github.ClientOptions{
+			Censor:          secret.Censor,
+			AppID:           opts.AppID,
+			AppPrivateKey:   key,
+			Bases:           []string{endpoints.REST},
+			GraphqlEndpoint: endpoints.GraphQL,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct GitHub App token generator: %w", err)
+		}
+		return token, nil
+	case opts.TokenPath != "":
+		if err := secret.Add(opts.TokenPath); err != nil {
+			return nil, fmt.Errorf("failed to add GitHub token to secret agent: %w", err)
+		}
+		token := secret.GetTokenGenerator(opts.TokenPath)
+		return func(string) (string, error) { return string(token()), nil }, nil
+	default:
+		return func(string) (string, error) { return "", nil }, nil
+	}
+}
+
+func newClient(client github.Client, endpoint string, token func(org string) (string, error), dryRun bool) *Client {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	return &Client{
+		Client:       client,
+		endpoint:     endpoint,
+		graphqlURL:   graphqlEndpoint(endpoint) + "/graphql",
+		token:        token,
+		dryRun:       dryRun,
+		http:         &http.Client{Timeout: time.Minute},
+		initialDelay: initialDelay,
+		logger:       logrus.WithField("client", "github"),
+	}
+}
+
+// SetJournal records the changes applied through the client in j.
+func (c *Client) SetJournal(j *journal.Journal) {
+	c.journal = j
+}
+
+// Record journals a change applied to org. Changes are only journaled
+// outside of dry-run mode.
+func (c *Client) Record(org, change, target string, before, after interface{}) {
+	if c.dryRun {
+		return
+	}
+	if err := c.journal.Record(org, change, target, before, after); err != nil {
+		c.logger.WithError(err).Errorf("Failed to journal %s of %s in %s.", change, target, org)
+	}
+}
+
+// RequestError is returned when GitHub responds with a non-2xx status.
+type RequestError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if err is a 404 response from GitHub.
+func IsNotFound(err error) bool {
+	var reqErr *RequestError
+	return errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound
+}
+
+// GraphQLError is the first error reported by the GraphQL API.
+type GraphQLError struct {
+	Code    string
+	Message string
+}
+
+func (e *GraphQLError) Error() string {
+	return "graphql: " + e.Message
+}
+
+// IsUndefinedField returns true if err is a GraphQL error for a field the
+// API does not serve, like the newer fields on GitHub Enterprise Server.
+func IsUndefinedField(err error) bool {
+	var gqlErr *GraphQLError
+	return errors.As(err, &gqlErr) && gqlErr.Code == "undefinedField"
+}
+
+// request sends body as JSON with the token of org and decodes the response
+// into out when non-nil. Mutating requests are logged and skipped in
+// dry-run mode.
+func (c *Client) request(org, method, path string, body, out interface{}) error {
+	if c.dryRun && method != http.MethodGet {
+		c.logger.WithFields(logrus.Fields{"method": method, "path": path}).Debug("Dry run, skipping request.")
+		return nil
+	}
+	return c.send(org, method, c.endpoint, path, body, out)
+}
+
+// send sends body as JSON to the endpoint with the token of org and decodes
+// the response into out when non-nil. Like the prow client, it retries
+// server errors and waits out rate limits.
+func (c *Client) send(org, method, endpoint, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = buf
+	}
+
+	backoff := c.initialDelay
+	for retries := 0; ; retries++ {
+		resp, err := c.do(org, method, endpoint+path, payload)
+		if err != nil {
+			if retries == maxRetries {
+				return err
+			}
+			c.logger.WithError(err).Warnf("%s %s failed, retrying in %s.", method, path, backoff)
+			time.Sleep(backoff)
+			backoff = min(2*backoff, maxSleepTime)
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			reqErr := &RequestError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: string(msg)}
+			wait, retry := retryAfter(resp, backoff)
+			if !retry || retries == maxRetries {
+				return reqErr
+			}
+			c.logger.WithError(reqErr).Warnf("Retrying in %s.", wait)
+			time.Sleep(wait)
+			backoff = min(2*backoff, maxSleepTime)
+			continue
+		}
+
+		defer resp.Body.Close()
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+		}
+		return nil
+	}
+}
+
+// do sends a single request authenticated with the token of org.
+func (c *Client) do(org, method, url string, payload []byte) (*http.Response, error) {
+	if c.throttle != nil {
+		if err := c.throttle.Wait(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	token, err := c.token(org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a token for %s: %w", org, err)
+	}
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.http.Do(req)
+}
+
+// retryAfter returns how long to wait before retrying a failed response and
+// whether to retry it at all. Server errors are retried after backoff, and
+// exhausted rate limits once they reset.
+func retryAfter(resp *http.Response, backoff time.Duration) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode >= 500:
+		return backoff, true
+	case resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests:
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return min(time.Duration(seconds)*time.Second, maxSleepTime), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return backoff, true
+		}
+		return min(max(time.Until(time.Unix(reset, 0)), 0), maxSleepTime), true
+	}
+	return 0, false
+}
+
+// graphql sends a GraphQL query, or a mutation when mutation is set, with
+// the token of org and decodes its data into out when non-nil. Mutations are
+// logged and skipped in dry-run mode.
+func (c *Client) graphql(org, query string, vars map[string]interface{}, mutation bool, out interface{}) error {
+	if c.dryRun && mutation {
+		c.logger.WithField("vars", vars).Debug("Dry run, skipping mutation.")
+		return nil
+	}
+	body := struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{Query: query, Variables: vars}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	i := strings.LastIndex(c.graphqlURL, "/")
+	if err := c.send(org, http.MethodPost, c.graphqlURL[:i], c.graphqlURL[i:], body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return &GraphQLError{Code: resp.Errors[0].Extensions.Code, Message: resp.Errors[0].Message}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+// graphqlEndpoint returns the endpoint of the GraphQL API, which GitHub
+// Enterprise Server serves beside the REST API rather than below it.
+func graphqlEndpoint(endpoint string) string {
+	return strings.TrimSuffix(endpoint, "/v3")
+}
+
+// listPages returns the items of every page of a list endpoint of org.
+func listPages[T any](c *Client, org, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	var all []T
+	for page := 1; ; page++ {
+		var items []T
+		if err := c.request(org, http.MethodGet, fmt.Sprintf("%s%sper_page=%d&page=%d", path, sep, perPage, page), nil, &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < perPage {
+			return all, nil
+		}
+	}
+}
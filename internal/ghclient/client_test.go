@@ -0,0 +1,409 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/flagutil"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+func newTestClient(t *testing.T, dryRun bool, status int, response string) (*Client, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{method: r.Method, path: r.URL.RequestURI(), body: string(body)})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	c := newClient(nil, server.URL, func(string) (string, error) { return "token", nil }, dryRun)
+	c.initialDelay = 0
+	return c, &requests
+}
+
+func TestNewUsesEndpointFlags(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var opts flagutil.GitHubOptions
+	fs := flag.NewFlagSet("github-flags", flag.ContinueOnError)
+	opts.AddCustomizedFlags(fs, flagutil.ThrottlerDefaults(300, 100))
+	args := []string{"--github-endpoint=" + server.URL + "/api/v3", "--github-graphql-endpoint=" + server.URL + "/api/graphql"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c, err := New(opts, EndpointsFromFlags(fs), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.initialDelay = 0
+
+	if err := c.DeleteRepo("org", "repo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.graphql("org", "query { viewer { login } }", nil, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"/api/v3/repos/org/repo", "/api/graphql"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("unexpected paths %v, expected %v", paths, expected)
+	}
+}
+
+// endpointTestCase is a call of an endpoint, along with the response of
+// the server and the request expected to be sent.
+type endpointTestCase struct {
+	name     string
+	status   int
+	response string
+	call     func(c *Client) (any, error)
+	expected any
+	request  recordedRequest
+}
+
+// testEndpoints runs the calls of the test cases. Reads are sent in
+// dry-run too, while mutations are sent otherwise.
+func testEndpoints(t *testing.T, testCases []endpointTestCase) {
+	t.Helper()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, requests := newTestClient(t, tc.request.method == http.MethodGet, tc.status, tc.response)
+			actual, err := tc.call(c)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+			if len(*requests) != 1 || (*requests)[0] != tc.request {
+				t.Errorf("unexpected requests: %+v", *requests)
+			}
+		})
+	}
+}
+
+func TestRepoEndpoints(t *testing.T) {
+	testEndpoints(t, []endpointTestCase{
+		{
+			name:   "delete repo",
+			status: http.StatusNoContent,
+			call: func(c *Client) (any, error) {
+				return nil, c.DeleteRepo("org", "repo")
+			},
+			request: recordedRequest{method: http.MethodDelete, path: "/repos/org/repo"},
+		},
+		{
+			name:     "transfer repo",
+			status:   http.StatusAccepted,
+			response: "{}",
+			call: func(c *Client) (any, error) {
+				return nil, c.TransferRepo("org", "repo", "other")
+			},
+			request: recordedRequest{method: http.MethodPost, path: "/repos/org/repo/transfer", body: `{"new_owner":"other"}`},
+		},
+		{
+			name:     "generate repo",
+			status:   http.StatusCreated,
+			response: `{"name":"repo","full_name":"org/repo"}`,
+			call: func(c *Client) (any, error) {
+				yes := true
+				repo, err := c.GenerateRepo("templates", "service", GenerateRepoRequest{Owner: "org", Name: "repo", IncludeAllBranches: &yes})
+				if err != nil {
+					return nil, err
+				}
+				return repo.FullName, nil
+			},
+			expected: "org/repo",
+			request:  recordedRequest{method: http.MethodPost, path: "/repos/templates/service/generate", body: `{"owner":"org","name":"repo","include_all_branches":true}`},
+		},
+		{
+			name:     "list repo IDs",
+			status:   http.StatusOK,
+			response: `[{"id":42,"name":"repo","private":true}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListOrgRepoIDs("org")
+			},
+			expected: []RepoID{{ID: 42, Name: "repo"}},
+			request:  recordedRequest{method: http.MethodGet, path: "/orgs/org/repos?type=all&per_page=100&page=1"},
+		},
+		{
+			name:     "list collaborators",
+			status:   http.StatusOK,
+			response: `[{"login":"anne","role_name":"triage-plus"}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListRepoCollaborators("org", "repo")
+			},
+			expected: []Collaborator{{Login: "anne", RoleName: "triage-plus"}},
+			request:  recordedRequest{method: http.MethodGet, path: "/repos/org/repo/collaborators?affiliation=direct&per_page=100&page=1"},
+		},
+		{
+			name:     "list invitations",
+			status:   http.StatusOK,
+			response: `[{"id":3,"invitee":{"login":"bob"}}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListRepoInvitations("org", "repo")
+			},
+			expected: []RepoInvitation{{ID: 3, Invitee: github.User{Login: "bob"}}},
+			request:  recordedRequest{method: http.MethodGet, path: "/repos/org/repo/invitations?per_page=100&page=1"},
+		},
+		{
+			name:   "set collaborator",
+			status: http.StatusNoContent,
+			call: func(c *Client) (any, error) {
+				return nil, c.SetRepoCollaborator("org", "repo", "anne", "triage-plus")
+			},
+			request: recordedRequest{method: http.MethodPut, path: "/repos/org/repo/collaborators/anne", body: `{"permission":"triage-plus"}`},
+		},
+		{
+			name:   "remove collaborator",
+			status: http.StatusNoContent,
+			call: func(c *Client) (any, error) {
+				return nil, c.RemoveRepoCollaborator("org", "repo", "anne")
+			},
+			request: recordedRequest{method: http.MethodDelete, path: "/repos/org/repo/collaborators/anne"},
+		},
+	})
+}
+
+func TestActionsEndpoints(t *testing.T) {
+	testEndpoints(t, []endpointTestCase{
+		{
+			name:     "list org repos",
+			status:   http.StatusOK,
+			response: `{"total_count":1,"repositories":[{"id":1,"name":"repo"}]}`,
+			call: func(c *Client) (any, error) {
+				return c.ListOrgActionsRepos("org")
+			},
+			expected: []ActionsRepo{{ID: 1, Name: "repo"}},
+			request:  recordedRequest{method: http.MethodGet, path: "/orgs/org/actions/permissions/repositories?per_page=100&page=1"},
+		},
+		{
+			name:   "set org repos",
+			status: http.StatusNoContent,
+			call: func(c *Client) (any, error) {
+				return nil, c.SetOrgActionsRepos("org", nil)
+			},
+			request: recordedRequest{method: http.MethodPut, path: "/orgs/org/actions/permissions/repositories", body: `{"selected_repository_ids":[]}`},
+		},
+		{
+			name:   "edit repo permissions",
+			status: http.StatusNoContent,
+			call: func(c *Client) (any, error) {
+				return nil, c.EditRepoActionsPermissions("org", "repo", RepoActionsPermissions{})
+			},
+			request: recordedRequest{method: http.MethodPut, path: "/repos/org/repo/actions/permissions", body: `{"enabled":false}`},
+		},
+	})
+}
+
+func TestMemberEndpoints(t *testing.T) {
+	testEndpoints(t, []endpointTestCase{
+		{
+			name:     "list members without 2FA",
+			status:   http.StatusOK,
+			response: `[{"login":"alice"},{"login":"bob"}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListOrgMembersWithout2FA("org")
+			},
+			expected: []github.TeamMember{{Login: "alice"}, {Login: "bob"}},
+			request:  recordedRequest{method: http.MethodGet, path: "/orgs/org/members?filter=2fa_disabled&per_page=100&page=1"},
+		},
+		{
+			name:     "list member users",
+			status:   http.StatusOK,
+			response: `[{"login":"anne","id":1}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListOrgMemberUsers("org")
+			},
+			expected: []github.User{{Login: "anne", ID: 1}},
+			request:  recordedRequest{method: http.MethodGet, path: "/orgs/org/members?per_page=100&page=1"},
+		},
+		{
+			name:     "get user by ID",
+			status:   http.StatusOK,
+			response: `{"login":"renamed","id":42}`,
+			call: func(c *Client) (any, error) {
+				return c.GetUserByID(42)
+			},
+			expected: &github.User{Login: "renamed", ID: 42},
+			request:  recordedRequest{method: http.MethodGet, path: "/user/42"},
+		},
+	})
+}
+
+func TestTeamEndpoints(t *testing.T) {
+	testEndpoints(t, []endpointTestCase{
+		{
+			name:     "list IdP groups",
+			status:   http.StatusOK,
+			response: `{"groups":[{"group_id":"1","group_name":"eng","group_description":"Engineering"}]}`,
+			call: func(c *Client) (any, error) {
+				return c.ListTeamIdPGroups("org", "team")
+			},
+			expected: []IdPGroup{{ID: "1", Name: "eng", Description: "Engineering"}},
+			request:  recordedRequest{method: http.MethodGet, path: "/orgs/org/teams/team/team-sync/group-mappings"},
+		},
+		{
+			name:     "set IdP groups",
+			status:   http.StatusOK,
+			response: `{"groups":[]}`,
+			call: func(c *Client) (any, error) {
+				return nil, c.SetTeamIdPGroups("org", "team", nil)
+			},
+			request: recordedRequest{method: http.MethodPatch, path: "/orgs/org/teams/team/team-sync/group-mappings", body: `{"groups":[]}`},
+		},
+	})
+}
+
+func TestRoleEndpoints(t *testing.T) {
+	testEndpoints(t, []endpointTestCase{
+		{
+			name:     "update custom repo role",
+			status:   http.StatusOK,
+			response: "{}",
+			call: func(c *Client) (any, error) {
+				role := CustomRepoRole{ID: 7, Name: "triage-plus", BaseRole: "triage", Permissions: []string{"add_label"}}
+				return nil, c.UpdateCustomRepoRole("org", role)
+			},
+			request: recordedRequest{
+				method: http.MethodPatch,
+				path:   "/orgs/org/custom-repository-roles/7",
+				body:   `{"id":7,"name":"triage-plus","description":"","base_role":"triage","permissions":["add_label"]}`,
+			},
+		},
+		{
+			name:     "list team repo roles",
+			status:   http.StatusOK,
+			response: `[{"name":"repo","role_name":"triage-plus","permissions":{"pull":true,"triage":true}}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListTeamRepoRoles("org", "team")
+			},
+			expected: []TeamRepoRole{{Name: "repo", RoleName: "triage-plus"}},
+			request:  recordedRequest{method: http.MethodGet, path: "/orgs/org/teams/team/repos?per_page=100&page=1"},
+		},
+		{
+			name:   "assign org role to team",
+			status: http.StatusNoContent,
+			call: func(c *Client) (any, error) {
+				return nil, c.AssignOrgRoleTeam("org", "security", 138)
+			},
+			request: recordedRequest{method: http.MethodPut, path: "/orgs/org/organization-roles/teams/security/138"},
+		},
+	})
+}
+
+func TestGraphQLEndpoint(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		defaultEndpoint:                     defaultEndpoint,
+		"https://github.example.com/api/v3": "https://github.example.com/api",
+	} {
+		if actual := graphqlEndpoint(endpoint); actual != expected {
+			t.Errorf("graphqlEndpoint(%q) = %q, expected %q", endpoint, actual, expected)
+		}
+	}
+}
+
+func TestListTeamReviewExcludedMembers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+		expected []string
+		ok       bool
+	}{
+		{
+			name:     "reported members",
+			response: `{"data":{"organization":{"team":{"reviewRequestDelegationExcludedMembers":{"nodes":[{"login":"anne"}],"pageInfo":{"hasNextPage":false}}}}}}`,
+			expected: []string{"anne"},
+			ok:       true,
+		},
+		{
+			name:     "no reported members",
+			response: `{"data":{"organization":{"team":{"reviewRequestDelegationExcludedMembers":{"nodes":[],"pageInfo":{"hasNextPage":false}}}}}}`,
+			expected: []string{},
+			ok:       true,
+		},
+		{
+			name:     "field not served by the API",
+			response: `{"errors":[{"message":"Field 'reviewRequestDelegationExcludedMembers' doesn't exist on type 'Team'","extensions":{"code":"undefinedField"}}]}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := newTestClient(t, false, http.StatusOK, tc.response)
+			excluded, ok, err := c.ListTeamReviewExcludedMembers("org", "team")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tc.ok {
+				t.Errorf("reported: %t, expected %t", ok, tc.ok)
+			}
+			if !reflect.DeepEqual(excluded, tc.expected) {
+				t.Errorf("excluded: %v, expected %v", excluded, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSetTeamReviewAssignment(t *testing.T) {
+	response := `{"data":{"organization":{"team":{"id":"T_1","reviewRequestDelegationEnabled":false}},"user":{"id":"U_1"}}}`
+	for _, dryRun := range []bool{false, true} {
+		c, requests := newTestClient(t, dryRun, http.StatusOK, response)
+		assignment := ReviewAssignment{Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1}
+		if err := c.SetTeamReviewAssignment("org", "team", assignment, []string{"anne"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := 3
+		if dryRun {
+			expected = 2
+		}
+		if len(*requests) != expected {
+			t.Fatalf("dry-run %t: unexpected requests: %+v", dryRun, *requests)
+		}
+		for _, r := range *requests {
+			if r.method != http.MethodPost || r.path != "/graphql" {
+				t.Errorf("unexpected request: %+v", r)
+			}
+		}
+		if !dryRun && !strings.Contains((*requests)[2].body, `"input":{"algorithm":"ROUND_ROBIN","enabled":true,"excludedTeamMemberIds":["U_1"],"id":"T_1","notifyTeam":false,"teamMemberCount":1}`) {
+			t.Errorf("unexpected mutation: %s", (*requests)[2].body)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/url"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// ListOrgMembersWithout2FA returns the org members that have not enabled
+// two-factor authentication. Only org owners can list them.
+//
+// See https://docs.github.com/en/rest/orgs/members#list-organization-members
+func (c *Client) ListOrgMembersWithout2FA(org string) ([]github.TeamMember, error) {
+	c.logger.Infof("ListOrgMembersWithout2FA(%s)", org)
+	return listPages[github.TeamMember](c, org, fmt.Sprintf("/orgs/%s/members?filter=2fa_disabled", url.PathEscape(org)))
+}
+
+// ListOrgMemberUsers returns the members of org, admins included, along
+// with their user ID.
+//
+// See https://docs.github.com/en/rest/orgs/members#list-organization-members
+func (c *Client) ListOrgMemberUsers(org string) ([]github.User, error) {
+	c.logger.Infof("ListOrgMemberUsers(%s)", org)
+	return listPages[github.User](c, org, fmt.Sprintf("/orgs/%s/members", url.PathEscape(org)))
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OrgSettings are the org settings missing from github.Organization.
+// GitHub omits some of them depending on the plan of the org, and edits
+// only send the non-nil ones.
+type OrgSettings struct {
+	Blog                                 *string `json:"blog,omitempty"`
+	TwitterUsername                      *string `json:"twitter_username,omitempty"`
+	MembersCanCreatePublicRepositories   *bool   `json:"members_can_create_public_repositories,omitempty"`
+	MembersCanCreatePrivateRepositories  *bool   `json:"members_can_create_private_repositories,omitempty"`
+	MembersCanCreateInternalRepositories *bool   `json:"members_can_create_internal_repositories,omitempty"`
+	MembersCanForkPrivateRepositories    *bool   `json:"members_can_fork_private_repositories,omitempty"`
+	MembersCanCreatePages                *bool   `json:"members_can_create_pages,omitempty"`
+	MembersCanCreatePublicPages          *bool   `json:"members_can_create_public_pages,omitempty"`
+	MembersCanCreatePrivatePages         *bool   `json:"members_can_create_private_pages,omitempty"`
+	WebCommitSignoffRequired             *bool   `json:"web_commit_signoff_required,omitempty"`
+	// TwoFactorRequirementEnabled is read-only.
+	TwoFactorRequirementEnabled              *bool `json:"two_factor_requirement_enabled,omitempty"`
+	DependencyGraphEnabledForNewRepositories *bool `json:"dependency_graph_enabled_for_new_repositories,omitempty"`
+	SecretScanningEnabledForNewRepositories  *bool `json:"secret_scanning_enabled_for_new_repositories,omitempty"`
+}
+
+// GetOrgSettings returns the settings of org.
+//
+// See https://docs.github.com/en/rest/orgs/orgs#get-an-organization
+func (c *Client) GetOrgSettings(org string) (*OrgSettings, error) {
+	c.logger.Infof("GetOrgSettings(%s)", org)
+	var settings OrgSettings
+	if err := c.request(org, http.MethodGet, fmt.Sprintf("/orgs/%s", url.PathEscape(org)), nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// EditOrgSettings updates the non-nil settings of org.
+//
+// See https://docs.github.com/en/rest/orgs/orgs#update-an-organization
+func (c *Client) EditOrgSettings(org string, settings OrgSettings) error {
+	c.logger.Infof("EditOrgSettings(%s)", org)
+	return c.request(org, http.MethodPatch, fmt.Sprintf("/orgs/%s", url.PathEscape(org)), settings, nil)
+}
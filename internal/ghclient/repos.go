@@ -0,0 +1,161 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// GenerateRepoRequest describes a repository generated from a template.
+type GenerateRepoRequest struct {
+	Owner              string  `json:"owner"`
+	Name               string  `json:"name"`
+	Description        *string `json:"description,omitempty"`
+	IncludeAllBranches *bool   `json:"include_all_branches,omitempty"`
+	Private            *bool   `json:"private,omitempty"`
+}
+
+// ForkRepoRequest describes a fork created in an organization.
+type ForkRepoRequest struct {
+	Organization      string `json:"organization,omitempty"`
+	Name              string `json:"name,omitempty"`
+	DefaultBranchOnly *bool  `json:"default_branch_only,omitempty"`
+}
+
+// DeleteRepo deletes a repository.
+//
+// See https://docs.github.com/en/rest/repos/repos#delete-a-repository
+func (c *Client) DeleteRepo(owner, repo string) error {
+	c.logger.Infof("DeleteRepo(%s, %s)", owner, repo)
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+	return c.request(owner, http.MethodDelete, path, nil, nil)
+}
+
+// TransferRepo transfers a repository to newOwner.
+//
+// See https://docs.github.com/en/rest/repos/repos#transfer-a-repository
+func (c *Client) TransferRepo(owner, repo, newOwner string) error {
+	c.logger.Infof("TransferRepo(%s, %s, %s)", owner, repo, newOwner)
+	path := fmt.Sprintf("/repos/%s/%s/transfer", url.PathEscape(owner), url.PathEscape(repo))
+	body := struct {
+		NewOwner string `json:"new_owner"`
+	}{NewOwner: newOwner}
+	return c.request(owner, http.MethodPost, path, body, nil)
+}
+
+// GenerateRepo creates a repository from the templateOwner/templateRepo
+// template repository.
+//
+// See https://docs.github.com/en/rest/repos/repos#create-a-repository-using-a-template
+func (c *Client) GenerateRepo(templateOwner, templateRepo string, req GenerateRepoRequest) (*github.FullRepo, error) {
+	c.logger.Infof("GenerateRepo(%s, %s, %s/%s)", templateOwner, templateRepo, req.Owner, req.Name)
+	path := fmt.Sprintf("/repos/%s/%s/generate", url.PathEscape(templateOwner), url.PathEscape(templateRepo))
+	repo := github.FullRepo{Repo: github.Repo{Name: req.Name}}
+	if err := c.request(req.Owner, http.MethodPost, path, req, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// ForkRepo forks the owner/repo repository.
+//
+// See https://docs.github.com/en/rest/repos/forks#create-a-fork
+func (c *Client) ForkRepo(owner, repo string, req ForkRepoRequest) (*github.FullRepo, error) {
+	c.logger.Infof("ForkRepo(%s, %s, %s/%s)", owner, repo, req.Organization, req.Name)
+	path := fmt.Sprintf("/repos/%s/%s/forks", url.PathEscape(owner), url.PathEscape(repo))
+	fork := github.FullRepo{Repo: github.Repo{Name: req.Name}}
+	if err := c.request(req.Organization, http.MethodPost, path, req, &fork); err != nil {
+		return nil, err
+	}
+	return &fork, nil
+}
+
+// RepoID is the numeric ID of a repo, which is kept when the repo is
+// renamed.
+type RepoID struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListOrgRepoIDs returns the IDs of the repos of org.
+//
+// See https://docs.github.com/en/rest/repos/repos#list-organization-repositories
+func (c *Client) ListOrgRepoIDs(org string) ([]RepoID, error) {
+	c.logger.Infof("ListOrgRepoIDs(%s)", org)
+	return listPages[RepoID](c, org, fmt.Sprintf("/orgs/%s/repos?type=all", url.PathEscape(org)))
+}
+
+// Collaborator is a direct collaborator of a repo and its role, either a
+// permission level or the name of a custom repository role.
+type Collaborator struct {
+	Login    string `json:"login"`
+	RoleName string `json:"role_name"`
+}
+
+// ListRepoCollaborators returns the direct collaborators of the repo,
+// leaving out the users with access through the org or its teams.
+//
+// See https://docs.github.com/en/rest/collaborators/collaborators#list-repository-collaborators
+func (c *Client) ListRepoCollaborators(org, repo string) ([]Collaborator, error) {
+	c.logger.Infof("ListRepoCollaborators(%s, %s)", org, repo)
+	return listPages[Collaborator](c, org, fmt.Sprintf("/repos/%s/%s/collaborators?affiliation=direct", url.PathEscape(org), url.PathEscape(repo)))
+}
+
+// RepoInvitation is a pending invitation to collaborate on a repo.
+type RepoInvitation struct {
+	ID      int         `json:"id"`
+	Invitee github.User `json:"invitee"`
+}
+
+// ListRepoInvitations returns the pending collaborator invitations of the
+// repo.
+//
+// See https://docs.github.com/en/rest/collaborators/invitations#list-repository-invitations
+func (c *Client) ListRepoInvitations(org, repo string) ([]RepoInvitation, error) {
+	c.logger.Infof("ListRepoInvitations(%s, %s)", org, repo)
+	return listPages[RepoInvitation](c, org, fmt.Sprintf("/repos/%s/%s/invitations", url.PathEscape(org), url.PathEscape(repo)))
+}
+
+// SetRepoCollaborator grants the user role on the repo, a permission level
+// or the name of a custom repository role. Users who are not collaborators
+// yet are invited.
+//
+// See https://docs.github.com/en/rest/collaborators/collaborators#add-a-repository-collaborator
+func (c *Client) SetRepoCollaborator(org, repo, user, role string) error {
+	c.logger.Infof("SetRepoCollaborator(%s, %s, %s, %s)", org, repo, user, role)
+	body := struct {
+		Permission string `json:"permission"`
+	}{Permission: role}
+	return c.request(org, http.MethodPut, collaboratorPath(org, repo, user), body, nil)
+}
+
+// RemoveRepoCollaborator removes the user from the collaborators of the
+// repo.
+//
+// See https://docs.github.com/en/rest/collaborators/collaborators#remove-a-repository-collaborator
+func (c *Client) RemoveRepoCollaborator(org, repo, user string) error {
+	c.logger.Infof("RemoveRepoCollaborator(%s, %s, %s)", org, repo, user)
+	return c.request(org, http.MethodDelete, collaboratorPath(org, repo, user), nil, nil)
+}
+
+func collaboratorPath(org, repo, user string) string {
+	return fmt.Sprintf("/repos/%s/%s/collaborators/%s", url.PathEscape(org), url.PathEscape(repo), url.PathEscape(user))
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// CustomRepoRole is a custom repository role of an org, which grants the
+// permissions of its base role plus some fine-grained permissions.
+type CustomRepoRole struct {
+	ID          int      `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	BaseRole    string   `json:"base_role"`
+	Permissions []string `json:"permissions"`
+}
+
+// ListCustomRepoRoles returns the custom repository roles of org.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles#list-custom-repository-roles-in-an-organization
+func (c *Client) ListCustomRepoRoles(org string) ([]CustomRepoRole, error) {
+	c.logger.Infof("ListCustomRepoRoles(%s)", org)
+	var resp struct {
+		Roles []CustomRepoRole `json:"custom_roles"`
+	}
+	path := fmt.Sprintf("/orgs/%s/custom-repository-roles", url.PathEscape(org))
+	if err := c.request(org, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Roles, nil
+}
+
+// CreateCustomRepoRole creates a custom repository role in org.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles#create-a-custom-repository-role
+func (c *Client) CreateCustomRepoRole(org string, role CustomRepoRole) error {
+	c.logger.Infof("CreateCustomRepoRole(%s, %s)", org, role.Name)
+	path := fmt.Sprintf("/orgs/%s/custom-repository-roles", url.PathEscape(org))
+	return c.request(org, http.MethodPost, path, role, nil)
+}
+
+// UpdateCustomRepoRole updates the custom repository role role.ID of org.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles#update-a-custom-repository-role
+func (c *Client) UpdateCustomRepoRole(org string, role CustomRepoRole) error {
+	c.logger.Infof("UpdateCustomRepoRole(%s, %s)", org, role.Name)
+	path := fmt.Sprintf("/orgs/%s/custom-repository-roles/%d", url.PathEscape(org), role.ID)
+	return c.request(org, http.MethodPatch, path, role, nil)
+}
+
+// DeleteCustomRepoRole deletes the custom repository role id of org.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles#delete-a-custom-repository-role
+func (c *Client) DeleteCustomRepoRole(org string, id int) error {
+	c.logger.Infof("DeleteCustomRepoRole(%s, %d)", org, id)
+	path := fmt.Sprintf("/orgs/%s/custom-repository-roles/%d", url.PathEscape(org), id)
+	return c.request(org, http.MethodDelete, path, nil, nil)
+}
+
+// TeamRepoRole is the role of a team on a repo, either a permission
+// level or the name of a custom repository role.
+type TeamRepoRole struct {
+	Name     string `json:"name"`
+	RoleName string `json:"role_name"`
+}
+
+// ListTeamRepoRoles returns the roles of the team on its repos, which
+// unlike the repo permissions tell custom repository roles apart.
+//
+// See https://docs.github.com/en/rest/teams/teams#list-team-repositories
+func (c *Client) ListTeamRepoRoles(org, teamSlug string) ([]TeamRepoRole, error) {
+	c.logger.Infof("ListTeamRepoRoles(%s, %s)", org, teamSlug)
+	return listPages[TeamRepoRole](c, org, fmt.Sprintf("/orgs/%s/teams/%s/repos", url.PathEscape(org), url.PathEscape(teamSlug)))
+}
+
+// OrgRole is an organization role, such as security_manager, granting
+// org-wide permissions to the teams and users it is assigned.
+type OrgRole struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListOrgRoles returns the organization roles of org.
+//
+// See https://docs.github.com/en/rest/orgs/organization-roles#get-all-organization-roles-for-an-organization
+func (c *Client) ListOrgRoles(org string) ([]OrgRole, error) {
+	c.logger.Infof("ListOrgRoles(%s)", org)
+	var resp struct {
+		Roles []OrgRole `json:"roles"`
+	}
+	path := fmt.Sprintf("/orgs/%s/organization-roles", url.PathEscape(org))
+	if err := c.request(org, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Roles, nil
+}
+
+// ListOrgRoleTeams returns the teams assigned the organization role id.
+//
+// See https://docs.github.com/en/rest/orgs/organization-roles#list-teams-that-are-assigned-to-an-organization-role
+func (c *Client) ListOrgRoleTeams(org string, id int) ([]github.Team, error) {
+	c.logger.Infof("ListOrgRoleTeams(%s, %d)", org, id)
+	return listPages[github.Team](c, org, fmt.Sprintf("/orgs/%s/organization-roles/%d/teams", url.PathEscape(org), id))
+}
+
+// AssignOrgRoleTeam assigns the organization role id to the team.
+//
+// See https://docs.github.com/en/rest/orgs/organization-roles#assign-an-organization-role-to-a-team
+func (c *Client) AssignOrgRoleTeam(org, teamSlug string, id int) error {
+	c.logger.Infof("AssignOrgRoleTeam(%s, %s, %d)", org, teamSlug, id)
+	return c.request(org, http.MethodPut, orgRoleTeamPath(org, teamSlug, id), nil, nil)
+}
+
+// RemoveOrgRoleTeam removes the organization role id from the team.
+//
+// See https://docs.github.com/en/rest/orgs/organization-roles#remove-an-organization-role-from-a-team
+func (c *Client) RemoveOrgRoleTeam(org, teamSlug string, id int) error {
+	c.logger.Infof("RemoveOrgRoleTeam(%s, %s, %d)", org, teamSlug, id)
+	return c.request(org, http.MethodDelete, orgRoleTeamPath(org, teamSlug, id), nil, nil)
+}
+
+func orgRoleTeamPath(org, teamSlug string, id int) string {
+	return fmt.Sprintf("/orgs/%s/organization-roles/teams/%s/%d", url.PathEscape(org), url.PathEscape(teamSlug), id)
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IdPGroup is an identity provider group a team can be synchronized with.
+type IdPGroup struct {
+	ID          string `json:"group_id"`
+	Name        string `json:"group_name"`
+	Description string `json:"group_description"`
+}
+
+type idpGroups struct {
+	Groups []IdPGroup `json:"groups"`
+}
+
+// ListOrgIdPGroups returns the identity provider groups available to the
+// teams of org. It requires team synchronization to be enabled.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/teams/team-sync#list-idp-groups-for-an-organization
+func (c *Client) ListOrgIdPGroups(org string) ([]IdPGroup, error) {
+	c.logger.Infof("ListOrgIdPGroups(%s)", org)
+	var groups []IdPGroup
+	for page := 1; ; page++ {
+		var resp idpGroups
+		path := fmt.Sprintf("/orgs/%s/team-sync/groups?per_page=%d&page=%d", url.PathEscape(org), perPage, page)
+		if err := c.request(org, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		groups = append(groups, resp.Groups...)
+		if len(resp.Groups) < perPage {
+			return groups, nil
+		}
+	}
+}
+
+// ListTeamIdPGroups returns the identity provider groups the team is
+// synchronized with.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/teams/team-sync#list-idp-groups-for-a-team
+func (c *Client) ListTeamIdPGroups(org, teamSlug string) ([]IdPGroup, error) {
+	c.logger.Infof("ListTeamIdPGroups(%s, %s)", org, teamSlug)
+	var resp idpGroups
+	if err := c.request(org, http.MethodGet, teamSyncPath(org, teamSlug), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Groups, nil
+}
+
+// SetTeamIdPGroups replaces the identity provider groups the team is
+// synchronized with. An empty list unlinks the team.
+//
+// See https://docs.github.com/en/enterprise-cloud@latest/rest/teams/team-sync#create-or-update-idp-group-connections
+func (c *Client) SetTeamIdPGroups(org, teamSlug string, groups []IdPGroup) error {
+	c.logger.Infof("SetTeamIdPGroups(%s, %s, %d groups)", org, teamSlug, len(groups))
+	if groups == nil {
+		groups = []IdPGroup{}
+	}
+	return c.request(org, http.MethodPatch, teamSyncPath(org, teamSlug), idpGroups{Groups: groups}, nil)
+}
+
+func teamSyncPath(org, teamSlug string) string {
+	return fmt.Sprintf("/orgs/%s/teams/%s/team-sync/group-mappings", url.PathEscape(org), url.PathEscape(teamSlug))
+}
+
+// TeamSettings are the team settings missing from github.Team. Edits only
+// send the non-nil ones.
+type TeamSettings struct {
+	NotificationSetting *string `json:"notification_setting,omitempty"`
+	Permission          *string `json:"permission,omitempty"`
+}
+
+// GetTeamSettings returns the settings of the team.
+//
+// See https://docs.github.com/en/rest/teams/teams#get-a-team-by-name
+func (c *Client) GetTeamSettings(org, teamSlug string) (*TeamSettings, error) {
+	c.logger.Infof("GetTeamSettings(%s, %s)", org, teamSlug)
+	var settings TeamSettings
+	if err := c.request(org, http.MethodGet, teamPath(org, teamSlug), nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// EditTeamSettings updates the non-nil settings of the team.
+//
+// See https://docs.github.com/en/rest/teams/teams#update-a-team
+func (c *Client) EditTeamSettings(org, teamSlug string, settings TeamSettings) error {
+	c.logger.Infof("EditTeamSettings(%s, %s)", org, teamSlug)
+	return c.request(org, http.MethodPatch, teamPath(org, teamSlug), settings, nil)
+}
+
+func teamPath(org, teamSlug string) string {
+	return fmt.Sprintf("/orgs/%s/teams/%s", url.PathEscape(org), url.PathEscape(teamSlug))
+}
+
+// ReviewAssignment is the code review assignment of a team, which routes
+// the review requests of the team to some of its members.
+type ReviewAssignment struct {
+	Enabled bool `json:"reviewRequestDelegationEnabled"`
+	// Algorithm is ROUND_ROBIN or LOAD_BALANCE.
+	Algorithm   string `json:"reviewRequestDelegationAlgorithm"`
+	MemberCount int    `json:"reviewRequestDelegationMemberCount"`
+	NotifyTeam  bool   `json:"reviewRequestDelegationNotifyTeam"`
+}
+
+const teamReviewAssignmentQuery = `query($org: String!, $slug: String!) {
+  organization(login: $org) {
+    team(slug: $slug) {
+      id
+      reviewRequestDelegationEnabled
+      reviewRequestDelegationAlgorithm
+      reviewRequestDelegationMemberCount
+      reviewRequestDelegationNotifyTeam
+    }
+  }
+}`
+
+const userIDQuery = `query($login: String!) {
+  user(login: $login) {
+    id
+  }
+}`
+
+const updateTeamReviewAssignmentMutation = `mutation($input: UpdateTeamReviewAssignmentInput!) {
+  updateTeamReviewAssignment(input: $input) {
+    team {
+      id
+    }
+  }
+}`
+
+type teamReviewAssignment struct {
+	Organization struct {
+		Team *struct {
+			ID string `json:"id"`
+			ReviewAssignment
+		} `json:"team"`
+	} `json:"organization"`
+}
+
+func (c *Client) teamReviewAssignment(org, teamSlug string) (*teamReviewAssignment, error) {
+	var resp teamReviewAssignment
+	vars := map[string]interface{}{"org": org, "slug": teamSlug}
+	if err := c.graphql(org, teamReviewAssignmentQuery, vars, false, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Organization.Team == nil {
+		return nil, &RequestError{Method: http.MethodPost, Path: "/graphql", StatusCode: http.StatusNotFound, Message: "team " + teamSlug + " not found"}
+	}
+	return &resp, nil
+}
+
+// GetTeamReviewAssignment returns the code review assignment of the team,
+// without the members excluded from it.
+//
+// See https://docs.github.com/en/graphql/reference/objects#team
+func (c *Client) GetTeamReviewAssignment(org, teamSlug string) (*ReviewAssignment, error) {
+	c.logger.Infof("GetTeamReviewAssignment(%s, %s)", org, teamSlug)
+	resp, err := c.teamReviewAssignment(org, teamSlug)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Organization.Team.ReviewAssignment, nil
+}
+
+const teamReviewExcludedMembersQuery = `query($org: String!, $slug: String!, $cursor: String) {
+  organization(login: $org) {
+    team(slug: $slug) {
+      reviewRequestDelegationExcludedMembers(first: 100, after: $cursor) {
+        nodes {
+          login
+        }
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
+      }
+    }
+  }
+}`
+
+// ListTeamReviewExcludedMembers returns the logins of the members excluded
+// from the code review assignment of the team, and false when the API does
+// not return them.
+//
+// See https://docs.github.com/en/graphql/reference/objects#team
+func (c *Client) ListTeamReviewExcludedMembers(org, teamSlug string) ([]string, bool, error) {
+	c.logger.Infof("ListTeamReviewExcludedMembers(%s, %s)", org, teamSlug)
+	excluded := []string{}
+	vars := map[string]interface{}{"org": org, "slug": teamSlug}
+	for {
+		var resp struct {
+			Organization struct {
+				Team *struct {
+					Excluded struct {
+						Nodes []struct {
+							Login string `json:"login"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"reviewRequestDelegationExcludedMembers"`
+				} `json:"team"`
+			} `json:"organization"`
+		}
+		err := c.graphql(org, teamReviewExcludedMembersQuery, vars, false, &resp)
+		if IsUndefinedField(err) {
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, err
+		}
+		team := resp.Organization.Team
+		if team == nil {
+			return nil, false, &RequestError{Method: http.MethodPost, Path: "/graphql", StatusCode: http.StatusNotFound, Message: "team " + teamSlug + " not found"}
+		}
+		for _, node := range team.Excluded.Nodes {
+			excluded = append(excluded, node.Login)
+		}
+		if !team.Excluded.PageInfo.HasNextPage {
+			return excluded, true, nil
+		}
+		vars["cursor"] = team.Excluded.PageInfo.EndCursor
+	}
+}
+
+// SetTeamReviewAssignment replaces the code review assignment of the team,
+// excluding the excluded logins from it.
+//
+// See https://docs.github.com/en/graphql/reference/mutations#updateteamreviewassignment
+func (c *Client) SetTeamReviewAssignment(org, teamSlug string, assignment ReviewAssignment, excluded []string) error {
+	c.logger.Infof("SetTeamReviewAssignment(%s, %s, %+v, %v)", org, teamSlug, assignment, excluded)
+	resp, err := c.teamReviewAssignment(org, teamSlug)
+	if err != nil {
+		return err
+	}
+	excludedIDs := []string{}
+	for _, login := range excluded {
+		var user struct {
+			User *struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		}
+		if err := c.graphql(org, userIDQuery, map[string]interface{}{"login": login}, false, &user); err != nil {
+			return err
+		}
+		if user.User == nil {
+			return fmt.Errorf("user %s not found", login)
+		}
+		excludedIDs = append(excludedIDs, user.User.ID)
+	}
+	input := map[string]interface{}{
+		"id":                    resp.Organization.Team.ID,
+		"enabled":               assignment.Enabled,
+		"excludedTeamMemberIds": excludedIDs,
+	}
+	if assignment.Enabled {
+		input["algorithm"] = assignment.Algorithm
+		input["teamMemberCount"] = assignment.MemberCount
+		input["notifyTeam"] = assignment.NotifyTeam
+	}
+	return c.graphql(org, updateTeamReviewAssignmentMutation, map[string]interface{}{"input": input}, true, nil)
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ghclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// GetUserByID returns the user with the ID, which unlike the login never
+// changes. The lookup is not scoped to an org, so with GitHub App
+// authentication it fails unless the token generator has a token for
+// requests outside of an installation.
+//
+// See https://docs.github.com/en/rest/users/users#get-a-user-using-their-id
+func (c *Client) GetUserByID(id int) (*github.User, error) {
+	c.logger.Infof("GetUserByID(%d)", id)
+	var user github.User
+	if err := c.request("", http.MethodGet, fmt.Sprintf("/user/%d", id), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -21,8 +21,7 @@ import (
 	"os"
 	"strings"
 
-	"sigs.k8s.io/prow/pkg/config/org"
-
+	"github.com/uwu-tools/peribolos/internal/config"
 	"github.com/uwu-tools/peribolos/internal/yaml"
 )
 
@@ -61,12 +60,12 @@ func (fm FlagMap) Type() string {
 	return "Type() is not implemented"
 }
 
-func UnmarshalPathToOrgConfig(path string) (*org.Config, error) {
+func UnmarshalPathToOrgConfig(path string) (*config.Config, error) {
 	buf, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read: %v", err)
 	}
-	var cfg org.Config
+	var cfg config.Config
 	if err := yaml.Unmarshal(buf, &cfg); err != nil {
 		return nil, fmt.Errorf("unmarshal: %v", err)
 	}
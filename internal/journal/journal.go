@@ -0,0 +1,135 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package journal records the changes peribolos applies to GitHub in an
+// append-only JSON Lines file.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is a change applied to GitHub.
+type Entry struct {
+	Time time.Time `json:"time"`
+	Org  string    `json:"org"`
+	// Actor is the login of the token user, or app:<id> for a GitHub App.
+	Actor string `json:"actor"`
+	// Change is the kind of change, such as team.membership.remove.
+	Change string `json:"change"`
+	// Target is what changed, such as a login, team or repo.
+	Target string      `json:"target,omitempty"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+	// ConfigSHA is the commit of the config being applied.
+	ConfigSHA string `json:"config_sha,omitempty"`
+}
+
+// Journal appends entries to a writer. A nil Journal records nothing.
+type Journal struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	actor  string
+	sha    string
+	now    func() time.Time
+}
+
+// New returns a journal writing to w on behalf of actor.
+func New(w io.Writer, actor, configSHA string) *Journal {
+	return &Journal{w: w, actor: actor, sha: configSHA, now: time.Now}
+}
+
+// Open returns a journal appending to the file at path, creating it when
+// missing.
+func Open(path, actor, configSHA string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j := New(f, actor, configSHA)
+	j.closer = f
+	return j, nil
+}
+
+// Record appends a change to the journal.
+func (j *Journal) Record(org, change, target string, before, after interface{}) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	line, err := json.Marshal(Entry{
+		Time:      j.now().UTC(),
+		Org:       org,
+		Actor:     j.actor,
+		Change:    change,
+		Target:    target,
+		Before:    before,
+		After:     after,
+		ConfigSHA: j.sha,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	if _, err := j.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// Key identifies the target of a kind of change in an org.
+type Key struct {
+	Org    string
+	Change string
+	Target string
+}
+
+// Latest returns the last entry recorded for each target and kind of
+// change in the journal at path. A missing journal has no entries.
+func Latest(path string) (map[Key]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return map[Key]Entry{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+	latest := map[Key]Entry{}
+	dec := json.NewDecoder(f)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err == io.EOF {
+			return latest, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read journal entry: %w", err)
+		}
+		latest[Key{Org: e.Org, Change: e.Change, Target: e.Target}] = e
+	}
+}
+
+// Close closes the file opened by Open.
+func (j *Journal) Close() error {
+	if j == nil || j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	if err := os.WriteFile(path, []byte("{\"existing\":true}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := Open(path, "bot", "abc123")
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	j.now = func() time.Time { return time.Date(2026, time.March, 4, 5, 6, 7, 0, time.FixedZone("", 3600)) }
+	if err := j.Record("org", "org.membership.set", "anne", "member", "admin"); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+	if err := j.Record("org", "team.delete", "old-team", map[string]string{"name": "Old team"}, nil); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("failed to close journal: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`{"existing":true}`,
+		`{"time":"2026-03-04T04:06:07Z","org":"org","actor":"bot","change":"org.membership.set","target":"anne","before":"member","after":"admin","config_sha":"abc123"}`,
+		`{"time":"2026-03-04T04:06:07Z","org":"org","actor":"bot","change":"team.delete","target":"old-team","before":{"name":"Old team"},"config_sha":"abc123"}`,
+	}
+	if actual := strings.Split(strings.TrimSpace(string(raw)), "\n"); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected journal:\n%s\nwant:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *Journal
+	if err := j.Record("org", "team.create", "team", nil, nil); err != nil {
+		t.Errorf("nil journal failed to record: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Errorf("nil journal failed to close: %v", err)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	latest, err := Latest(path)
+	if err != nil || len(latest) != 0 {
+		t.Fatalf("missing journal: got %v, %v", latest, err)
+	}
+
+	raw := strings.Join([]string{
+		`{"org":"org","change":"team.review_assignment.set","target":"team","after":{"excluded_members":["anne"]}}`,
+		`{"org":"org","change":"team.delete","target":"old-team"}`,
+		`{"org":"org","change":"team.review_assignment.set","target":"team","after":{"excluded_members":["bob"]}}`,
+	}, "\n")
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if latest, err = Latest(path); err != nil {
+		t.Fatalf("failed to read journal: %v", err)
+	}
+	if len(latest) != 2 {
+		t.Errorf("unexpected entries: %v", latest)
+	}
+	entry := latest[Key{Org: "org", Change: "team.review_assignment.set", Target: "team"}]
+	if after, ok := entry.After.(map[string]interface{}); !ok || after["excluded_members"].([]interface{})[0] != "bob" {
+		t.Errorf("unexpected last entry: %+v", entry)
+	}
+}
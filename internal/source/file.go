@@ -0,0 +1,116 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultColumn = "login"
+
+// File lists the logins of a CSV file with a header row, or of a JSON file
+// holding a list of logins or of objects.
+type File struct {
+	Path string
+	// Column is the CSV column or JSON field holding the logins, login by
+	// default.
+	Column string
+}
+
+func (f *File) String() string {
+	return "file " + f.Path
+}
+
+// Members returns the non-empty logins of the file.
+func (f *File) Members() ([]string, error) {
+	raw, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	column := f.Column
+	if column == "" {
+		column = defaultColumn
+	}
+	switch ext := strings.ToLower(filepath.Ext(f.Path)); ext {
+	case ".csv":
+		return csvLogins(raw, column)
+	case ".json":
+		return jsonLogins(raw, column)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q, must be .csv or .json", ext)
+	}
+}
+
+func csvLogins(raw []byte, column string) ([]string, error) {
+	records, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+	index := -1
+	for i, name := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("missing %s column", column)
+	}
+	var logins []string
+	for _, record := range records[1:] {
+		if login := strings.TrimSpace(record[index]); login != "" {
+			logins = append(logins, login)
+		}
+	}
+	return logins, nil
+}
+
+func jsonLogins(raw []byte, column string) ([]string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	var logins []string
+	for _, item := range items {
+		var login string
+		if err := json.Unmarshal(item, &login); err != nil {
+			var fields map[string]any
+			if err := json.Unmarshal(item, &fields); err != nil {
+				return nil, fmt.Errorf("%s is neither a login nor an object", item)
+			}
+			s, ok := fields[column].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s has no %s string", item, column)
+			}
+			login = s
+		}
+		if login = strings.TrimSpace(login); login != "" {
+			logins = append(logins, login)
+		}
+	}
+	return logins, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAttribute = "memberUid"
+	ldapTimeout      = time.Minute
+)
+
+// LDAPOptions are the connection settings of the LDAP server.
+type LDAPOptions struct {
+	// URL is the address of the server, such as ldaps://ldap.example.com.
+	URL string
+	// BindDN and Password authenticate the connection, it is anonymous
+	// when BindDN is empty. The password is never sent in clear: over
+	// ldap:// URLs, the connection is upgraded with StartTLS first.
+	BindDN   string
+	Password string
+	// TLSConfig is used for ldaps URLs and StartTLS.
+	TLSConfig *tls.Config
+}
+
+// LDAP lists the members of an LDAP group.
+type LDAP struct {
+	LDAPOptions
+	// Group is the DN of the group.
+	Group string
+	// Attribute is the attribute of the group listing its members,
+	// memberUid by default.
+	Attribute string
+	// LoginAttribute is the attribute of the member entries holding the
+	// logins, when Attribute lists the DNs of the members.
+	LoginAttribute string
+}
+
+func (l *LDAP) String() string {
+	return "ldap " + l.Group
+}
+
+// Members returns the logins of the members of the group.
+func (l *LDAP) Members() ([]string, error) {
+	conn, err := dialLDAP(l.LDAPOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	attribute := l.Attribute
+	if attribute == "" {
+		attribute = defaultAttribute
+	}
+	values, err := attributeValues(conn, l.Group, attribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s of %s: %w", attribute, l.Group, err)
+	}
+	if l.LoginAttribute == "" {
+		return values, nil
+	}
+
+	var logins []string
+	for _, dn := range values {
+		login, err := attributeValues(conn, dn, l.LoginAttribute)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of %s: %w", l.LoginAttribute, dn, err)
+		}
+		if len(login) == 0 {
+			logrus.Warnf("Skipping member %s of %s without %s", dn, l.Group, l.LoginAttribute)
+			continue
+		}
+		logins = append(logins, login[0])
+	}
+	return logins, nil
+}
+
+// dialLDAP connects to the server and binds as opts.BindDN, if any.
+func dialLDAP(opts LDAPOptions) (*ldap.Conn, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("invalid LDAP URL %q, must start with ldap:// or ldaps://", opts.URL)
+	}
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(opts.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", u.Host, err)
+	}
+	conn.SetTimeout(ldapTimeout)
+	if opts.BindDN == "" {
+		return conn, nil
+	}
+	if u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("refusing to bind as %s over %s without TLS, StartTLS failed: %w", opts.BindDN, u.Host, err)
+		}
+	}
+	if err := conn.Bind(opts.BindDN, opts.Password); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind as %s: %w", opts.BindDN, err)
+	}
+	return conn, nil
+}
+
+// attributeValues returns the values of the attribute of the entry.
+func attributeValues(conn *ldap.Conn, dn, attribute string) ([]string, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)", []string{attribute}, nil,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) == 0 {
+		return nil, errors.New("no such entry")
+	}
+	return result.Entries[0].GetEqualFoldAttributeValues(attribute), nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/go-cmp/cmp"
+)
+
+// ldapStandIn is a local LDAP server answering StartTLS, simple binds and
+// base searches of its entries.
+type ldapStandIn struct {
+	bindDN   string
+	password string
+	entries  map[string]map[string][]string
+	// tlsConfig holds the certificate served over ldaps, or after StartTLS
+	// when startTLS is set.
+	tlsConfig *tls.Config
+	ldaps     bool
+	startTLS  bool
+}
+
+func (s ldapStandIn) start(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	scheme := "ldap://"
+	if s.ldaps {
+		l = tls.NewListener(l, s.tlsConfig)
+		scheme = "ldaps://"
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return scheme + l.Addr().String()
+}
+
+func (s ldapStandIn) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	bound := s.bindDN == ""
+	for {
+		msg, err := ber.ReadPacket(conn)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, _ := msg.Children[0].Value.(int64)
+		reply := func(op *ber.Packet) {
+			p := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			p.AppendChild(op)
+			_, _ = conn.Write(p.Bytes())
+		}
+		result := func(tag ber.Tag, code int64, message string) {
+			op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+			op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+			op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, ""))
+			reply(op)
+		}
+		switch op := msg.Children[1]; op.Tag {
+		case ldap.ApplicationExtendedRequest:
+			if !s.startTLS {
+				result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation")
+				continue
+			}
+			result(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, "")
+			conn = tls.Server(conn, s.tlsConfig)
+		case ldap.ApplicationBindRequest:
+			if _, ok := conn.(*tls.Conn); !ok {
+				return // The password must not be sent in clear.
+			}
+			if op.Children[1].Data.String() != s.bindDN || op.Children[2].Data.String() != s.password {
+				result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+				continue
+			}
+			bound = true
+			result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+		case ldap.ApplicationSearchRequest:
+			if !bound {
+				result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights, "insufficient access rights")
+				continue
+			}
+			dn, attribute := op.Children[0].Data.String(), op.Children[7].Children[0].Data.String()
+			entry, ok := s.entries[dn]
+			if !ok {
+				result(ldap.ApplicationSearchResultDone, ldap.LDAPResultNoSuchObject, "no such object")
+				continue
+			}
+			values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+			for _, v := range entry[attribute] {
+				values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+			}
+			attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute, ""))
+			attr.AppendChild(values)
+			attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			attrs.AppendChild(attr)
+			found := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+			found.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+			found.AppendChild(attrs)
+			reply(found)
+			result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, "")
+		default:
+			return
+		}
+	}
+}
+
+// selfSigned returns the server and client TLS configs of a certificate
+// for 127.0.0.1.
+func selfSigned(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+	return server, &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+}
+
+func TestLDAPMembers(t *testing.T) {
+	serverTLS, clientTLS := selfSigned(t)
+	server := ldapStandIn{
+		bindDN:   "cn=peribolos,dc=example,dc=com",
+		password: "secret",
+		entries: map[string]map[string][]string{
+			"cn=eng,ou=groups,dc=example,dc=com": {
+				"memberUid": {"anne", "bob"},
+				"member":    {"uid=anne,ou=people,dc=example,dc=com", "uid=carl,ou=people,dc=example,dc=com"},
+			},
+			"uid=anne,ou=people,dc=example,dc=com": {"githubLogin": {"anne-gh"}},
+			"uid=carl,ou=people,dc=example,dc=com": {},
+		},
+		tlsConfig: serverTLS,
+		startTLS:  true,
+	}
+	url := server.start(t)
+	opts := LDAPOptions{URL: url, BindDN: server.bindDN, Password: server.password, TLSConfig: clientTLS}
+	ldaps := server
+	ldaps.ldaps = true
+	plain := server
+	plain.startTLS = false
+
+	testCases := []struct {
+		name     string
+		source   LDAP
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "logins listed by the group",
+			source:   LDAP{LDAPOptions: opts, Group: "cn=eng,ou=groups,dc=example,dc=com"},
+			expected: []string{"anne", "bob"},
+		},
+		{
+			name: "logins of the member entries",
+			source: LDAP{
+				LDAPOptions:    opts,
+				Group:          "cn=eng,ou=groups,dc=example,dc=com",
+				Attribute:      "member",
+				LoginAttribute: "githubLogin",
+			},
+			expected: []string{"anne-gh"},
+		},
+		{
+			name: "ldaps connection",
+			source: LDAP{
+				LDAPOptions: LDAPOptions{URL: ldaps.start(t), BindDN: server.bindDN, Password: server.password, TLSConfig: clientTLS},
+				Group:       "cn=eng,ou=groups,dc=example,dc=com",
+			},
+			expected: []string{"anne", "bob"},
+		},
+		{
+			name: "bind refused without StartTLS",
+			source: LDAP{
+				LDAPOptions: LDAPOptions{URL: plain.start(t), BindDN: server.bindDN, Password: server.password, TLSConfig: clientTLS},
+				Group:       "cn=eng,ou=groups,dc=example,dc=com",
+			},
+			err: true,
+		},
+		{
+			name:   "unknown group",
+			source: LDAP{LDAPOptions: opts, Group: "cn=unknown,dc=example,dc=com"},
+			err:    true,
+		},
+		{
+			name: "invalid credentials",
+			source: LDAP{
+				LDAPOptions: LDAPOptions{URL: url, BindDN: server.bindDN, Password: "wrong", TLSConfig: clientTLS},
+				Group:       "cn=eng,ou=groups,dc=example,dc=com",
+			},
+			err: true,
+		},
+		{
+			name:   "anonymous connection",
+			source: LDAP{LDAPOptions: LDAPOptions{URL: url}, Group: "cn=eng,ou=groups,dc=example,dc=com"},
+			err:    true,
+		},
+		{
+			name:   "invalid URL",
+			source: LDAP{LDAPOptions: LDAPOptions{URL: "http://" + url[len("ldap://"):]}, Group: "cn=eng"},
+			err:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.source.Members()
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Error("failed to receive an error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected members (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
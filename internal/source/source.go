@@ -0,0 +1,120 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package source resolves the members listed outside of the config, such
+// as in a file exported from an HR system or in an LDAP group.
+package source
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+)
+
+// MemberSource lists the logins of members kept outside of the config.
+type MemberSource interface {
+	// String names the source in logs.
+	String() string
+	// Members returns the logins of the members.
+	Members() ([]string, error)
+}
+
+// Options are the settings shared by the sources.
+type Options struct {
+	LDAP LDAPOptions
+}
+
+// New returns the member source declared by src.
+func New(src config.Source, opts Options) (MemberSource, error) {
+	if err := src.Validate(); err != nil {
+		return nil, err
+	}
+	if src.File != "" {
+		return &File{Path: src.File, Column: src.Column}, nil
+	}
+	return &LDAP{
+		LDAPOptions:    opts.LDAP,
+		Group:          src.LDAP,
+		Attribute:      src.Attribute,
+		LoginAttribute: src.LoginAttribute,
+	}, nil
+}
+
+// Resolve adds the members listed by the sources of the org and of its
+// teams to cfg, recording their source. Members also declared in the
+// config are left as declared, and each source is read once.
+func Resolve(cfg *config.Config, opts Options) error {
+	r := resolver{opts: opts, listed: map[config.Source][]string{}}
+	members, err := r.resolve(cfg.Members, cfg.MembersFrom)
+	if err != nil {
+		return fmt.Errorf("members_from: %w", err)
+	}
+	cfg.Members = members
+	return r.resolveTeams(cfg.Teams)
+}
+
+type resolver struct {
+	opts   Options
+	listed map[config.Source][]string
+}
+
+func (r resolver) resolveTeams(teams map[string]config.Team) error {
+	for name, team := range teams {
+		members, err := r.resolve(team.Members, team.MembersFrom)
+		if err != nil {
+			return fmt.Errorf("team %s members_from: %w", name, err)
+		}
+		team.Members = members
+		if err := r.resolveTeams(team.Children); err != nil {
+			return err
+		}
+		teams[name] = team
+	}
+	return nil
+}
+
+func (r resolver) resolve(declared []config.Member, src *config.Source) ([]config.Member, error) {
+	if src == nil {
+		return declared, nil
+	}
+	ms, err := New(*src, r.opts)
+	if err != nil {
+		return nil, err
+	}
+	logins, ok := r.listed[*src]
+	if !ok {
+		if logins, err = ms.Members(); err != nil {
+			return nil, fmt.Errorf("failed to list the members of %s: %w", ms, err)
+		}
+		r.listed[*src] = logins
+	}
+
+	seen := map[string]bool{}
+	for _, m := range declared {
+		seen[github.NormLogin(m.Login)] = true
+	}
+	members := declared
+	for _, login := range logins {
+		if seen[github.NormLogin(login)] {
+			continue
+		}
+		seen[github.NormLogin(login)] = true
+		members = append(members, config.Member{Login: login, Source: ms.String()})
+	}
+	return members, nil
+}
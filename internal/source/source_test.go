@@ -0,0 +1,163 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileMembers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		column   string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "csv with a login column",
+			file:     "hr.csv",
+			content:  "name,Login\nAnne,anne\nBob, bob \nCarl,\n",
+			expected: []string{"anne", "bob"},
+		},
+		{
+			name:     "csv with another column",
+			file:     "hr.csv",
+			content:  "name,github\nAnne,anne\n",
+			column:   "github",
+			expected: []string{"anne"},
+		},
+		{
+			name:    "csv without the column",
+			file:    "hr.csv",
+			content: "name,email\nAnne,anne@example.com\n",
+			err:     true,
+		},
+		{
+			name:     "json list of logins",
+			file:     "hr.json",
+			content:  `["anne", "bob", ""]`,
+			expected: []string{"anne", "bob"},
+		},
+		{
+			name:     "json list of objects",
+			file:     "hr.json",
+			content:  `[{"github": "anne", "team": "eng"}, "bob"]`,
+			column:   "github",
+			expected: []string{"anne", "bob"},
+		},
+		{
+			name:    "json object without the field",
+			file:    "hr.json",
+			content: `[{"name": "Anne"}]`,
+			err:     true,
+		},
+		{
+			name:    "unsupported extension",
+			file:    "hr.txt",
+			content: "anne\n",
+			err:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &File{Path: writeFile(t, tc.file, tc.content), Column: tc.column}
+			actual, err := f.Members()
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Error("failed to receive an error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected members (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	path := writeFile(t, "hr.csv", "login\nanne\nBob\ncarl\n")
+	from := &config.Source{File: path}
+	cfg := config.Config{
+		Members:     config.NewMembers("bob", "dave"),
+		MembersFrom: from,
+		Teams: map[string]config.Team{
+			"parent": {
+				Children: map[string]config.Team{
+					"child": {MembersFrom: from},
+				},
+			},
+			"static": {Members: config.NewMembers("dave")},
+		},
+	}
+	if err := Resolve(&cfg, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source := "file " + path
+	expected := []config.Member{
+		{Login: "bob"},
+		{Login: "dave"},
+		{Login: "anne", Source: source},
+		{Login: "carl", Source: source},
+	}
+	if diff := cmp.Diff(expected, cfg.Members); diff != "" {
+		t.Errorf("unexpected org members (-want +got):\n%s", diff)
+	}
+	expected = []config.Member{
+		{Login: "anne", Source: source},
+		{Login: "Bob", Source: source},
+		{Login: "carl", Source: source},
+	}
+	if diff := cmp.Diff(expected, cfg.Teams["parent"].Children["child"].Members); diff != "" {
+		t.Errorf("unexpected team members (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(config.NewMembers("dave"), cfg.Teams["static"].Members); diff != "" {
+		t.Errorf("unexpected static team members (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveInvalidSource(t *testing.T) {
+	cfg := config.Config{
+		Teams: map[string]config.Team{
+			"eng": {MembersFrom: &config.Source{File: "hr.csv", LDAP: "cn=eng"}},
+		},
+	}
+	if err := Resolve(&cfg, Options{}); err == nil {
+		t.Error("failed to receive an error")
+	}
+}
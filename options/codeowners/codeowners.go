@@ -0,0 +1,346 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package codeowners renders the CODEOWNERS and Prow OWNERS files of the
+// repos from the code owners declared in the config, and verifies existing
+// CODEOWNERS files.
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/yaml"
+	"github.com/uwu-tools/peribolos/options/merge"
+)
+
+const header = "# Generated by peribolos gen codeowners, do not edit.\n"
+
+type Options struct {
+	// Config is a config file or a directory of org directories, as
+	// accepted by the root command.
+	Config string
+	// People is the people file resolving the person IDs of the config,
+	// see merge.LoadPath.
+	People string
+	// Output is the directory the files are written to, under
+	// <org>/<repo>/.
+	Output string
+	// Owners also renders Prow OWNERS and OWNERS_ALIASES files.
+	Owners bool
+	// Verify is an existing CODEOWNERS file of Repo to check, instead of
+	// rendering the files.
+	Verify string
+	// Repo is the org/repo the verified CODEOWNERS file belongs to.
+	Repo string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// Validate validates codeowners options.
+func (o *Options) Validate() error {
+	switch {
+	case o.Config == "":
+		return errors.New("--config-path required")
+	case o.Verify != "" && o.Output != "":
+		return errors.New("--verify XOR --output, not both")
+	case o.Verify != "":
+		if _, _, ok := strings.Cut(o.Repo, "/"); !ok {
+			return fmt.Errorf("--repo=%q must be org/repo with --verify", o.Repo)
+		}
+	case o.Output == "":
+		return errors.New("--output or --verify required")
+	}
+	return nil
+}
+
+// Run writes the rendered files below the output directory, or reports
+// the problems of the verified CODEOWNERS file.
+func (o *Options) Run(out io.Writer, now time.Time) error {
+	cfg, err := merge.LoadPath(o.Config, o.People, nil)
+	if err != nil {
+		return err
+	}
+
+	if o.Verify != "" {
+		orgName, repo, _ := strings.Cut(o.Repo, "/")
+		orgConfig, ok := cfg.Orgs[orgName]
+		if !ok {
+			return fmt.Errorf("org %s is not declared in %s", orgName, o.Config)
+		}
+		raw, err := os.ReadFile(o.Verify)
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", o.Verify, err)
+		}
+		problems := Verify(orgConfig, orgName, repo, string(raw), now)
+		for _, p := range problems {
+			fmt.Fprintf(out, "%s:%s\n", o.Verify, p)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s references %d teams which cannot own %s", o.Verify, len(problems), o.Repo)
+		}
+		return nil
+	}
+
+	files, err := Render(*cfg, o.Owners, now)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		target := filepath.Join(o.Output, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("could not create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, []byte(files[name]), 0o644); err != nil {
+			return fmt.Errorf("could not write %s: %w", target, err)
+		}
+		fmt.Fprintln(out, target)
+	}
+	return nil
+}
+
+// Render returns the content of the files rendered for each repo with
+// code owners, keyed by their slash separated path: the
+// <org>/<repo>/.github/CODEOWNERS file and, with owners, the Prow OWNERS
+// files of the owned directories and <org>/<repo>/OWNERS_ALIASES.
+//
+// Every code owner must be a team of the org with write access to the
+// repo at now, as GitHub otherwise ignores it.
+func Render(cfg config.FullConfig, owners bool, now time.Time) (map[string]string, error) {
+	files := map[string]string{}
+	for orgName, orgConfig := range cfg.Orgs {
+		teams := flatten(orgConfig.Effective(now).Teams)
+		for repo, repoConfig := range orgConfig.Repos {
+			if len(repoConfig.CodeOwners) == 0 {
+				continue
+			}
+			levels := orgConfig.RepoLevels(repo, now)
+			var lines []string
+			for _, owner := range repoConfig.CodeOwners {
+				if owner.Path == "" || strings.ContainsAny(owner.Path, " \t") || len(owner.Teams) == 0 {
+					return nil, fmt.Errorf("%s/%s has invalid code owner %+v", orgName, repo, owner)
+				}
+				refs := make([]string, 0, len(owner.Teams))
+				for _, name := range owner.Teams {
+					if _, ok := teams[name]; !ok {
+						return nil, fmt.Errorf("%s/%s code owner %s is not a team of %s", orgName, repo, name, orgName)
+					}
+					if !config.Grants(levels[name], github.Write) {
+						return nil, fmt.Errorf("%s/%s code owner %s lacks write access to %s", orgName, repo, name, repo)
+					}
+					refs = append(refs, fmt.Sprintf("@%s/%s", orgName, Slug(name)))
+				}
+				lines = append(lines, owner.Path+" "+strings.Join(refs, " "))
+			}
+			base := orgName + "/" + repo
+			files[base+"/.github/CODEOWNERS"] = header + strings.Join(lines, "\n") + "\n"
+
+			if !owners {
+				continue
+			}
+			rendered, err := renderOwners(base, repoConfig.CodeOwners, teams)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", base, err)
+			}
+			for name, content := range rendered {
+				files[name] = content
+			}
+		}
+	}
+	return files, nil
+}
+
+// ownersFile is a Prow OWNERS file, approving with the aliases of teams.
+type ownersFile struct {
+	Approvers []string       `json:"approvers"`
+	Options   *ownersOptions `json:"options,omitempty"`
+}
+
+type ownersOptions struct {
+	NoParentOwners bool `json:"no_parent_owners,omitempty"`
+}
+
+type ownersAliases struct {
+	Aliases map[string][]string `json:"aliases"`
+}
+
+// renderOwners renders an OWNERS file for each directory owned by
+// codeOwners, and the OWNERS_ALIASES file resolving each owning team to
+// its members. Directory OWNERS files do not inherit the parent approvers,
+// as owners of a subdirectory in CODEOWNERS replace the ones of the root.
+func renderOwners(base string, codeOwners []config.CodeOwner, teams map[string]org.Team) (map[string]string, error) {
+	dirs := map[string][]string{}
+	for _, owner := range codeOwners {
+		dir, ok := ownedDir(owner.Path)
+		if !ok {
+			logrus.Warnf("%s code owners of %s have no OWNERS equivalent, skipping", base, owner.Path)
+			continue
+		}
+		// The last matching path wins, like in CODEOWNERS.
+		dirs[dir] = owner.Teams
+	}
+
+	files := map[string]string{}
+	aliases := map[string][]string{}
+	for dir, names := range dirs {
+		owners := ownersFile{}
+		if dir != "" {
+			owners.Options = &ownersOptions{NoParentOwners: true}
+		}
+		for _, name := range names {
+			alias := Slug(name)
+			owners.Approvers = append(owners.Approvers, alias)
+			aliases[alias] = members(teams[name])
+		}
+		sort.Strings(owners.Approvers)
+		out, err := yaml.Marshal(owners)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling %s OWNERS: %w", dir, err)
+		}
+		files[path.Join(base, dir, "OWNERS")] = header + string(out)
+	}
+	if len(aliases) == 0 {
+		return files, nil
+	}
+	out, err := yaml.Marshal(ownersAliases{Aliases: aliases})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling OWNERS_ALIASES: %w", err)
+	}
+	files[base+"/OWNERS_ALIASES"] = header + string(out)
+	return files, nil
+}
+
+// ownedDir returns the directory relative to the repo root a CODEOWNERS
+// pattern owns, which is false for file and glob patterns.
+func ownedDir(pattern string) (string, bool) {
+	switch {
+	case pattern == "*" || pattern == "/":
+		return "", true
+	case strings.ContainsAny(pattern, "*?[\\"), !strings.HasSuffix(pattern, "/"):
+		return "", false
+	}
+	return strings.Trim(pattern, "/"), true
+}
+
+// members returns the logins of the maintainers and members of team and
+// its child teams, which GitHub requests reviews from.
+func members(team org.Team) []string {
+	logins := sets.New[string]()
+	var walk func(team org.Team)
+	walk = func(team org.Team) {
+		for _, login := range append(append([]string(nil), team.Maintainers...), team.Members...) {
+			logins.Insert(github.NormLogin(login))
+		}
+		for _, child := range team.Children {
+			walk(child)
+		}
+	}
+	walk(team)
+	return sets.List(logins)
+}
+
+// flatten returns the teams of the org by name, child teams included.
+func flatten(teams map[string]org.Team) map[string]org.Team {
+	all := map[string]org.Team{}
+	var walk func(teams map[string]org.Team)
+	walk = func(teams map[string]org.Team) {
+		for name, team := range teams {
+			all[name] = team
+			walk(team.Children)
+		}
+	}
+	walk(teams)
+	return all
+}
+
+// Verify returns a problem for each team referenced by the CODEOWNERS
+// content which is not a team of the org with write access to repo at now,
+// prefixed by its line number. Users and email addresses are not checked.
+func Verify(cfg config.Config, orgName, repo, content string, now time.Time) []string {
+	slugs := map[string]string{}
+	for name := range flatten(cfg.Effective(now).Teams) {
+		slugs[Slug(name)] = name
+	}
+	levels := cfg.RepoLevels(repo, now)
+
+	var problems []string
+	for i, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, ref := range fields[1:] {
+			if strings.HasPrefix(ref, "#") {
+				break
+			}
+			if !strings.HasPrefix(ref, "@") {
+				continue // an email address
+			}
+			owner, slug, ok := strings.Cut(ref[1:], "/")
+			if !ok {
+				continue // a user
+			}
+			name, found := slugs[strings.ToLower(slug)]
+			switch {
+			case !strings.EqualFold(owner, orgName), !found:
+				problems = append(problems, fmt.Sprintf("%d: team %s is not declared in %s", i+1, ref, orgName))
+			case !config.Grants(levels[name], github.Write):
+				problems = append(problems, fmt.Sprintf("%d: team %s lacks write access to %s", i+1, ref, repo))
+			}
+		}
+	}
+	return problems
+}
+
+// Slug returns the slug GitHub derives from a team name: lowercase, with
+// each run of other characters than letters, digits and underscores
+// replaced by a dash.
+func Slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
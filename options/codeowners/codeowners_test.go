@@ -0,0 +1,162 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package codeowners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+const raw = `
+orgs:
+  foo:
+    custom_repo_roles:
+      reviewer:
+        base_role: write
+    teams:
+      Release Managers:
+        maintainers:
+        - Alice
+        repos:
+          widget: maintain
+        teams:
+          docs:
+            members:
+            - bob
+      readers:
+        members:
+        - carol
+        repos:
+          widget: read
+      reviewers:
+        members:
+        - dave
+        repos:
+          widget: reviewer
+      contractors:
+        members:
+        - erin
+        repos:
+          widget:
+            permission: write
+            expires: 2026-05-31
+    repos:
+      widget:
+        code_owners:
+        - path: "*"
+          teams: [Release Managers]
+        - path: /docs/
+          teams: [docs, reviewers]
+        - path: "*.md"
+          teams: [docs]
+`
+
+func loadConfig(t *testing.T, raw string) config.FullConfig {
+	t.Helper()
+	var cfg config.FullConfig
+	if err := yaml.Unmarshal([]byte(raw), &cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	files, err := Render(loadConfig(t, raw), true, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"foo/widget/.github/CODEOWNERS": header +
+			"* @foo/release-managers\n" +
+			"/docs/ @foo/docs @foo/reviewers\n" +
+			"*.md @foo/docs\n",
+		"foo/widget/OWNERS": header +
+			"approvers:\n- release-managers\n",
+		"foo/widget/docs/OWNERS": header +
+			"approvers:\n- docs\n- reviewers\noptions:\n  no_parent_owners: true\n",
+		"foo/widget/OWNERS_ALIASES": header +
+			"aliases:\n  docs:\n  - bob\n  release-managers:\n  - alice\n  - bob\n  reviewers:\n  - dave\n",
+	}
+	if diff := cmp.Diff(expected, files); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestRenderRejectsOwnersWithoutWriteAccess(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name  string
+		teams string
+	}{
+		{name: "read access", teams: "[readers]"},
+		{name: "expired access", teams: "[contractors]"},
+		{name: "undeclared team", teams: "[ghosts]"},
+	}
+	for _, tc := range testCases {
+		cfg := loadConfig(t, raw)
+		repo := cfg.Orgs["foo"].Repos["widget"]
+		repo.CodeOwners = []config.CodeOwner{{Path: "*"}}
+		if err := yaml.Unmarshal([]byte(tc.teams), &repo.CodeOwners[0].Teams); err != nil {
+			t.Fatalf("%s: failed to load teams: %v", tc.name, err)
+		}
+		cfg.Orgs["foo"].Repos["widget"] = repo
+		if _, err := Render(cfg, false, now); err == nil {
+			t.Errorf("%s: failed to receive error", tc.name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	content := `# Owners of widget
+* @foo/release-managers @someone
+/docs/ @foo/Docs @foo/reviewers # docs
+/api/ @foo/readers owner@example.com
+/contrib/ @foo/contractors @foo/ghosts
+/vendor/ @bar/release-managers
+`
+	expected := []string{
+		"4: team @foo/readers lacks write access to widget",
+		"5: team @foo/contractors lacks write access to widget",
+		"5: team @foo/ghosts is not declared in foo",
+		"6: team @bar/release-managers is not declared in foo",
+	}
+	actual := Verify(loadConfig(t, raw).Orgs["foo"], "foo", "widget", content, now)
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected problems (-want +got):\n%s", diff)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	testCases := map[string]string{
+		"release-managers":   "release-managers",
+		"Release Managers":   "release-managers",
+		"Foo & Bar (Legacy)": "foo-bar-legacy",
+		"sig_node":           "sig_node",
+	}
+	for name, expected := range testCases {
+		if actual := Slug(name); actual != expected {
+			t.Errorf("%s: got %s, want %s", name, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package expiring lists the memberships and team repo permissions which
+// expire soon.
+package expiring
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/options/merge"
+)
+
+type Options struct {
+	// Config is a config file or a directory of org directories, as
+	// accepted by the root command.
+	Config string
+	// People is the people file resolving the person IDs of the config,
+	// see merge.LoadPath.
+	People string
+	// Within is the window to look ahead, such as 14d or 36h.
+	Within string
+}
+
+func NewOptions() *Options {
+	return &Options{Within: "14d"}
+}
+
+// Validate validates expiring options.
+func (o *Options) Validate() error {
+	if o.Config == "" {
+		return errors.New("--config-path required")
+	}
+	if _, err := ParseWithin(o.Within); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseWithin parses a duration accepting a d suffix for days on top of
+// the units of time.ParseDuration.
+func ParseWithin(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("--within=%s must be a number of days like 14d or a duration like 36h", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("--within=%s must be a number of days like 14d or a duration like 36h", s)
+	}
+	return d, nil
+}
+
+// Run prints the entries expiring before now plus the window, including
+// the ones which already expired and are still declared.
+func (o *Options) Run(out io.Writer, now time.Time) error {
+	within, err := ParseWithin(o.Within)
+	if err != nil {
+		return err
+	}
+	cfg, err := merge.LoadPath(o.Config, o.People, nil)
+	if err != nil {
+		return err
+	}
+	for _, line := range List(*cfg, now, within) {
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+// List returns one line per entry expiring before now plus within, the
+// earliest first.
+func List(cfg config.FullConfig, now time.Time, within time.Duration) []string {
+	orgs := make([]string, 0, len(cfg.Orgs))
+	for name := range cfg.Orgs {
+		orgs = append(orgs, name)
+	}
+	sort.Strings(orgs)
+
+	type entry struct {
+		org    string
+		expiry config.Expiry
+	}
+	var entries []entry
+	deadline := now.Add(within)
+	for _, name := range orgs {
+		for _, e := range cfg.Orgs[name].Expiries() {
+			if e.Expires.Before(deadline) {
+				entries = append(entries, entry{org: name, expiry: e})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].expiry.Expires.Before(entries[j].expiry.Expires.Time)
+	})
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := fmt.Sprintf("%s: %s", e.org, e.expiry)
+		if e.expiry.Expires.Passed(now) {
+			line += " (expired)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
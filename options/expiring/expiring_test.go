@@ -0,0 +1,90 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package expiring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+func TestParseWithin(t *testing.T) {
+	testCases := []struct {
+		in          string
+		expected    time.Duration
+		expectError bool
+	}{
+		{in: "14d", expected: 14 * 24 * time.Hour},
+		{in: "36h", expected: 36 * time.Hour},
+		{in: "0d"},
+		{in: "two weeks", expectError: true},
+		{in: "-1d", expectError: true},
+		{in: "d", expectError: true},
+	}
+	for _, tc := range testCases {
+		actual, err := ParseWithin(tc.in)
+		switch {
+		case err != nil:
+			if !tc.expectError {
+				t.Errorf("%s: unexpected error: %v", tc.in, err)
+			}
+		case tc.expectError:
+			t.Errorf("%s: failed to receive error", tc.in)
+		case actual != tc.expected:
+			t.Errorf("%s: got %s, want %s", tc.in, actual, tc.expected)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	raw := `
+orgs:
+  foo:
+    admins:
+    - login: responder
+      expires: 2026-06-20
+    members:
+    - login: intern
+      expires: 2026-05-31
+    - login: contractor
+      expires: 2026-12-31
+  bar:
+    teams:
+      oncall:
+        repos:
+          incident:
+            permission: admin
+            expires: 2026-06-02
+`
+	var cfg config.FullConfig
+	if err := yaml.Unmarshal([]byte(raw), &cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	expected := []string{
+		"foo: org member intern expires 2026-05-31 (expired)",
+		"bar: admin permission of team oncall on repo incident expires 2026-06-02",
+		"foo: org admin responder expires 2026-06-20",
+	}
+	if diff := cmp.Diff(expected, List(cfg, now, 20*24*time.Hour)); diff != "" {
+		t.Errorf("unexpected expiries (-want +got):\n%s", diff)
+	}
+}
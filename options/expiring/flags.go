@@ -0,0 +1,45 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package expiring
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// AddFlags adds this options' flags to the cobra command.
+func (o *Options) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&o.Config,
+		"config-path",
+		"",
+		"Path to org config.yaml, or a directory of org directories",
+	)
+
+	cmd.Flags().StringVar(
+		&o.People,
+		"people",
+		"",
+		"Path to a people.yaml file mapping the person IDs of the config to GitHub logins, defaults to people.yaml in the --config-path directory",
+	)
+
+	cmd.Flags().StringVar(
+		&o.Within,
+		"within",
+		o.Within,
+		"List the entries expiring within this window, in days like 14d or as a duration like 36h",
+	)
+}
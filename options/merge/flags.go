@@ -43,6 +43,13 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		"Never configure teams",
 	)
 
+	cmd.Flags().StringVar(
+		&o.People,
+		"people",
+		"",
+		"Resolve the person IDs of the configs with this people.yaml file",
+	)
+
 	for _, a := range cmd.Flags().Args() {
 		logrus.Print("Extra", a)
 		_ = o.Orgs.Set(a)
@@ -23,8 +23,8 @@ import (
 	"path/filepath"
 
 	"github.com/sirupsen/logrus"
-	"sigs.k8s.io/prow/pkg/config/org"
 
+	"github.com/uwu-tools/peribolos/internal/config"
 	"github.com/uwu-tools/peribolos/internal/helpers"
 	"github.com/uwu-tools/peribolos/internal/yaml"
 )
@@ -33,6 +33,11 @@ type Options struct {
 	Orgs        helpers.FlagMap
 	MergeTeams  bool
 	IgnoreTeams bool
+
+	// People is the path of the people file resolving person IDs, see
+	// ResolvePeople. Users, when set, follows renamed logins.
+	People string
+	Users  UserLookup
 }
 
 func NewOptions() *Options {
@@ -45,15 +50,11 @@ func NewOptions() *Options {
 
 var errValidate = errors.New("some options could not be validated")
 
-// Run merges org configuration files.
-func (o *Options) Run() (*org.FullConfig, error) {
-	cfg, err := loadOrgs(*o)
+// Run merges org configuration files and prints the result.
+func (o *Options) Run() (*config.FullConfig, error) {
+	pc, err := o.Load()
 	if err != nil {
-		return nil, fmt.Errorf("loading orgs: %v", err)
-	}
-
-	pc := org.FullConfig{
-		Orgs: cfg,
+		return nil, err
 	}
 	out, err := yaml.Marshal(pc)
 	if err != nil {
@@ -62,7 +63,77 @@ func (o *Options) Run() (*org.FullConfig, error) {
 
 	// TODO(merge): Consider adding options to output the config (via stdout, file)
 	fmt.Println(string(out))
-	return &pc, nil
+	return pc, nil
+}
+
+// Load merges org configuration files.
+func (o *Options) Load() (*config.FullConfig, error) {
+	cfg, err := loadOrgs(*o)
+	if err != nil {
+		return nil, fmt.Errorf("loading orgs: %v", err)
+	}
+	if o.People != "" {
+		if err := ResolvePeople(cfg, o.People, o.Users); err != nil {
+			return nil, fmt.Errorf("resolving people: %v", err)
+		}
+	}
+	return &config.FullConfig{Orgs: cfg}, nil
+}
+
+// PeopleFileName is the people file looked up in a directory of org
+// directories when no people file is given.
+const PeopleFileName = "people.yaml"
+
+// LoadPath loads a config file, or merges the org.yaml files of a directory
+// of org directories with their team files. The person IDs of the config
+// are resolved with the people file, which defaults to the PeopleFileName
+// file of the directory. Users, when set, follows renamed logins.
+func LoadPath(path, people string, users UserLookup) (*config.FullConfig, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve file info for %s: %w", path, err)
+	}
+	if info.IsDir() {
+		files, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s directory: %w", path, err)
+		}
+		mergeOpts := NewOptions()
+		mergeOpts.MergeTeams = true
+		mergeOpts.People = people
+		if mergeOpts.People == "" {
+			if _, err := os.Stat(filepath.Join(path, PeopleFileName)); err == nil {
+				mergeOpts.People = filepath.Join(path, PeopleFileName)
+			}
+		}
+		mergeOpts.Users = users
+		for _, f := range files {
+			if f.IsDir() {
+				logrus.Infof("Adding config for org: %s", f.Name())
+				mergeOpts.Orgs[f.Name()] = filepath.Join(path, f.Name(), "org.yaml")
+			}
+		}
+		return mergeOpts.Load()
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	var cfg config.FullConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	for name, orgConfig := range cfg.Orgs {
+		resolveFiles(&orgConfig, filepath.Dir(path))
+		cfg.Orgs[name] = orgConfig
+	}
+	if people != "" {
+		if err := ResolvePeople(cfg.Orgs, people, users); err != nil {
+			return nil, fmt.Errorf("resolving people: %v", err)
+		}
+	}
+	return &cfg, nil
 }
 
 // Validate validates merge options.
@@ -85,20 +156,67 @@ func (o *Options) Validate() error {
 	return nil
 }
 
-func unmarshal(path string) (*org.Config, error) {
+func unmarshal(path string) (*config.Config, error) {
 	buf, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read: %v", err)
 	}
-	var cfg org.Config
+	var cfg config.Config
 	if err := yaml.Unmarshal(buf, &cfg); err != nil {
 		return nil, fmt.Errorf("unmarshal: %v", err)
 	}
+	resolveFiles(&cfg, filepath.Dir(path))
 	return &cfg, nil
 }
 
-func loadOrgs(o Options) (map[string]org.Config, error) {
-	config := map[string]org.Config{}
+// resolveFiles joins the relative members_from files of the org and of its
+// teams, and the relative secret files of its webhooks, to dir, the
+// directory of the config file declaring them.
+func resolveFiles(cfg *config.Config, dir string) {
+	cfg.MembersFrom = resolveSourceFile(cfg.MembersFrom, dir)
+	resolveTeamSourceFiles(cfg.Teams, dir)
+	cfg.Webhooks = resolveSecretFiles(cfg.Webhooks, dir)
+	for name, repo := range cfg.Repos {
+		repo.Webhooks = resolveSecretFiles(repo.Webhooks, dir)
+		cfg.Repos[name] = repo
+	}
+}
+
+func resolveTeamSourceFiles(teams map[string]config.Team, dir string) {
+	for name, team := range teams {
+		team.MembersFrom = resolveSourceFile(team.MembersFrom, dir)
+		resolveTeamSourceFiles(team.Children, dir)
+		teams[name] = team
+	}
+}
+
+func resolveSourceFile(src *config.Source, dir string) *config.Source {
+	if src == nil || src.File == "" || filepath.IsAbs(src.File) {
+		return src
+	}
+	resolved := *src
+	resolved.File = filepath.Join(dir, src.File)
+	return &resolved
+}
+
+func resolveSecretFiles(hooks []config.Webhook, dir string) []config.Webhook {
+	if hooks == nil {
+		return nil
+	}
+	resolved := make([]config.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		if hook.Secret != nil && hook.Secret.File != "" && !filepath.IsAbs(hook.Secret.File) {
+			secret := *hook.Secret
+			secret.File = filepath.Join(dir, secret.File)
+			hook.Secret = &secret
+		}
+		resolved = append(resolved, hook)
+	}
+	return resolved
+}
+
+func loadOrgs(o Options) (map[string]config.Config, error) {
+	orgs := map[string]config.Config{}
 	for name, path := range o.Orgs {
 		cfg, err := unmarshal(path)
 		if err != nil {
@@ -109,35 +227,72 @@ func loadOrgs(o Options) (map[string]org.Config, error) {
 			cfg.Teams = nil
 		case o.MergeTeams:
 			if cfg.Teams == nil {
-				cfg.Teams = map[string]org.Team{}
+				cfg.Teams = map[string]config.Team{}
 			}
-			prefix := filepath.Dir(path)
-			err := filepath.Walk(prefix, func(path string, info os.FileInfo, err error) error {
-				switch {
-				case path == prefix:
-					return nil // Skip base dir
-				case info.IsDir() && filepath.Dir(path) != prefix:
-					logrus.Infof("Skipping %s and its children", path)
-					return filepath.SkipDir // Skip prefix/foo/bar/ dirs
-				case !info.IsDir() && filepath.Dir(path) == prefix:
-					return nil // Ignore prefix/foo files
-				case filepath.Base(path) == "teams.yaml":
-					teamCfg, err := unmarshal(path)
-					if err != nil {
-						return fmt.Errorf("error in %s: %v", path, err)
-					}
-
-					for name, team := range teamCfg.Teams {
-						cfg.Teams[name] = team
-					}
-				}
-				return nil
-			})
+			files, err := teamFiles(path)
 			if err != nil {
 				return nil, fmt.Errorf("merge teams %s: %v", path, err)
 			}
+			for _, file := range files {
+				teamCfg, err := unmarshal(file)
+				if err != nil {
+					return nil, fmt.Errorf("merge teams %s: error in %s: %v", path, file, err)
+				}
+				for name, team := range teamCfg.Teams {
+					cfg.Teams[name] = team
+				}
+			}
+		}
+		orgs[name] = *cfg
+	}
+	return orgs, nil
+}
+
+// teamFiles returns the teams.yaml files merged into the teams of the org
+// config at path, which are in the direct subdirectories of its directory.
+func teamFiles(path string) ([]string, error) {
+	var files []string
+	prefix := filepath.Dir(path)
+	err := filepath.Walk(prefix, func(path string, info os.FileInfo, err error) error {
+		switch {
+		case path == prefix:
+			return nil // Skip base dir
+		case info.IsDir() && filepath.Dir(path) != prefix:
+			logrus.Infof("Skipping %s and its children", path)
+			return filepath.SkipDir // Skip prefix/foo/bar/ dirs
+		case !info.IsDir() && filepath.Dir(path) == prefix:
+			return nil // Ignore prefix/foo files
+		case filepath.Base(path) == "teams.yaml":
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// TeamSources returns the file declaring each team of the org config at
+// path, the teams.yaml file it is merged from or path itself.
+func TeamSources(path string) (map[string]string, error) {
+	cfg, err := unmarshal(path)
+	if err != nil {
+		return nil, fmt.Errorf("error in %s: %v", path, err)
+	}
+	sources := map[string]string{}
+	for name := range cfg.Teams {
+		sources[name] = path
+	}
+	files, err := teamFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("merge teams %s: %v", path, err)
+	}
+	for _, file := range files {
+		teamCfg, err := unmarshal(file)
+		if err != nil {
+			return nil, fmt.Errorf("error in %s: %v", file, err)
+		}
+		for name := range teamCfg.Teams {
+			sources[name] = file
 		}
-		config[name] = *cfg
 	}
-	return config, nil
+	return sources, nil
 }
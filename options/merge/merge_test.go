@@ -0,0 +1,100 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPathResolvesFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "org", "org.yaml"), "members_from:\n  file: hr/org.csv\nwebhooks:\n- url: https://ci.example.com/hook\n  secret:\n    file: secrets/hmac\nrepos:\n  repo:\n    webhooks:\n    - url: https://ci.example.com/repo\n      secret:\n        file: /abs/hmac\n    - url: https://ci.example.com/env\n      secret:\n        env: HMAC\n")
+	write(filepath.Join(dir, "org", "team", "teams.yaml"), "teams:\n  team:\n    members_from:\n      file: team.csv\n    teams:\n      child:\n        members_from:\n          file: /abs/child.csv\n")
+	other := t.TempDir()
+	file := filepath.Join(other, "config", "config.yaml")
+	write(file, "orgs:\n  org:\n    members_from:\n      file: ../hr/org.csv\n    webhooks:\n    - url: https://ci.example.com/hook\n      secret:\n        file: hmac\n")
+
+	cfg, err := LoadPath(dir, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	orgConfig := cfg.Orgs["org"]
+	team := orgConfig.Teams["team"]
+	repoHooks := orgConfig.Repos["repo"].Webhooks
+	testCases := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{
+			name:     "org file",
+			actual:   orgConfig.MembersFrom.File,
+			expected: filepath.Join(dir, "org", "hr", "org.csv"),
+		},
+		{
+			name:     "team file",
+			actual:   team.MembersFrom.File,
+			expected: filepath.Join(dir, "org", "team", "team.csv"),
+		},
+		{
+			name:     "absolute file",
+			actual:   team.Children["child"].MembersFrom.File,
+			expected: "/abs/child.csv",
+		},
+		{
+			name:     "org webhook secret file",
+			actual:   orgConfig.Webhooks[0].Secret.File,
+			expected: filepath.Join(dir, "org", "secrets", "hmac"),
+		},
+		{
+			name:     "absolute repo webhook secret file",
+			actual:   repoHooks[0].Secret.File,
+			expected: "/abs/hmac",
+		},
+		{
+			name:     "repo webhook secret env",
+			actual:   repoHooks[1].Secret.File,
+			expected: "",
+		},
+	}
+	for _, tc := range testCases {
+		if tc.actual != tc.expected {
+			t.Errorf("%s: got %s, expected %s", tc.name, tc.actual, tc.expected)
+		}
+	}
+
+	if cfg, err = LoadPath(file, "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual, expected := cfg.Orgs["org"].MembersFrom.File, filepath.Join(other, "hr", "org.csv"); actual != expected {
+		t.Errorf("file %s, expected %s", actual, expected)
+	}
+	if actual, expected := cfg.Orgs["org"].Webhooks[0].Secret.File, filepath.Join(other, "config", "hmac"); actual != expected {
+		t.Errorf("secret file %s, expected %s", actual, expected)
+	}
+}
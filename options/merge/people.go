@@ -0,0 +1,67 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+)
+
+// UserLookup finds GitHub users by their ID.
+type UserLookup interface {
+	GetUserByID(id int) (*github.User, error)
+}
+
+// ResolvePeople replaces the person IDs referenced by the org configs with
+// the logins of the people file at path. When users is set, the current
+// login of each person with a user ID is looked up, so that a renamed
+// login is followed instead of being removed and invited again.
+func ResolvePeople(orgs map[string]config.Config, path string, users UserLookup) error {
+	people, err := config.LoadPeople(path)
+	if err != nil {
+		return err
+	}
+	if users != nil {
+		people = followRenames(people, users)
+	}
+	for name, cfg := range orgs {
+		people.Resolve(&cfg)
+		orgs[name] = cfg
+	}
+	return nil
+}
+
+// followRenames returns people with the current logins of their users.
+func followRenames(people config.People, users UserLookup) config.People {
+	current := make(config.People, len(people))
+	for person, account := range people {
+		if account.ID != 0 {
+			user, err := users.GetUserByID(account.ID)
+			switch {
+			case err != nil:
+				logrus.WithError(err).Warnf("Could not look up user %d of %s, using login %s", account.ID, person, account.Login)
+			case github.NormLogin(user.Login) != github.NormLogin(account.Login):
+				logrus.Warnf("User %d of %s renamed login %s to %s, update the people file", account.ID, person, account.Login, user.Login)
+				account.Login = user.Login
+			}
+		}
+		current[person] = account
+	}
+	return current
+}
@@ -0,0 +1,132 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+)
+
+type fakeUsers map[int]string
+
+func (f fakeUsers) GetUserByID(id int) (*github.User, error) {
+	login, ok := f[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &github.User{ID: id, Login: login}, nil
+}
+
+func TestResolvePeople(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.yaml")
+	content := "anne@example.com:\n  login: anne\n  id: 1\nbob@example.com:\n  login: bob\n  id: 2\nE1234:\n  login: carl\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	orgs := map[string]config.Config{
+		"org": {
+			Admins:  config.NewMembers("anne@example.com"),
+			Members: config.NewMembers("bob@example.com", "E1234", "dave"),
+		},
+	}
+	users := fakeUsers{1: "anne-renamed", 2: "Bob"}
+	if err := ResolvePeople(orgs, path, users); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]config.Config{
+		"org": {
+			Admins:  []config.Member{{Login: "anne-renamed", ID: 1}},
+			Members: []config.Member{{Login: "bob", ID: 2}, {Login: "carl"}, {Login: "dave"}},
+		},
+	}
+	if diff := cmp.Diff(expected, orgs); diff != "" {
+		t.Errorf("unexpected orgs (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadPathResolvesPeople(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, PeopleFileName), "anne@example.com:\n  login: anne\n")
+	write(filepath.Join(dir, "org", "org.yaml"), "admins: [anne@example.com]\n")
+	write(filepath.Join(dir, "org", "team", "teams.yaml"), "teams:\n  team:\n    members: [anne@example.com]\n")
+	other := filepath.Join(t.TempDir(), "people.yaml")
+	write(other, "anne@example.com:\n  login: anne-other\n")
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write(file, "orgs:\n  org:\n    admins: [anne@example.com]\n")
+
+	testCases := []struct {
+		name     string
+		path     string
+		people   string
+		expected string
+	}{
+		{
+			name:     "directory with its people file",
+			path:     dir,
+			expected: "anne",
+		},
+		{
+			name:     "directory with another people file",
+			path:     dir,
+			people:   other,
+			expected: "anne-other",
+		},
+		{
+			name:     "config file with a people file",
+			path:     file,
+			people:   other,
+			expected: "anne-other",
+		},
+		{
+			name:     "config file without a people file",
+			path:     file,
+			expected: "anne@example.com",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := LoadPath(tc.path, tc.people, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			orgConfig := cfg.Orgs["org"]
+			if actual := orgConfig.Admins[0].Login; actual != tc.expected {
+				t.Errorf("admin %s, expected %s", actual, tc.expected)
+			}
+			if team, ok := orgConfig.Teams["team"]; ok && team.Members[0].Login != tc.expected {
+				t.Errorf("team member %s, expected %s", team.Members[0].Login, tc.expected)
+			}
+		})
+	}
+}
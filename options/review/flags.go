@@ -0,0 +1,45 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package review
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// AddFlags adds this options' flags to the cobra command.
+func (o *Options) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&o.Format,
+		"format",
+		o.Format,
+		"Format of the export, csv or json",
+	)
+
+	cmd.Flags().StringVar(
+		&o.Output,
+		"output",
+		"",
+		"File the export is written to, instead of stdout",
+	)
+
+	cmd.Flags().BoolVar(
+		&o.Live,
+		"live",
+		false,
+		"Compare the config with the orgs on GitHub, adding the access only found there",
+	)
+}
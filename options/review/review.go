@@ -0,0 +1,437 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package review exports the access of the users of the orgs for access
+// reviews, along with the config file declaring it and when it last
+// changed.
+package review
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/blame"
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/options/merge"
+)
+
+// Formats of the export.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// States of a row compared with the live state of the org.
+const (
+	StateSynced = "synced"
+	StateConfig = "config-only"
+	StateLive   = "live-only"
+)
+
+type Options struct {
+	// Format is csv or json.
+	Format string
+	// Output is the file the export is written to, stdout when empty.
+	Output string
+	// Live compares the config with the live state of the orgs.
+	Live bool
+}
+
+func NewOptions() *Options {
+	return &Options{Format: FormatCSV}
+}
+
+// Validate validates review options.
+func (o *Options) Validate() error {
+	if o.Format != FormatCSV && o.Format != FormatJSON {
+		return fmt.Errorf("--format=%s must be %s or %s", o.Format, FormatCSV, FormatJSON)
+	}
+	return nil
+}
+
+// Row is an access of a user: its org role, and a team membership or a
+// permission on a repo, granted by the team or, when Team is empty, by the
+// org role or as a repo collaborator.
+type Row struct {
+	Org        string `json:"org"`
+	User       string `json:"user"`
+	OrgRole    string `json:"org_role,omitempty"`
+	Team       string `json:"team,omitempty"`
+	Repo       string `json:"repo,omitempty"`
+	Permission string `json:"permission,omitempty"`
+	// Source is the config file declaring the access, and LastChange the
+	// day it last changed in git, empty when unknown.
+	Source     string `json:"source,omitempty"`
+	LastChange string `json:"last_change,omitempty"`
+	// State compares the access with the live state of the org, when
+	// requested.
+	State string `json:"state,omitempty"`
+
+	// level is the permission level the permission amounts to.
+	level github.RepoPermissionLevel
+}
+
+var header = []string{"org", "user", "org_role", "team", "repo", "permission", "source", "last_change", "state"}
+
+func (r Row) record() []string {
+	return []string{r.Org, r.User, r.OrgRole, r.Team, r.Repo, r.Permission, r.Source, r.LastChange, r.State}
+}
+
+// key identifies the access of a row, regardless of where it is declared.
+// Custom repository roles are compared by the permission level they amount
+// to, as the live state only knows the latter.
+type key struct {
+	org, user, orgRole, team, repo string
+	level                          github.RepoPermissionLevel
+}
+
+func (r Row) key() key {
+	return key{org: r.Org, user: r.User, orgRole: r.OrgRole, team: r.Team, repo: r.Repo, level: r.level}
+}
+
+// Write writes the rows in the format.
+func Write(out io.Writer, format string, rows []Row) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	w := csv.NewWriter(out)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := w.Write(r.record()); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Run writes the export of the rows of the config, compared with the rows
+// of the live state of the orgs with Live.
+func (o *Options) Run(rows, live []Row) error {
+	if o.Live {
+		rows = Compare(rows, live)
+	}
+	if o.Output == "" {
+		return Write(os.Stdout, o.Format, rows)
+	}
+	f, err := os.Create(o.Output)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", o.Output, err)
+	}
+	if err := Write(f, o.Format, rows); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s: %w", o.Output, err)
+	}
+	return f.Close()
+}
+
+// Origin returns the config file declaring the first of keys found in the
+// config of the org, and the last time any of the keys found changed. Keys
+// are paths of YAML keys and list values relative to the org config, such
+// as teams, sig-foo, members, alice.
+type Origin func(orgName string, keys ...[]string) (string, time.Time)
+
+// Rows returns the rows of the access of the users of the org at now: a
+// row per permission on a repo, per team membership without one, and per
+// org member without either. When origin is set, the rows carry the config
+// file declaring them and the day it last changed.
+func Rows(orgName string, cfg config.Config, now time.Time, origin Origin) []Row {
+	effective := cfg.Effective(now)
+	roles := map[string]string{}
+	for _, login := range effective.Members {
+		roles[github.NormLogin(login)] = "member"
+	}
+	for _, login := range effective.Admins {
+		roles[github.NormLogin(login)] = "admin"
+	}
+
+	var rows []Row
+	add := func(r Row, keys ...[]string) {
+		if origin != nil {
+			r.Source, r.LastChange = locate(origin, orgName, keys)
+		}
+		rows = append(rows, r)
+	}
+
+	covered := map[[2]string]bool{}
+	for _, g := range cfg.Access(now) {
+		r := Row{Org: orgName, User: g.User, OrgRole: roles[g.User], Team: g.Team, Repo: g.Repo, Permission: string(g.Permission), level: g.Level}
+		covered[[2]string{g.User, g.Team}] = true
+		covered[[2]string{g.User, ""}] = true
+		switch {
+		case g.OrgRole != "" && g.Team != "":
+			add(r, append(memberKeys(strings.Split(g.Team, "/"), g.User), []string{"org_roles", g.OrgRole})...)
+		case g.OrgRole == "admin":
+			add(r, []string{"admins", g.User})
+		case g.OrgRole != "":
+			add(r, []string{"members", g.User}, []string{"default_repository_permission"})
+		case g.Collaborator:
+			add(r, []string{"repos", g.Repo, "collaborators", g.User})
+		default:
+			path := strings.Split(g.Team, "/")
+			declaring := path
+			if g.Inherited != "" {
+				for i, name := range path {
+					if name == g.Inherited {
+						declaring = path[:i+1]
+					}
+				}
+			}
+			add(r, append(memberKeys(path, g.User), append(teamKeys(declaring), "repos", g.Repo))...)
+		}
+	}
+
+	var walk func(teams map[string]org.Team, path []string)
+	walk = func(teams map[string]org.Team, path []string) {
+		for name, team := range teams {
+			teamPath := append(append([]string(nil), path...), name)
+			for _, login := range append(append([]string(nil), team.Maintainers...), team.Members...) {
+				login = github.NormLogin(login)
+				if covered[[2]string{login, strings.Join(teamPath, "/")}] {
+					continue
+				}
+				covered[[2]string{login, ""}] = true
+				add(Row{Org: orgName, User: login, OrgRole: roles[login], Team: strings.Join(teamPath, "/")}, memberKeys(teamPath, login)...)
+			}
+			walk(team.Children, teamPath)
+		}
+	}
+	walk(effective.Teams, nil)
+
+	for login, role := range roles {
+		if !covered[[2]string{login, ""}] {
+			add(Row{Org: orgName, User: login, OrgRole: role}, []string{role + "s", login})
+		}
+	}
+
+	sortRows(rows)
+	return rows
+}
+
+// teamKeys returns the keys of the team at path, such as teams, parent,
+// teams, child.
+func teamKeys(path []string) []string {
+	var keys []string
+	for _, name := range path {
+		keys = append(keys, "teams", name)
+	}
+	return keys
+}
+
+// memberKeys returns the keys declaring login a maintainer or member of
+// the team at path.
+func memberKeys(path []string, login string) [][]string {
+	team := teamKeys(path)
+	return [][]string{
+		append(append([]string(nil), team...), "maintainers", login),
+		append(append([]string(nil), team...), "members", login),
+	}
+}
+
+func locate(origin Origin, orgName string, keys [][]string) (string, string) {
+	source, changed := origin(orgName, keys...)
+	if changed.IsZero() {
+		return source, ""
+	}
+	return source, changed.Format(time.DateOnly)
+}
+
+func sortRows(rows []Row) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case a.Org != b.Org:
+			return a.Org < b.Org
+		case a.User != b.User:
+			return a.User < b.User
+		case a.Repo != b.Repo:
+			return a.Repo < b.Repo
+		}
+		return a.Team < b.Team
+	})
+}
+
+// Compare returns the rows of the config and of the live state of the
+// orgs, with their state: synced when the access is both declared and
+// live, config-only or live-only otherwise.
+func Compare(declared, live []Row) []Row {
+	seen := map[key]bool{}
+	for _, r := range live {
+		seen[r.key()] = true
+	}
+	found := map[key]bool{}
+	var out []Row
+	for _, r := range declared {
+		r.State = StateConfig
+		if seen[r.key()] {
+			r.State = StateSynced
+			found[r.key()] = true
+		}
+		out = append(out, r)
+	}
+	for _, r := range live {
+		if !found[r.key()] {
+			r.State = StateLive
+			out = append(out, r)
+		}
+	}
+	sortRows(out)
+	return out
+}
+
+// NewOrigin returns the Origin of the config at path, a config file or a
+// directory of org directories whose teams may be merged from teams.yaml
+// files. The last changes are read with git blame, and are unknown when
+// the config is not tracked by git.
+func NewOrigin(path string) (Origin, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve file info for %s: %w", path, err)
+	}
+
+	type file struct {
+		lines   map[string]int
+		changed []time.Time
+	}
+	files := map[string]*file{}
+	read := func(name string) *file {
+		if f, ok := files[name]; ok {
+			return f
+		}
+		f := &file{}
+		files[name] = f
+		raw, err := os.ReadFile(name)
+		if err != nil {
+			logrus.WithError(err).Warnf("Could not read %s", name)
+			return f
+		}
+		f.lines = index(string(raw))
+		if f.changed, err = blame.File(name); err != nil {
+			logrus.WithError(err).Warnf("Could not read the last changes of %s", name)
+		}
+		return f
+	}
+	teamSources := map[string]map[string]string{}
+	sourceOf := func(orgName string, keys []string) (string, []string) {
+		if !info.IsDir() {
+			return path, append([]string{"orgs", orgName}, keys...)
+		}
+		orgFile := filepath.Join(path, orgName, "org.yaml")
+		if len(keys) < 2 || keys[0] != "teams" {
+			return orgFile, keys
+		}
+		sources, ok := teamSources[orgName]
+		if !ok {
+			var err error
+			if sources, err = merge.TeamSources(orgFile); err != nil {
+				logrus.WithError(err).Warnf("Could not find the team files of %s", orgName)
+			}
+			teamSources[orgName] = sources
+		}
+		if source, ok := sources[keys[1]]; ok {
+			return source, keys
+		}
+		return orgFile, keys
+	}
+
+	return func(orgName string, keys ...[]string) (string, time.Time) {
+		var source string
+		var changed []time.Time
+		for _, k := range keys {
+			name, k := sourceOf(orgName, k)
+			if source == "" {
+				source = name
+			}
+			f := read(name)
+			if line, ok := f.lines[strings.ToLower(strings.Join(k, "\x00"))]; ok && line <= len(f.changed) {
+				changed = append(changed, f.changed[line-1])
+			}
+		}
+		return source, blame.Last(changed...)
+	}, nil
+}
+
+// index returns the line declaring each path of the YAML content: the map
+// keys, the list values and the login or name of list items. Paths are
+// lowercased and their keys joined by NUL.
+func index(content string) map[string]int {
+	lines := map[string]int{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		logrus.WithError(err).Warn("Could not index the config")
+		return lines
+	}
+	var walk func(n *yaml.Node, path []string)
+	walk = func(n *yaml.Node, path []string) {
+		add := func(key string, line int) []string {
+			p := append(append([]string{}, path...), key)
+			lines[strings.ToLower(strings.Join(p, "\x00"))] = line
+			return p
+		}
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k := n.Content[i]
+				walk(n.Content[i+1], add(k.Value, k.Line))
+			}
+		case yaml.SequenceNode:
+			for _, item := range n.Content {
+				switch item.Kind {
+				case yaml.ScalarNode:
+					add(item.Value, item.Line)
+				case yaml.MappingNode:
+					if name := itemName(item); name != "" {
+						walk(item, add(name, item.Line))
+					}
+				}
+			}
+		}
+	}
+	walk(&doc, nil)
+	return lines
+}
+
+// itemName returns the login or name of a list item.
+func itemName(item *yaml.Node) string {
+	for _, field := range []string{"login", "name"} {
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == field {
+				return item.Content[i+1].Value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package review
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+const raw = `default_repository_permission: read
+admins:
+- owner
+members:
+- alice  # on call
+- login: bob
+  expires: 2026-12-31
+- carol
+teams:
+  parent:
+    maintainers:
+    - alice
+    repos:
+      widget: write
+    teams:
+      child:
+        members: [bob]
+  idle:
+    members:
+    - carol
+`
+
+func TestIndex(t *testing.T) {
+	lines := index(raw)
+	testCases := map[string]int{
+		"default_repository_permission":                       1,
+		"admins\x00owner":                                     3,
+		"members\x00alice":                                    5,
+		"members\x00bob":                                      6,
+		"teams\x00parent\x00maintainers\x00alice":             12,
+		"teams\x00parent\x00repos\x00widget":                  14,
+		"teams\x00parent\x00teams\x00child\x00members\x00bob": 17,
+		"teams\x00idle\x00members\x00carol":                   20,
+	}
+	for path, expected := range testCases {
+		if actual := lines[path]; actual != expected {
+			t.Errorf("%q: got line %d, want %d", path, actual, expected)
+		}
+	}
+	if _, ok := lines["members\x00bob\x00expires"]; !ok {
+		t.Errorf("failed to index the keys of list items")
+	}
+}
+
+func loadConfig(t *testing.T) config.Config {
+	t.Helper()
+	var cfg config.Config
+	if err := yaml.Unmarshal([]byte(raw), &cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestRows(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	lines := index(raw)
+	origin := func(orgName string, keys ...[]string) (string, time.Time) {
+		var changed []time.Time
+		for _, k := range keys {
+			// Each line changed on the day of the month of its number.
+			if line, ok := lines[strings.Join(k, "\x00")]; ok {
+				changed = append(changed, time.Date(2026, time.January, line, 0, 0, 0, 0, time.UTC))
+			}
+		}
+		var last time.Time
+		for _, c := range changed {
+			if c.After(last) {
+				last = c
+			}
+		}
+		return orgName + "/org.yaml", last
+	}
+	expected := []Row{
+		{Org: "foo", User: "alice", OrgRole: "member", Repo: "widget", Permission: "read", Source: "foo/org.yaml", LastChange: "2026-01-05", level: "read"},
+		{Org: "foo", User: "alice", OrgRole: "member", Team: "parent", Repo: "widget", Permission: "write", Source: "foo/org.yaml", LastChange: "2026-01-14", level: "write"},
+		{Org: "foo", User: "bob", OrgRole: "member", Repo: "widget", Permission: "read", Source: "foo/org.yaml", LastChange: "2026-01-06", level: "read"},
+		{Org: "foo", User: "bob", OrgRole: "member", Team: "parent/child", Repo: "widget", Permission: "write", Source: "foo/org.yaml", LastChange: "2026-01-17", level: "write"},
+		{Org: "foo", User: "carol", OrgRole: "member", Team: "idle", Source: "foo/org.yaml", LastChange: "2026-01-20"},
+		{Org: "foo", User: "carol", OrgRole: "member", Repo: "widget", Permission: "read", Source: "foo/org.yaml", LastChange: "2026-01-08", level: "read"},
+		{Org: "foo", User: "owner", OrgRole: "admin", Repo: "widget", Permission: "admin", Source: "foo/org.yaml", LastChange: "2026-01-03", level: "admin"},
+	}
+	actual := Rows("foo", loadConfig(t), now, origin)
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(Row{})); diff != "" {
+		t.Errorf("unexpected rows (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	declared := loadConfig(t)
+	live := loadConfig(t)
+	live.Teams["idle"] = config.Team{}
+	live.Admins = append(live.Admins, config.NewMembers("intruder")...)
+
+	var actual []string
+	for _, r := range Compare(Rows("foo", declared, now, nil), Rows("foo", live, now, nil)) {
+		if r.State != StateSynced {
+			actual = append(actual, r.User+" "+r.Team+" "+r.State)
+		}
+	}
+	expected := []string{
+		"carol idle " + StateConfig,
+		"intruder  " + StateLive,
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected states (-want +got):\n%s", diff)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rows := []Row{
+		{Org: "foo", User: "alice", OrgRole: "member", Team: "parent", Repo: "widget", Permission: "write", Source: "foo/org.yaml", LastChange: "2026-01-14"},
+	}
+	var csv bytes.Buffer
+	if err := Write(&csv, FormatCSV, rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "org,user,org_role,team,repo,permission,source,last_change,state\n" +
+		"foo,alice,member,parent,widget,write,foo/org.yaml,2026-01-14,\n"
+	if diff := cmp.Diff(expected, csv.String()); diff != "" {
+		t.Errorf("unexpected csv (-want +got):\n%s", diff)
+	}
+
+	var json bytes.Buffer
+	if err := Write(&json, FormatJSON, rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(json.String(), `"last_change": "2026-01-14"`) || strings.Contains(json.String(), "state") {
+		t.Errorf("unexpected json: %s", json.String())
+	}
+}
@@ -19,6 +19,7 @@ package root
 import (
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -29,11 +30,16 @@ const (
 	// Flags.
 
 	// Configuration settings.
-	flagConfigPath = "config-path"
-	flagConfirm    = "confirm"
-	flagDump       = "dump"
-	flagDumpFull   = "dump-full"
-	flagLogLevel   = "log-level"
+	flagConfigPath  = "config-path"
+	flagConfirm     = "confirm"
+	flagDump        = "dump"
+	flagDumpFull    = "dump-full"
+	flagLogLevel    = "log-level"
+	flagSnapshotDir = "snapshot-dir"
+	flagJournal     = "journal"
+	flagConfigSHA   = "config-sha"
+	flagPolicy      = "policy"
+	flagPeople      = "people"
 
 	// Protections.
 	flagMaxRemovalDelta = "maximum-removal-delta"
@@ -41,12 +47,24 @@ const (
 	flagRequireSelf     = "require-self"
 	flagRequiredAdmins  = "required-admins"
 
+	flagOverrideProtected     = "override-protected"
+	flagMaxAdminDemotions     = "max-admin-demotions"
+	flagConfirmAdminDemotions = "confirm-admin-demotions"
+
+	flagRequireApproval = "require-approval"
+	flagApprovedChanges = "approved-changes"
+
 	// Organization settings.
 	flagFixOrg         = "fix-org"
 	flagIgnoreInvitees = "ignore-invitees"
 
 	// Members settings.
 	flagFixOrgMembers = "fix-org-members"
+	flagTwoFactor     = "two-factor"
+
+	flagLDAPURL          = "ldap-url"
+	flagLDAPBindDN       = "ldap-bind-dn"
+	flagLDAPPasswordPath = "ldap-password-path"
 
 	// Team settings.
 	flagFixTeams          = "fix-teams"
@@ -58,6 +76,15 @@ const (
 	flagFixRepos          = "fix-repos"
 	flagAllowRepoArchival = "allow-repo-archival"
 	flagAllowRepoPublish  = "allow-repo-publish"
+	flagAllowRepoDeletion = "allow-repo-deletion"
+	flagAllowRepoTransfer = "allow-repo-transfer"
+	flagUnmanagedRepos    = "unmanaged-repos"
+
+	flagUnmanagedReposAllowlist = "unmanaged-repos-allowlist"
+	flagFailOnUnmanagedRepos    = "fail-on-unmanaged-repos"
+
+	// Webhook settings.
+	flagFixWebhooks = "fix-webhooks"
 
 	// Prow GitHub settings.
 	// TODO(action): Missing input parameter
@@ -74,6 +101,8 @@ type Command interface {
 
 // AddFlags adds this options' flags to the cobra command.
 func (o *Options) AddFlags(cmd *cobra.Command) {
+	o.changed = cmd.Flags().Changed
+
 	cmd.Flags().StringSliceVar(
 		&o.RequiredAdmins,
 		flagRequiredAdmins,
@@ -95,6 +124,41 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		"Ensure github token path user is an admin",
 	)
 
+	cmd.Flags().BoolVar(
+		&o.OverrideProtected,
+		flagOverrideProtected,
+		false,
+		"Allow removing and demoting the users, teams and repos listed as protected in the config",
+	)
+
+	cmd.Flags().IntVar(
+		&o.MaxAdminDemotions,
+		flagMaxAdminDemotions,
+		defaultMaxAdminDemotions,
+		"Demote or remove at most this many admins per run without --confirm-admin-demotions",
+	)
+
+	cmd.Flags().BoolVar(
+		&o.ConfirmAdminDemotions,
+		flagConfirmAdminDemotions,
+		false,
+		"Allow demoting or removing more than --max-admin-demotions admins, or the authenticated user",
+	)
+
+	cmd.Flags().BoolVar(
+		&o.RequireApproval,
+		flagRequireApproval,
+		false,
+		"Skip high-risk changes unless their IDs are listed in --approved-changes or $PERIBOLOS_APPROVED_CHANGES",
+	)
+
+	cmd.Flags().StringVar(
+		&o.ApprovedChangesFile,
+		flagApprovedChanges,
+		"",
+		"Path to a file listing the IDs of the approved high-risk changes, one per line",
+	)
+
 	cmd.Flags().Float64Var(
 		&o.MaxDelta,
 		flagMaxRemovalDelta,
@@ -120,7 +184,7 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		&o.Dump,
 		flagDump,
 		"",
-		"Output current config of this org if set",
+		"Output current config of this org if set, leaving out the resources ignored by --policy and by the ignore key of the org in --config-path",
 	)
 
 	cmd.Flags().BoolVar(
@@ -130,6 +194,41 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		"Output current config of the org as a valid input config file instead of a snippet",
 	)
 
+	cmd.Flags().StringVar(
+		&o.SnapshotDir,
+		flagSnapshotDir,
+		"",
+		"Write a snapshot of each org to this directory before mutating it, for use with the rollback command",
+	)
+
+	cmd.Flags().StringVar(
+		&o.Policy,
+		flagPolicy,
+		"",
+		"Path to a policy file declaring the managed, read-only and ignored resources of each org, flags set explicitly take precedence",
+	)
+
+	cmd.Flags().StringVar(
+		&o.People,
+		flagPeople,
+		"",
+		"Path to a people.yaml file mapping the person IDs of the config to GitHub logins, defaults to people.yaml in the --config-path directory",
+	)
+
+	cmd.Flags().StringVar(
+		&o.Journal,
+		flagJournal,
+		"",
+		"Append every change applied to GitHub to this JSON Lines file",
+	)
+
+	cmd.Flags().StringVar(
+		&o.ConfigSHA,
+		flagConfigSHA,
+		os.Getenv("GITHUB_SHA"),
+		"Commit of the config recorded in the journal, defaults to $GITHUB_SHA",
+	)
+
 	cmd.Flags().BoolVar(
 		&o.IgnoreInvitees,
 		flagIgnoreInvitees,
@@ -158,6 +257,34 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		"Add/remove org members if set",
 	)
 
+	cmd.Flags().StringVar(
+		&o.TwoFactor,
+		flagTwoFactor,
+		TwoFactorIgnore,
+		fmt.Sprintf("What to do with org members without two-factor authentication, one of %v: freeze leaves the role of declared members unchanged, new users are invited regardless", twoFactorModes),
+	)
+
+	cmd.Flags().StringVar(
+		&o.LDAPURL,
+		flagLDAPURL,
+		"",
+		"URL of the LDAP server of the members_from sources, such as ldaps://ldap.example.com",
+	)
+
+	cmd.Flags().StringVar(
+		&o.LDAPBindDN,
+		flagLDAPBindDN,
+		"",
+		"DN to bind to the LDAP server as, the connection is anonymous if unset",
+	)
+
+	cmd.Flags().StringVar(
+		&o.LDAPPasswordPath,
+		flagLDAPPasswordPath,
+		"",
+		"Path to the file containing the password of --ldap-bind-dn",
+	)
+
 	cmd.Flags().BoolVar(
 		&o.FixTeams,
 		flagFixTeams,
@@ -200,6 +327,48 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 		"If set, making private repos public is allowed while updating repos",
 	)
 
+	cmd.Flags().BoolVar(
+		&o.AllowRepoDeletion,
+		flagAllowRepoDeletion,
+		false,
+		"If set, repos configured with delete: true are deleted while updating repos",
+	)
+
+	cmd.Flags().BoolVar(
+		&o.AllowRepoTransfer,
+		flagAllowRepoTransfer,
+		false,
+		"If set, repos configured with transfer_to are transferred while updating repos",
+	)
+
+	cmd.Flags().StringVar(
+		&o.UnmanagedRepos,
+		flagUnmanagedRepos,
+		UnmanagedReposIgnore,
+		fmt.Sprintf("What to do with repos missing from the config while updating repos, one of %v", unmanagedReposModes),
+	)
+
+	cmd.Flags().StringSliceVar(
+		&o.UnmanagedReposAllowlist,
+		flagUnmanagedReposAllowlist,
+		o.UnmanagedReposAllowlist,
+		"Glob patterns of repos that may exist without being declared in the config",
+	)
+
+	cmd.Flags().BoolVar(
+		&o.FailOnUnmanagedRepos,
+		flagFailOnUnmanagedRepos,
+		false,
+		"Fail if repos that are not allowlisted exist without being declared in the config",
+	)
+
+	cmd.Flags().BoolVar(
+		&o.FixWebhooks,
+		flagFixWebhooks,
+		false,
+		"Create/update/delete org and repo webhooks if set",
+	)
+
 	cmd.Flags().StringVar(
 		&o.logLevel,
 		flagLogLevel,
@@ -209,6 +378,7 @@ func (o *Options) AddFlags(cmd *cobra.Command) {
 
 	ghFlags := flag.NewFlagSet("github-flags", flag.ContinueOnError)
 	o.GithubOpts.AddCustomizedFlags(ghFlags, flagutil.ThrottlerDefaults(defaultTokens, defaultBurst))
+	o.githubFlags = ghFlags
 
 	cmd.Flags().AddGoFlagSet(ghFlags)
 }
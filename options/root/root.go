@@ -20,22 +20,50 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/caarlos0/env/v7"
 	actions "github.com/sethvargo/go-githubactions"
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/prow/pkg/flagutil"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/internal/journal"
 )
 
 const (
-	defaultMinAdmins = 5
-	defaultDelta     = 0.25
-	defaultTokens    = 300
-	defaultBurst     = 100
+	defaultMinAdmins         = 5
+	defaultMaxAdminDemotions = 1
+	defaultDelta             = 0.25
+	defaultTokens            = 300
+	defaultBurst             = 100
 )
 
+// Actions taken on repos that exist in the org but are missing from the config.
+const (
+	UnmanagedReposIgnore  = "ignore"
+	UnmanagedReposReport  = "report"
+	UnmanagedReposArchive = "archive"
+)
+
+var unmanagedReposModes = []string{UnmanagedReposIgnore, UnmanagedReposReport, UnmanagedReposArchive}
+
+// Actions taken on org members without two-factor authentication.
+const (
+	TwoFactorIgnore = "ignore"
+	TwoFactorReport = "report"
+	TwoFactorFreeze = "freeze"
+	TwoFactorFail   = "fail"
+)
+
+var twoFactorModes = []string{TwoFactorIgnore, TwoFactorReport, TwoFactorFreeze, TwoFactorFail}
+
 type Options struct {
 	// Configuration settings.
 
@@ -47,20 +75,45 @@ type Options struct {
 	Confirm      bool
 	Dump         string
 	DumpFull     bool
+	SnapshotDir  string
+	Journal      string
+	ConfigSHA    string
+	Policy       string
+	People       string
 	logLevel     string
 
+	// changed reports whether a flag was set explicitly.
+	changed func(flag string) bool
+
 	// Protections.
 	MaxDelta       float64
 	MinAdmins      int
 	RequireSelf    bool
 	RequiredAdmins []string
 
+	OverrideProtected bool
+
+	MaxAdminDemotions     int
+	ConfirmAdminDemotions bool
+
+	RequireApproval     bool
+	ApprovedChangesFile string
+	// ApprovedChanges are the IDs of the high-risk changes which may be
+	// applied with RequireApproval, see LoadApprovedChanges.
+	ApprovedChanges []string `env:"PERIBOLOS_APPROVED_CHANGES" envSeparator:","`
+
 	// Organization settings.
 	FixOrg         bool
 	IgnoreInvitees bool
 
 	// Members settings.
 	FixOrgMembers bool
+	TwoFactor     string
+
+	// LDAP settings of the member sources.
+	LDAPURL          string
+	LDAPBindDN       string
+	LDAPPasswordPath string
 
 	// Team settings.
 	FixTeams          bool
@@ -72,9 +125,34 @@ type Options struct {
 	FixRepos          bool
 	AllowRepoArchival bool
 	AllowRepoPublish  bool
+	AllowRepoDeletion bool
+	AllowRepoTransfer bool
+	UnmanagedRepos    string
+
+	UnmanagedReposAllowlist []string
+	FailOnUnmanagedRepos    bool
+
+	// Webhook settings.
+	FixWebhooks bool
+
+	// ReadOnly and Ignored are the teams and repos of the org being
+	// configured which are read-only or ignored, see ForOrg.
+	ReadOnly config.Patterns
+	Ignored  config.Patterns
+
+	// Locked are the IDs pinned in the lockfile for the org being
+	// configured.
+	Locked config.OrgLock
+
+	// Journaled are the last changes recorded in the --journal file, see
+	// journal.Latest.
+	Journaled map[journal.Key]journal.Entry
 
 	// Prow GitHub settings.
 	GithubOpts flagutil.GitHubOptions
+	// githubFlags are the flags of GithubOpts, holding the endpoints it does
+	// not export.
+	githubFlags *flag.FlagSet
 }
 
 func NewOptions() Options {
@@ -106,6 +184,10 @@ func (o *Options) validateArgsForAction() error {
 		return fmt.Errorf("--min-admins=%d must be at least 2", o.MinAdmins)
 	}
 
+	if o.MaxAdminDemotions < 0 {
+		return fmt.Errorf("--max-admin-demotions=%d must be non-negative", o.MaxAdminDemotions)
+	}
+
 	if o.MaxDelta > 1 || o.MaxDelta < 0 {
 		return fmt.Errorf("--maximum-removal-delta=%f must be a non-negative number less than 1.0", o.MaxDelta)
 	}
@@ -122,10 +204,6 @@ func (o *Options) validateArgsForAction() error {
 		return errors.New("--config-path or --dump required")
 	}
 
-	if o.Config != "" && o.Dump != "" {
-		return fmt.Errorf("--config-path=%s and --dump=%s cannot both be set", o.Config, o.Dump)
-	}
-
 	if o.DumpFull && o.Dump == "" {
 		return errors.New("--dump-full can't be used without --dump")
 	}
@@ -138,6 +216,20 @@ func (o *Options) validateArgsForAction() error {
 		return errors.New("--fix-team-repos requires --fix-teams")
 	}
 
+	if !slices.Contains(unmanagedReposModes, o.UnmanagedRepos) {
+		return fmt.Errorf("--unmanaged-repos=%s must be one of %v", o.UnmanagedRepos, unmanagedReposModes)
+	}
+
+	if !slices.Contains(twoFactorModes, o.TwoFactor) {
+		return fmt.Errorf("--two-factor=%s must be one of %v", o.TwoFactor, twoFactorModes)
+	}
+
+	for _, pattern := range o.UnmanagedReposAllowlist {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("--unmanaged-repos-allowlist contains an invalid pattern: %s", pattern)
+		}
+	}
+
 	level, err := logrus.ParseLevel(o.logLevel)
 	if err != nil {
 		return fmt.Errorf("--log-level invalid: %s", err.Error())
@@ -157,6 +249,15 @@ func (o *Options) ParseFromAction() error {
 	}
 
 	o.Dump = actions.GetInput(flagDump)
+	o.Policy = actions.GetInput(flagPolicy)
+	o.People = actions.GetInput(flagPeople)
+	o.changed = func(flag string) bool { return actions.GetInput(flag) != "" }
+	o.SnapshotDir = actions.GetInput(flagSnapshotDir)
+	o.Journal = actions.GetInput(flagJournal)
+	o.ConfigSHA = actions.GetInput(flagConfigSHA)
+	if o.ConfigSHA == "" {
+		o.ConfigSHA = os.Getenv("GITHUB_SHA")
+	}
 
 	dumpFull := actions.GetInput(flagDumpFull)
 	if dumpFull != "" {
@@ -187,6 +288,28 @@ func (o *Options) ParseFromAction() error {
 		o.RequireSelf, _ = strconv.ParseBool(requireSelf)
 	}
 
+	overrideProtected := actions.GetInput(flagOverrideProtected)
+	if overrideProtected != "" {
+		o.OverrideProtected, _ = strconv.ParseBool(overrideProtected)
+	}
+
+	o.MaxAdminDemotions = defaultMaxAdminDemotions
+	maxAdminDemotions := actions.GetInput(flagMaxAdminDemotions)
+	if maxAdminDemotions != "" {
+		o.MaxAdminDemotions, _ = strconv.Atoi(maxAdminDemotions)
+	}
+
+	confirmAdminDemotions := actions.GetInput(flagConfirmAdminDemotions)
+	if confirmAdminDemotions != "" {
+		o.ConfirmAdminDemotions, _ = strconv.ParseBool(confirmAdminDemotions)
+	}
+
+	requireApproval := actions.GetInput(flagRequireApproval)
+	if requireApproval != "" {
+		o.RequireApproval, _ = strconv.ParseBool(requireApproval)
+	}
+	o.ApprovedChangesFile = actions.GetInput(flagApprovedChanges)
+
 	requiredAdmins := actions.GetInput(flagRequiredAdmins)
 	if requiredAdmins != "" {
 		// TODO(options): Test this with unexpected inputs as well, including spaces between commas
@@ -210,6 +333,16 @@ func (o *Options) ParseFromAction() error {
 		o.FixOrgMembers, _ = strconv.ParseBool(fixOrgMembers)
 	}
 
+	o.TwoFactor = TwoFactorIgnore
+	twoFactor := actions.GetInput(flagTwoFactor)
+	if twoFactor != "" {
+		o.TwoFactor = twoFactor
+	}
+
+	o.LDAPURL = actions.GetInput(flagLDAPURL)
+	o.LDAPBindDN = actions.GetInput(flagLDAPBindDN)
+	o.LDAPPasswordPath = actions.GetInput(flagLDAPPasswordPath)
+
 	// Team settings.
 	fixTeams := actions.GetInput(flagFixTeams)
 	if fixTeams != "" {
@@ -247,9 +380,42 @@ func (o *Options) ParseFromAction() error {
 		o.AllowRepoPublish, _ = strconv.ParseBool(allowRepoPublish)
 	}
 
+	allowRepoDeletion := actions.GetInput(flagAllowRepoDeletion)
+	if allowRepoDeletion != "" {
+		o.AllowRepoDeletion, _ = strconv.ParseBool(allowRepoDeletion)
+	}
+
+	allowRepoTransfer := actions.GetInput(flagAllowRepoTransfer)
+	if allowRepoTransfer != "" {
+		o.AllowRepoTransfer, _ = strconv.ParseBool(allowRepoTransfer)
+	}
+
+	o.UnmanagedRepos = UnmanagedReposIgnore
+	unmanagedRepos := actions.GetInput(flagUnmanagedRepos)
+	if unmanagedRepos != "" {
+		o.UnmanagedRepos = unmanagedRepos
+	}
+
+	unmanagedReposAllowlist := actions.GetInput(flagUnmanagedReposAllowlist)
+	if unmanagedReposAllowlist != "" {
+		o.UnmanagedReposAllowlist = strings.Split(unmanagedReposAllowlist, ",")
+	}
+
+	failOnUnmanagedRepos := actions.GetInput(flagFailOnUnmanagedRepos)
+	if failOnUnmanagedRepos != "" {
+		o.FailOnUnmanagedRepos, _ = strconv.ParseBool(failOnUnmanagedRepos)
+	}
+
+	// Webhook settings.
+	fixWebhooks := actions.GetInput(flagFixWebhooks)
+	if fixWebhooks != "" {
+		o.FixWebhooks, _ = strconv.ParseBool(fixWebhooks)
+	}
+
 	// Prow GitHub settings.
 	ghFlags := flag.NewFlagSet("github-flags", flag.ContinueOnError)
 	o.GithubOpts.AddCustomizedFlags(ghFlags, flagutil.ThrottlerDefaults(defaultTokens, defaultBurst))
+	o.githubFlags = ghFlags
 
 	// TODO(flags): Consider parameterizing flag.
 	o.GithubOpts.TokenPath = actions.GetInput("github-token-path")
@@ -271,3 +437,67 @@ func (o *Options) ParseFromAction() error {
 
 	return o.validateArgsForAction()
 }
+
+// GitHubEndpoints returns the endpoints of --github-endpoint and
+// --github-graphql-endpoint.
+func (o *Options) GitHubEndpoints() ghclient.Endpoints {
+	if o.githubFlags == nil {
+		return ghclient.Endpoints{}
+	}
+	return ghclient.EndpointsFromFlags(o.githubFlags)
+}
+
+// LoadApprovedChanges adds the change IDs listed in ApprovedChangesFile to
+// ApprovedChanges. IDs are separated by whitespace or commas, and lines
+// starting with # are ignored.
+func (o *Options) LoadApprovedChanges() error {
+	if o.ApprovedChangesFile == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(o.ApprovedChangesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read --%s: %w", flagApprovedChanges, err)
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		o.ApprovedChanges = append(o.ApprovedChanges, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})...)
+	}
+	return nil
+}
+
+// ForOrg returns the options configuring orgName under the policy. The flags
+// set explicitly override the policy, and orgs without a policy are
+// configured by the flags alone.
+func (o Options) ForOrg(policy *config.Policy, orgName string) Options {
+	if policy == nil {
+		return o
+	}
+	op, ok := policy.For(orgName)
+	if !ok {
+		return o
+	}
+	out := o
+	set := func(v *bool, flag string, value bool) {
+		if o.changed == nil || !o.changed(flag) {
+			*v = value
+		}
+	}
+	set(&out.FixOrg, flagFixOrg, op.Manages(config.ResourceOrg))
+	set(&out.FixOrgMembers, flagFixOrgMembers, op.Manages(config.ResourceOrgMembers))
+	set(&out.FixTeams, flagFixTeams, op.Manages(config.ResourceTeams))
+	set(&out.FixTeamMembers, flagFixTeamMembers, op.Manages(config.ResourceTeamMembers))
+	set(&out.FixTeamRepos, flagFixTeamRepos, op.Manages(config.ResourceTeamRepos))
+	set(&out.FixRepos, flagFixRepos, op.Manages(config.ResourceRepos))
+	set(&out.FixWebhooks, flagFixWebhooks, op.Manages(config.ResourceWebhooks))
+	set(&out.AllowRepoArchival, flagAllowRepoArchival, op.Allows(config.AllowRepoArchival))
+	set(&out.AllowRepoPublish, flagAllowRepoPublish, op.Allows(config.AllowRepoPublish))
+	set(&out.AllowRepoDeletion, flagAllowRepoDeletion, op.Allows(config.AllowRepoDeletion))
+	set(&out.AllowRepoTransfer, flagAllowRepoTransfer, op.Allows(config.AllowRepoTransfer))
+	out.ReadOnly = op.ReadOnly
+	out.Ignored = op.Ignored
+	return out
+}
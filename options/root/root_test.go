@@ -85,10 +85,6 @@ func TestOptions(t *testing.T) {
 			name: "reject dump and confirm",
 			args: []string{"--confirm", "--dump=frogger"},
 		},
-		{
-			name: "reject dump and config-path",
-			args: []string{"--config-path=foo", "--dump=frogger"},
-		},
 		{
 			name: "reject --fix-team-members without --fix-teams",
 			args: []string{"--config-path=foo", "--fix-team-members"},
@@ -0,0 +1,421 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+)
+
+type orgActionsClient interface {
+	GetOrgActionsPermissions(org string) (*ghclient.OrgActionsPermissions, error)
+	EditOrgActionsPermissions(org string, perms ghclient.OrgActionsPermissions) error
+	ListOrgActionsRepos(org string) ([]ghclient.ActionsRepo, error)
+	SetOrgActionsRepos(org string, ids []int) error
+	GetRepoID(owner, repo string) (int, error)
+	GetOrgSelectedActions(org string) (*ghclient.SelectedActions, error)
+	EditOrgSelectedActions(org string, selected ghclient.SelectedActions) error
+	GetOrgWorkflowPermissions(org string) (*ghclient.WorkflowPermissions, error)
+	EditOrgWorkflowPermissions(org string, perms ghclient.WorkflowPermissions) error
+}
+
+type repoActionsClient interface {
+	GetRepoActionsPermissions(owner, repo string) (*ghclient.RepoActionsPermissions, error)
+	EditRepoActionsPermissions(owner, repo string, perms ghclient.RepoActionsPermissions) error
+	GetRepoSelectedActions(owner, repo string) (*ghclient.SelectedActions, error)
+	EditRepoSelectedActions(owner, repo string, selected ghclient.SelectedActions) error
+	GetRepoWorkflowPermissions(owner, repo string) (*ghclient.WorkflowPermissions, error)
+	EditRepoWorkflowPermissions(owner, repo string, perms ghclient.WorkflowPermissions) error
+}
+
+// configureOrgActions will update github to have the non-nil wanted Actions
+// permissions of the org.
+func configureOrgActions(client orgActionsClient, orgName string, want *config.ActionsPolicy) error {
+	if want == nil {
+		return nil
+	}
+	if err := validateActionsPolicy(want, true); err != nil {
+		return fmt.Errorf("invalid %s actions policy: %w", orgName, err)
+	}
+
+	cur, err := client.GetOrgActionsPermissions(orgName)
+	if err != nil {
+		return fmt.Errorf("failed to get %s actions permissions: %w", orgName, err)
+	}
+	before := *cur
+	wasSelectingRepos := cur.EnabledRepositories == config.ActionsSelected
+	wasSelectingActions := cur.AllowedActions == config.ActionsSelected
+	change := false
+	change = updateString(&cur.EnabledRepositories, want.EnabledRepositories) || change
+	change = updateString(&cur.AllowedActions, want.AllowedActions) || change
+	if change {
+		logrus.Infof("Updating %s actions permissions", orgName)
+		if err := client.EditOrgActionsPermissions(orgName, *cur); err != nil {
+			return fmt.Errorf("failed to edit %s actions permissions: %w", orgName, err)
+		}
+		record(client, orgName, "org.actions.edit", orgName, before, *cur)
+	}
+
+	var errs []error
+	if cur.EnabledRepositories == config.ActionsSelected && want.SelectedRepositories != nil {
+		if err := configureOrgActionsRepos(client, orgName, want.SelectedRepositories, wasSelectingRepos); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cur.AllowedActions == config.ActionsSelected && want.SelectedActions != nil {
+		err := configureSelectedActions(want.SelectedActions, wasSelectingActions,
+			func() (*ghclient.SelectedActions, error) { return client.GetOrgSelectedActions(orgName) },
+			func(selected ghclient.SelectedActions) error {
+				if err := client.EditOrgSelectedActions(orgName, selected); err != nil {
+					return err
+				}
+				record(client, orgName, "org.actions.selected.edit", orgName, nil, selected)
+				return nil
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to configure %s selected actions: %w", orgName, err))
+		}
+	}
+	err = configureWorkflowPermissions(want,
+		func() (*ghclient.WorkflowPermissions, error) { return client.GetOrgWorkflowPermissions(orgName) },
+		func(perms ghclient.WorkflowPermissions) error {
+			if err := client.EditOrgWorkflowPermissions(orgName, perms); err != nil {
+				return err
+			}
+			record(client, orgName, "org.actions.workflow.edit", orgName, nil, perms)
+			return nil
+		},
+	)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to configure %s workflow permissions: %w", orgName, err))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// configureOrgActionsRepos ensures exactly the wanted repos may run Actions.
+// The current selection is only listed if the org was already selecting repos.
+func configureOrgActionsRepos(client orgActionsClient, orgName string, want []string, listCurrent bool) error {
+	ids := map[string]int{}
+	if listCurrent {
+		repos, err := client.ListOrgActionsRepos(orgName)
+		if err != nil {
+			return fmt.Errorf("failed to list %s actions repos: %w", orgName, err)
+		}
+		for _, repo := range repos {
+			ids[strings.ToLower(repo.Name)] = repo.ID
+		}
+		have := sets.KeySet(ids)
+		if have.Equal(normalizeNames(want)) {
+			return nil
+		}
+	}
+
+	selected := make([]int, 0, len(want))
+	for _, name := range want {
+		id, ok := ids[strings.ToLower(name)]
+		if !ok {
+			var err error
+			if id, err = client.GetRepoID(orgName, name); err != nil {
+				return fmt.Errorf("failed to get %s/%s id: %w", orgName, name, err)
+			}
+		}
+		selected = append(selected, id)
+	}
+	sort.Ints(selected)
+	logrus.Infof("Allowing %d repos in %s to run actions", len(selected), orgName)
+	if err := client.SetOrgActionsRepos(orgName, selected); err != nil {
+		return fmt.Errorf("failed to set %s actions repos: %w", orgName, err)
+	}
+	record(client, orgName, "org.actions.repos.set", orgName, nil, want)
+	return nil
+}
+
+// configureRepoActions will update github to have the non-nil wanted Actions
+// permissions of the repo.
+func configureRepoActions(client repoActionsClient, orgName, repoName string, want *config.ActionsPolicy) error {
+	if want == nil {
+		return nil
+	}
+
+	cur, err := client.GetRepoActionsPermissions(orgName, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get actions permissions: %w", err)
+	}
+	before := *cur
+	wasSelectingActions := cur.AllowedActions == config.ActionsSelected
+	change := false
+	change = updateBool(&cur.Enabled, want.Enabled) || change
+	if cur.Enabled {
+		change = updateString(&cur.AllowedActions, want.AllowedActions) || change
+	} else {
+		cur.AllowedActions = ""
+	}
+	if change {
+		logrus.WithField("repo", repoName).Info("Updating actions permissions")
+		if err := client.EditRepoActionsPermissions(orgName, repoName, *cur); err != nil {
+			return fmt.Errorf("failed to edit actions permissions: %w", err)
+		}
+		record(client, orgName, "repo.actions.edit", repoName, before, *cur)
+	}
+	if !cur.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if cur.AllowedActions == config.ActionsSelected && want.SelectedActions != nil {
+		err := configureSelectedActions(want.SelectedActions, wasSelectingActions,
+			func() (*ghclient.SelectedActions, error) { return client.GetRepoSelectedActions(orgName, repoName) },
+			func(selected ghclient.SelectedActions) error {
+				if err := client.EditRepoSelectedActions(orgName, repoName, selected); err != nil {
+					return err
+				}
+				record(client, orgName, "repo.actions.selected.edit", repoName, nil, selected)
+				return nil
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to configure selected actions: %w", err))
+		}
+	}
+	err = configureWorkflowPermissions(want,
+		func() (*ghclient.WorkflowPermissions, error) {
+			return client.GetRepoWorkflowPermissions(orgName, repoName)
+		},
+		func(perms ghclient.WorkflowPermissions) error {
+			if err := client.EditRepoWorkflowPermissions(orgName, repoName, perms); err != nil {
+				return err
+			}
+			record(client, orgName, "repo.actions.workflow.edit", repoName, nil, perms)
+			return nil
+		},
+	)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to configure workflow permissions: %w", err))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// configureSelectedActions updates the allowed actions to the non-nil wanted
+// values. GitHub only returns them once selected actions are allowed, so
+// they are only read if that was already the case.
+func configureSelectedActions(want *config.SelectedActions, readCurrent bool, get func() (*ghclient.SelectedActions, error), edit func(ghclient.SelectedActions) error) error {
+	cur := &ghclient.SelectedActions{}
+	change := !readCurrent
+	if readCurrent {
+		var err error
+		if cur, err = get(); err != nil {
+			return err
+		}
+	}
+	change = updateBool(&cur.GithubOwnedAllowed, want.GithubOwnedAllowed) || change
+	change = updateBool(&cur.VerifiedAllowed, want.VerifiedAllowed) || change
+	if want.PatternsAllowed != nil && !sets.New(want.PatternsAllowed...).Equal(sets.New(cur.PatternsAllowed...)) {
+		cur.PatternsAllowed = want.PatternsAllowed
+		change = true
+	}
+	if !change {
+		return nil
+	}
+	if cur.PatternsAllowed == nil {
+		cur.PatternsAllowed = []string{}
+	}
+	return edit(*cur)
+}
+
+// configureWorkflowPermissions updates the default workflow permissions to
+// the non-nil wanted values.
+func configureWorkflowPermissions(want *config.ActionsPolicy, get func() (*ghclient.WorkflowPermissions, error), edit func(ghclient.WorkflowPermissions) error) error {
+	if want.DefaultWorkflowPermissions == nil && want.CanApprovePullRequestReviews == nil {
+		return nil
+	}
+	cur, err := get()
+	if err != nil {
+		return err
+	}
+	change := false
+	change = updateString(&cur.DefaultWorkflowPermissions, want.DefaultWorkflowPermissions) || change
+	change = updateBool(&cur.CanApprovePullRequestReviews, want.CanApprovePullRequestReviews) || change
+	if !change {
+		return nil
+	}
+	return edit(*cur)
+}
+
+// validateActionsPolicy ensures the policy only sets known values, and only
+// sets org settings on orgs and repo settings on repos.
+func validateActionsPolicy(policy *config.ActionsPolicy, isOrg bool) error {
+	if policy == nil {
+		return nil
+	}
+	oneOf := func(field string, value *string, allowed ...string) error {
+		if value != nil && !slices.Contains(allowed, *value) {
+			return fmt.Errorf("%s=%s must be one of %s", field, *value, strings.Join(allowed, ", "))
+		}
+		return nil
+	}
+	switch {
+	case isOrg && policy.Enabled != nil:
+		return fmt.Errorf("enabled only applies to repos, use enabled_repositories for orgs")
+	case !isOrg && (policy.EnabledRepositories != nil || policy.SelectedRepositories != nil):
+		return fmt.Errorf("enabled_repositories and selected_repositories only apply to orgs")
+	case policy.SelectedRepositories != nil && (policy.EnabledRepositories == nil || *policy.EnabledRepositories != config.ActionsSelected):
+		return fmt.Errorf("selected_repositories requires enabled_repositories=%s", config.ActionsSelected)
+	case policy.SelectedActions != nil && (policy.AllowedActions == nil || *policy.AllowedActions != config.ActionsSelected):
+		return fmt.Errorf("selected_actions requires allowed_actions=%s", config.ActionsSelected)
+	}
+	return utilerrors.NewAggregate([]error{
+		oneOf("enabled_repositories", policy.EnabledRepositories, config.ActionsAll, config.ActionsNone, config.ActionsSelected),
+		oneOf("allowed_actions", policy.AllowedActions, config.ActionsAll, config.ActionsLocalOnly, config.ActionsSelected),
+		oneOf("default_workflow_permissions", policy.DefaultWorkflowPermissions, config.WorkflowPermissionsRead, config.WorkflowPermissionsWrite),
+	})
+}
+
+// normalizeNames returns the lowercase names, as GitHub compares repo and
+// team names case-insensitively.
+func normalizeNames(names []string) sets.Set[string] {
+	out := sets.Set[string]{}
+	for _, name := range names {
+		out.Insert(strings.ToLower(name))
+	}
+	return out
+}
+
+type actionsDumpClient interface {
+	GetOrgActionsPermissions(org string) (*ghclient.OrgActionsPermissions, error)
+	ListOrgActionsRepos(org string) ([]ghclient.ActionsRepo, error)
+	GetOrgSelectedActions(org string) (*ghclient.SelectedActions, error)
+	GetOrgWorkflowPermissions(org string) (*ghclient.WorkflowPermissions, error)
+	GetRepoActionsPermissions(owner, repo string) (*ghclient.RepoActionsPermissions, error)
+	GetRepoSelectedActions(owner, repo string) (*ghclient.SelectedActions, error)
+	GetRepoWorkflowPermissions(owner, repo string) (*ghclient.WorkflowPermissions, error)
+}
+
+// dumpOrgActions returns the Actions policy of the org.
+func dumpOrgActions(client actionsDumpClient, orgName string) (*config.ActionsPolicy, error) {
+	perms, err := client.GetOrgActionsPermissions(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions permissions: %w", err)
+	}
+	policy := config.ActionsPolicy{EnabledRepositories: &perms.EnabledRepositories}
+	if perms.EnabledRepositories == config.ActionsSelected {
+		repos, err := client.ListOrgActionsRepos(orgName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list actions repos: %w", err)
+		}
+		policy.SelectedRepositories = make([]string, 0, len(repos))
+		for _, repo := range repos {
+			policy.SelectedRepositories = append(policy.SelectedRepositories, repo.Name)
+		}
+		sort.Strings(policy.SelectedRepositories)
+	}
+	if perms.EnabledRepositories == config.ActionsNone {
+		return &policy, nil
+	}
+	policy.AllowedActions = &perms.AllowedActions
+	if perms.AllowedActions == config.ActionsSelected {
+		selected, err := client.GetOrgSelectedActions(orgName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get selected actions: %w", err)
+		}
+		policy.SelectedActions = dumpSelectedActions(selected)
+	}
+	workflow, err := client.GetOrgWorkflowPermissions(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow permissions: %w", err)
+	}
+	policy.DefaultWorkflowPermissions = &workflow.DefaultWorkflowPermissions
+	policy.CanApprovePullRequestReviews = &workflow.CanApprovePullRequestReviews
+	return &policy, nil
+}
+
+// dumpRepoActions returns the settings of the repo Actions policy that
+// override the org policy, or nil if the repo inherits all of them.
+func dumpRepoActions(client actionsDumpClient, orgName, repoName string, orgPolicy *config.ActionsPolicy) (*config.ActionsPolicy, error) {
+	if orgPolicy.EnabledRepositories != nil && *orgPolicy.EnabledRepositories == config.ActionsNone {
+		return nil, nil
+	}
+	perms, err := client.GetRepoActionsPermissions(orgName, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions permissions: %w", err)
+	}
+	var policy config.ActionsPolicy
+	if !perms.Enabled {
+		if *orgPolicy.EnabledRepositories == config.ActionsAll {
+			policy.Enabled = &perms.Enabled
+			return &policy, nil
+		}
+		return nil, nil
+	}
+	if perms.AllowedActions != *orgPolicy.AllowedActions {
+		policy.AllowedActions = &perms.AllowedActions
+	}
+	if perms.AllowedActions == config.ActionsSelected {
+		selected, err := client.GetRepoSelectedActions(orgName, repoName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get selected actions: %w", err)
+		}
+		dumped := dumpSelectedActions(selected)
+		if policy.AllowedActions != nil || !selectedActionsEqual(dumped, orgPolicy.SelectedActions) {
+			policy.AllowedActions = &perms.AllowedActions
+			policy.SelectedActions = dumped
+		}
+	}
+	workflow, err := client.GetRepoWorkflowPermissions(orgName, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow permissions: %w", err)
+	}
+	if workflow.DefaultWorkflowPermissions != *orgPolicy.DefaultWorkflowPermissions {
+		policy.DefaultWorkflowPermissions = &workflow.DefaultWorkflowPermissions
+	}
+	if workflow.CanApprovePullRequestReviews != *orgPolicy.CanApprovePullRequestReviews {
+		policy.CanApprovePullRequestReviews = &workflow.CanApprovePullRequestReviews
+	}
+	if policy.AllowedActions == nil && policy.DefaultWorkflowPermissions == nil && policy.CanApprovePullRequestReviews == nil {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func dumpSelectedActions(selected *ghclient.SelectedActions) *config.SelectedActions {
+	return &config.SelectedActions{
+		GithubOwnedAllowed: &selected.GithubOwnedAllowed,
+		VerifiedAllowed:    &selected.VerifiedAllowed,
+		PatternsAllowed:    selected.PatternsAllowed,
+	}
+}
+
+func selectedActionsEqual(a, b *config.SelectedActions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	boolEqual := func(x, y *bool) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return boolEqual(a.GithubOwnedAllowed, b.GithubOwnedAllowed) &&
+		boolEqual(a.VerifiedAllowed, b.VerifiedAllowed) &&
+		sets.New(a.PatternsAllowed...).Equal(sets.New(b.PatternsAllowed...))
+}
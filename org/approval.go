@@ -0,0 +1,87 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+// Kinds of high-risk changes. With --require-approval they are only applied
+// once their ID is approved.
+const (
+	changeAdminGrant   = "org.admin.grant"
+	changeBulkRemoval  = "org.membership.bulk-remove"
+	changeTeamDelete   = "team.delete"
+	changeRoleDelete   = "org.repo_role.delete"
+	changeRepoArchive  = "repo.archive"
+	changeRepoPublish  = "repo.publish"
+	changeRepoDelete   = "repo.delete"
+	changeRepoTransfer = "repo.transfer"
+)
+
+// bulkRemovals is the number of org members removed by a run above which
+// each removal is a high-risk change.
+const bulkRemovals = 5
+
+// changeID identifies a change across runs, so that the IDs listed by a
+// dry-run can be approved for the next run.
+func changeID(kind, orgName, target string) string {
+	return kind + ":" + orgName + "/" + target
+}
+
+// approvals gates high-risk changes. The zero value approves every change.
+type approvals struct {
+	required bool
+	approved sets.Set[string]
+}
+
+func newApprovals(opt root.Options) approvals {
+	a := approvals{required: opt.RequireApproval, approved: sets.Set[string]{}}
+	for _, id := range opt.ApprovedChanges {
+		if id = strings.TrimSpace(id); id != "" {
+			a.approved.Insert(id)
+		}
+	}
+	return a
+}
+
+// allow reports whether the high-risk change may be applied. The change ID
+// is always logged, so that a plan lists the changes awaiting approval.
+func (a approvals) allow(kind, orgName, target string) bool {
+	id := changeID(kind, orgName, target)
+	switch {
+	case !a.required:
+		logrus.Infof("Applying high-risk change %s", id)
+		return true
+	case a.approved.Has(id):
+		logrus.Infof("Applying approved high-risk change %s", id)
+		return true
+	}
+	logrus.Warnf("Skipping high-risk change %s until it is approved (see --approved-changes)", id)
+	return false
+}
+
+// approves reports whether the high-risk change would be applied, without
+// logging it.
+func (a approvals) approves(kind, orgName, target string) bool {
+	return !a.required || a.approved.Has(changeID(kind, orgName, target))
+}
@@ -18,10 +18,15 @@ package org
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/prow/pkg/config/org"
 	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
 )
 
 type dumpClient interface {
@@ -33,10 +38,26 @@ type dumpClient interface {
 	GetRepo(owner, name string) (github.FullRepo, error)
 	GetRepos(org string, isUser bool) ([]github.Repo, error)
 	BotUser() (*github.UserData, error)
+	ListOrgHooks(org string) ([]github.Hook, error)
+	ListRepoHooks(org, repo string) ([]github.Hook, error)
+	ListRepoCollaborators(org, repo string) ([]ghclient.Collaborator, error)
+	GetOrgSettings(org string) (*ghclient.OrgSettings, error)
+	ListTeamIdPGroups(org, teamSlug string) ([]ghclient.IdPGroup, error)
+	GetTeamSettings(org, teamSlug string) (*ghclient.TeamSettings, error)
+	GetTeamReviewAssignment(org, teamSlug string) (*ghclient.ReviewAssignment, error)
+	ListOrgRoles(org string) ([]ghclient.OrgRole, error)
+	ListOrgRoleTeams(org string, id int) ([]github.Team, error)
+	actionsDumpClient
 }
 
-func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID string) (*org.Config, error) {
-	out := org.Config{}
+// Dump returns the config of the org, leaving out the ignored teams, repos
+// and team repo permissions.
+func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID string, ignored config.Patterns) (*config.Config, error) {
+	out := config.Config{}
+	scoped := scope{ignored: ignored}
+	if !ignored.Empty() {
+		out.Ignore = &ignored
+	}
 	meta, err := client.GetOrg(orgName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get org: %w", err)
@@ -53,6 +74,26 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 	out.Metadata.DefaultRepositoryPermission = &drp
 	out.Metadata.MembersCanCreateRepositories = &meta.MembersCanCreateRepositories
 
+	settings, err := client.GetOrgSettings(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get org settings: %w", err)
+	}
+	out.OrgSettings = config.OrgSettings{
+		Blog:                                     settings.Blog,
+		TwitterUsername:                          settings.TwitterUsername,
+		MembersCanCreatePublicRepositories:       settings.MembersCanCreatePublicRepositories,
+		MembersCanCreatePrivateRepositories:      settings.MembersCanCreatePrivateRepositories,
+		MembersCanCreateInternalRepositories:     settings.MembersCanCreateInternalRepositories,
+		MembersCanForkPrivateRepositories:        settings.MembersCanForkPrivateRepositories,
+		MembersCanCreatePages:                    settings.MembersCanCreatePages,
+		MembersCanCreatePublicPages:              settings.MembersCanCreatePublicPages,
+		MembersCanCreatePrivatePages:             settings.MembersCanCreatePrivatePages,
+		WebCommitSignoffRequired:                 settings.WebCommitSignoffRequired,
+		TwoFactorRequirementEnabled:              settings.TwoFactorRequirementEnabled,
+		DependencyGraphEnabledForNewRepositories: settings.DependencyGraphEnabledForNewRepositories,
+		SecretScanningEnabledForNewRepositories:  settings.SecretScanningEnabledForNewRepositories,
+	}
+
 	var runningAsAdmin bool
 	runningAs, err := client.BotUser()
 	if err != nil {
@@ -65,7 +106,7 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 	logrus.Debugf("Found %d admins", len(admins))
 	for _, m := range admins {
 		logrus.WithField("login", m.Login).Debug("Recording admin.")
-		out.Admins = append(out.Admins, m.Login)
+		out.Admins = append(out.Admins, config.Member{Login: m.Login})
 		if runningAs.Login == m.Login || appID != "" {
 			runningAsAdmin = true
 		}
@@ -82,7 +123,7 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 	logrus.Debugf("Found %d members", len(orgMembers))
 	for _, m := range orgMembers {
 		logrus.WithField("login", m.Login).Debug("Recording member.")
-		out.Members = append(out.Members, m.Login)
+		out.Members = append(out.Members, config.Member{Login: m.Login})
 	}
 
 	teams, err := client.ListTeams(orgName)
@@ -91,10 +132,14 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 	}
 	logrus.Debugf("Found %d teams", len(teams))
 
-	names := map[int]string{}   // what's the name of a team?
-	idMap := map[int]org.Team{} // metadata for a team
-	children := map[int][]int{} // what children does it have
-	var tops []int              // what are the top-level teams
+	names := map[int]string{}       // what's the name of a team?
+	idMap := map[int]org.Team{}     // metadata for a team
+	children := map[int][]int{}     // what children does it have
+	var tops []int                  // what are the top-level teams
+	linked := map[string][]string{} // what IdP groups is it synchronized with
+	listGroups := true
+	teamSettings := map[string]config.TeamSettings{} // what settings differ from the defaults
+	listAssignments := true
 
 	for _, t := range teams {
 		logger := logrus.WithFields(logrus.Fields{"id": t.ID, "name": t.Name})
@@ -103,6 +148,10 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 			logger.Debug("Ignoring secret team.")
 			continue
 		}
+		if scoped.ignoredTeam(t.Name) || scoped.ignoredTeam(t.Slug) {
+			logger.Debug("Ignoring team.")
+			continue
+		}
 		d := t.Description
 		nt := org.Team{
 			TeamMetadata: org.TeamMetadata{
@@ -134,6 +183,34 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 			nt.Members = append(nt.Members, m.Login)
 		}
 
+		if listGroups {
+			groups, err := client.ListTeamIdPGroups(orgName, t.Slug)
+			if err != nil {
+				// Team synchronization is only available to some orgs.
+				logrus.WithError(err).Debug("Not recording IdP groups.")
+				listGroups = false
+			}
+			for _, g := range groups {
+				linked[t.Name] = append(linked[t.Name], g.Name)
+			}
+		}
+
+		current, err := client.GetTeamSettings(orgName, t.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get team %d(%s) settings: %w", t.ID, t.Name, err)
+		}
+		var assignment *ghclient.ReviewAssignment
+		if listAssignments {
+			if assignment, err = client.GetTeamReviewAssignment(orgName, t.Slug); err != nil {
+				// The GraphQL API may not be available to the token.
+				logrus.WithError(err).Debug("Not recording review assignments.")
+				listAssignments = false
+			}
+		}
+		if ts := dumpTeamSettings(*current, assignment); ts != (config.TeamSettings{}) {
+			teamSettings[t.Name] = ts
+		}
+
 		names[t.ID] = t.Name
 		idMap[t.ID] = nt
 
@@ -151,6 +228,9 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 		}
 		logger.Debugf("Found %d repo permissions.", len(repos))
 		for _, repo := range repos {
+			if scoped.ignoredTeamRepo(t.Name, repo.Name) {
+				continue
+			}
 			level := github.LevelFromPermissions(repo.Permissions)
 			logger.WithFields(logrus.Fields{"repo": repo, "permission": level}).Debug("Recording repo permission.")
 			nt.Repos[repo.Name] = level
@@ -167,9 +247,17 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 		return t
 	}
 
-	out.Teams = make(map[string]org.Team, len(tops))
+	out.Teams = make(map[string]config.Team, len(tops))
 	for _, id := range tops {
-		out.Teams[names[id]] = makeChild(id)
+		out.Teams[names[id]] = config.NewTeam(makeChild(id))
+	}
+	linkTeams(out.Teams, linked)
+	setTeamSettings(out.Teams, teamSettings)
+	out.OrgRoles = dumpOrgRoles(client, orgName, names)
+
+	out.Actions, err = dumpOrgActions(client, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump org actions policy: %w", err)
 	}
 
 	repos, err := client.GetRepos(orgName, false)
@@ -177,14 +265,23 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 		return nil, fmt.Errorf("failed to list org repos: %w", err)
 	}
 	logrus.Debugf("Found %d repos", len(repos))
-	out.Repos = make(map[string]org.Repo, len(repos))
+	out.Repos = make(map[string]config.Repo, len(repos))
 	for _, repo := range repos {
+		if scoped.ignoredRepo(repo.Name) {
+			logrus.WithField("repo", repo.Name).Debug("Ignoring repo.")
+			continue
+		}
 		full, err := client.GetRepo(orgName, repo.Name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get repo: %w", err)
 		}
 		logrus.WithField("repo", full.FullName).Debug("Recording repo.")
-		out.Repos[full.Name] = org.PruneRepoDefaults(org.Repo{
+		hooks, err := client.ListRepoHooks(orgName, full.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repo %s webhooks: %w", full.Name, err)
+		}
+		logrus.WithField("repo", full.FullName).Debugf("Found %d webhooks.", len(hooks))
+		dumped := config.Repo{Repo: org.PruneRepoDefaults(org.Repo{
 			Description:      &full.Description,
 			HomePage:         &full.Homepage,
 			Private:          &full.Private,
@@ -196,8 +293,107 @@ func Dump(client dumpClient, orgName string, ignoreSecretTeams bool, appID strin
 			AllowRebaseMerge: &full.AllowRebaseMerge,
 			Archived:         &full.Archived,
 			DefaultBranch:    &full.DefaultBranch,
-		})
+		})}
+		if len(hooks) > 0 {
+			dumped.Webhooks = dumpHooks(hooks)
+		}
+		collaborators, err := client.ListRepoCollaborators(orgName, full.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repo %s collaborators: %w", full.Name, err)
+		}
+		if len(collaborators) > 0 {
+			dumped.Collaborators = make(map[string]github.RepoPermissionLevel, len(collaborators))
+			for _, c := range collaborators {
+				dumped.Collaborators[c.Login] = github.RepoPermissionLevel(c.RoleName)
+			}
+		}
+		if dumped.Actions, err = dumpRepoActions(client, orgName, full.Name, out.Actions); err != nil {
+			return nil, fmt.Errorf("failed to dump repo %s actions policy: %w", full.Name, err)
+		}
+		out.Repos[full.Name] = dumped
+	}
+
+	hooks, err := client.ListOrgHooks(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list org webhooks: %w", err)
+	}
+	logrus.Debugf("Found %d webhooks", len(hooks))
+	if len(hooks) > 0 {
+		out.Webhooks = dumpHooks(hooks)
 	}
 
 	return &out, nil
 }
+
+// linkTeams records the IdP groups of the synchronized teams, whose
+// members are then left out.
+func linkTeams(teams map[string]config.Team, linked map[string][]string) {
+	for name, team := range teams {
+		linkTeams(team.Children, linked)
+		if groups, ok := linked[name]; ok {
+			team.IdPGroups = groups
+			team.Members, team.Maintainers = nil, nil
+		}
+		teams[name] = team
+	}
+}
+
+// dumpTeamSettings returns the team settings which differ from the
+// defaults of GitHub. Excluded members cannot be dumped.
+func dumpTeamSettings(settings ghclient.TeamSettings, assignment *ghclient.ReviewAssignment) config.TeamSettings {
+	var out config.TeamSettings
+	if n := settings.NotificationSetting; n != nil && *n != "notifications_enabled" {
+		out.NotificationSetting = n
+	}
+	if p := settings.Permission; p != nil && *p != "pull" {
+		out.Permission = p
+	}
+	if assignment != nil && assignment.Enabled {
+		out.ReviewAssignment = &config.ReviewAssignment{
+			Algorithm:   strings.ToLower(assignment.Algorithm),
+			MemberCount: assignment.MemberCount,
+			NotifyTeam:  assignment.NotifyTeam,
+		}
+	}
+	return out
+}
+
+// setTeamSettings sets the settings of teams and their children by name.
+func setTeamSettings(teams map[string]config.Team, settings map[string]config.TeamSettings) {
+	for name, team := range teams {
+		setTeamSettings(team.Children, settings)
+		team.TeamSettings = settings[name]
+		teams[name] = team
+	}
+}
+
+// dumpOrgRoles returns the recorded teams assigned each organization role,
+// leaving out the roles without any. Nothing is recorded when the
+// organization roles are not available to the token.
+func dumpOrgRoles(client dumpClient, orgName string, names map[int]string) map[string][]string {
+	roles, err := client.ListOrgRoles(orgName)
+	if err != nil {
+		logrus.WithError(err).Debug("Not recording org roles.")
+		return nil
+	}
+	var out map[string][]string
+	for _, role := range roles {
+		teams, err := client.ListOrgRoleTeams(orgName, role.ID)
+		if err != nil {
+			logrus.WithError(err).Debug("Not recording org roles.")
+			return nil
+		}
+		for _, t := range teams {
+			name, ok := names[t.ID]
+			if !ok {
+				continue // ignored or secret team
+			}
+			if out == nil {
+				out = map[string][]string{}
+			}
+			out[role.Name] = append(out[role.Name], name)
+		}
+		sort.Strings(out[role.Name])
+	}
+	return out
+}
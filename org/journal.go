@@ -0,0 +1,42 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+// recorder is implemented by clients journaling the changes applied
+// through them, see ghclient.Client.Record.
+type recorder interface {
+	Record(org, change, target string, before, after interface{})
+}
+
+// record journals a successful change when client supports it.
+func record(client interface{}, orgName, change, target string, before, after interface{}) {
+	if r, ok := client.(recorder); ok {
+		r.Record(orgName, change, target, before, after)
+	}
+}
+
+// roleOf returns the role of user in have, or an empty string when the
+// user has none.
+func roleOf(have memberships, user, superRole string) string {
+	switch {
+	case have.super.Has(user):
+		return superRole
+	case have.members.Has(user):
+		return "member"
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+)
+
+type lockClient interface {
+	ListOrgMemberUsers(org string) ([]github.User, error)
+	ListTeams(org string) ([]github.Team, error)
+	ListOrgRepoIDs(org string) ([]ghclient.RepoID, error)
+}
+
+// Lock resolves the IDs of the users, teams and repos declared in cfg.
+// IDs pinned in previous are kept as long as they still exist in the org,
+// so that renamed users, teams and repos keep their ID. Users declared
+// with an ID keep it, other users are resolved among the org members.
+func Lock(client lockClient, orgName string, cfg config.Config, previous config.OrgLock) (config.OrgLock, error) {
+	var lock config.OrgLock
+
+	users, err := client.ListOrgMemberUsers(orgName)
+	if err != nil {
+		return lock, fmt.Errorf("failed to list %s members: %w", orgName, err)
+	}
+	userIDs := map[int]bool{}
+	byLogin := map[string]int{}
+	for _, u := range users {
+		userIDs[u.ID] = true
+		byLogin[github.NormLogin(u.Login)] = u.ID
+	}
+	for login, m := range cfg.Logins() {
+		id := m.ID
+		if id == 0 {
+			if prev, ok := previous.Users[login]; ok && userIDs[prev] {
+				id = prev
+			} else {
+				id = byLogin[login]
+			}
+		}
+		if id == 0 {
+			continue
+		}
+		if lock.Users == nil {
+			lock.Users = map[string]int{}
+		}
+		lock.Users[login] = id
+	}
+
+	teamList, err := client.ListTeams(orgName)
+	if err != nil {
+		return lock, fmt.Errorf("failed to list %s teams: %w", orgName, err)
+	}
+	teams := map[string]github.Team{}
+	for _, t := range teamList {
+		teams[t.Slug] = t
+	}
+	matcher := newTeamMatcher(teams, previous.Teams)
+	var walk func(teams map[string]config.Team)
+	walk = func(teams map[string]config.Team) {
+		for name, team := range teams {
+			walk(team.Children)
+			t := matcher.match(name, team.Previously...)
+			if t == nil {
+				continue
+			}
+			if lock.Teams == nil {
+				lock.Teams = map[string]int{}
+			}
+			lock.Teams[name] = t.ID
+		}
+	}
+	walk(cfg.Teams)
+
+	repoIDs, err := client.ListOrgRepoIDs(orgName)
+	if err != nil {
+		return lock, fmt.Errorf("failed to list %s repos: %w", orgName, err)
+	}
+	byID := map[int]string{}
+	for _, r := range repoIDs {
+		byID[r.ID] = r.Name
+	}
+	for name, repo := range cfg.Repos {
+		id, ok := previous.Repos[name]
+		if _, exists := byID[id]; !ok || !exists {
+			id = findRepoID(repoIDs, append([]string{name}, repo.Previously...))
+		}
+		if id == 0 {
+			continue
+		}
+		if lock.Repos == nil {
+			lock.Repos = map[string]int{}
+		}
+		lock.Repos[name] = id
+	}
+	return lock, nil
+}
+
+// findRepoID returns the ID of the repo of the first of names found in
+// repos, ignoring case like GitHub does.
+func findRepoID(repos []ghclient.RepoID, names []string) int {
+	for _, name := range names {
+		for _, r := range repos {
+			if strings.EqualFold(r.Name, name) {
+				return r.ID
+			}
+		}
+	}
+	return 0
+}
@@ -26,6 +26,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/uwu-tools/peribolos/internal/config"
 	"github.com/uwu-tools/peribolos/options/root"
 )
 
@@ -58,7 +59,10 @@ type orgClient interface {
 	UpdateOrgMembership(org, user string, admin bool) (*github.OrgMembership, error)
 }
 
-func configureOrgMembers(opt root.Options, client orgClient, orgName string, orgConfig org.Config, invitees sets.Set[string]) error {
+// configureOrgMembers invites, updates and removes org members. The roles
+// of frozen members are not updated, protected users are not removed nor
+// demoted. Admin grants and bulk removals are high-risk changes.
+func configureOrgMembers(opt root.Options, client orgClient, orgName string, orgConfig org.Config, invitees, frozen sets.Set[string], protected protection, sourced origins) error {
 	// Get desired state
 	wantAdmins := sets.New[string](orgConfig.Admins...)
 	wantMembers := sets.New[string](orgConfig.Members...)
@@ -112,6 +116,11 @@ func configureOrgMembers(opt root.Options, client orgClient, orgName string, org
 	if d := float64(len(remove)) / float64(len(have.all())); d > opt.MaxDelta {
 		return fmt.Errorf("cannot delete %d memberships or %.3f of %s (exceeds limit of %.3f)", len(remove), d, orgName, opt.MaxDelta)
 	}
+	approved := newApprovals(opt)
+	if err := checkAdminDemotions(opt, client, orgName, have, want, frozen, protected, approved); err != nil {
+		return err
+	}
+	bulk := len(remove) > bulkRemovals
 
 	teamMembers := sets.Set[string]{}
 	teamNames := sets.Set[string]{}
@@ -149,6 +158,17 @@ func configureOrgMembers(opt root.Options, client orgClient, orgName string, org
 		if super {
 			role = github.RoleAdmin
 		}
+		if frozen.Has(user) {
+			logrus.Warnf("Not setting %s as a %s of %s, two-factor authentication is disabled", user, role, orgName)
+			return nil
+		}
+		if !super && have.super.Has(user) && protected.user(user) {
+			logrus.Warnf("Not demoting protected admin %s of %s", user, orgName)
+			return nil
+		}
+		if super && !have.super.Has(user) && !approved.allow(changeAdminGrant, orgName, user) {
+			return nil
+		}
 		om, err := client.UpdateOrgMembership(orgName, user, super)
 		if err != nil {
 			logrus.WithError(err).Warnf("UpdateOrgMembership(%s, %s, %t) failed", orgName, user, super)
@@ -158,17 +178,28 @@ func configureOrgMembers(opt root.Options, client orgClient, orgName string, org
 				err = nil
 			}
 		} else if om.State == github.StatePending {
-			logrus.Infof("Invited %s to %s as a %s", user, orgName, role)
+			logrus.Infof("Invited %s to %s as a %s%s", user, orgName, role, sourced.from(user))
+			record(client, orgName, "org.membership.invite", user, roleOf(have, user, github.RoleAdmin), role)
 		} else {
-			logrus.Infof("Set %s as a %s of %s", user, role, orgName)
+			logrus.Infof("Set %s as a %s of %s%s", user, role, orgName, sourced.from(user))
+			record(client, orgName, "org.membership.set", user, roleOf(have, user, github.RoleAdmin), role)
 		}
 		return err
 	}
 
 	remover := func(user string) error {
+		if protected.user(user) {
+			logrus.Warnf("Not removing protected user %s from %s", user, orgName)
+			return nil
+		}
+		if bulk && !approved.allow(changeBulkRemoval, orgName, user) {
+			return nil
+		}
 		err := client.RemoveOrgMembership(orgName, user)
 		if err != nil {
 			logrus.WithError(err).Warnf("RemoveOrgMembership(%s, %s) failed", orgName, user)
+		} else {
+			record(client, orgName, "org.membership.remove", user, roleOf(have, user, github.RoleAdmin), nil)
 		}
 		return err
 	}
@@ -176,6 +207,78 @@ func configureOrgMembers(opt root.Options, client orgClient, orgName string, org
 	return configureMembers(have, want, invitees, adder, remover)
 }
 
+// origins are the sources of the members resolved from a member source, by
+// normalized login.
+type origins map[string]string
+
+// memberOrigins returns the origins of the org members and of the members
+// of each team.
+func memberOrigins(cfg config.Config) (origins, map[string]origins) {
+	orgSources, teamSources := cfg.MemberSources()
+	teams := make(map[string]origins, len(teamSources))
+	for name, sources := range teamSources {
+		teams[name] = newOrigins(sources)
+	}
+	return newOrigins(orgSources), teams
+}
+
+func newOrigins(sources map[string]string) origins {
+	o := make(origins, len(sources))
+	for login, source := range sources {
+		o[github.NormLogin(login)] = source
+	}
+	return o
+}
+
+// from describes the source of the member in logs, if any.
+func (o origins) from(user string) string {
+	if source, ok := o[user]; ok {
+		return fmt.Sprintf(" (from %s)", source)
+	}
+	return ""
+}
+
+// checkAdminDemotions refuses to demote or remove more than opt.MaxAdminDemotions
+// admins, or the authenticated user, unless opt.ConfirmAdminDemotions is set.
+// It also ensures the org keeps opt.MinAdmins admins once the changes are
+// applied: invited admins only count after accepting their invitation, and
+// promotions awaiting approval do not count.
+func checkAdminDemotions(opt root.Options, client orgClient, orgName string, have, want memberships, frozen sets.Set[string], protected protection, approved approvals) error {
+	demoted := sets.Set[string]{}
+	for user := range have.super.Difference(want.super) {
+		if !protected.user(user) {
+			demoted.Insert(user)
+		}
+	}
+
+	if n := len(demoted); n > 0 && !opt.ConfirmAdminDemotions {
+		if n > opt.MaxAdminDemotions {
+			return fmt.Errorf("cannot demote or remove %d admins of %s (exceeds limit of %d without --confirm-admin-demotions): %s", n, orgName, opt.MaxAdminDemotions, strings.Join(sets.List(demoted), ", "))
+		}
+		me, err := client.BotUser()
+		if err != nil {
+			return fmt.Errorf("cannot determine user making requests for %s: %v", opt.GithubOpts.TokenPath, err)
+		}
+		if demoted.Has(github.NormLogin(me.Login)) {
+			return fmt.Errorf("cannot demote or remove authenticated user %s from the admins of %s without --confirm-admin-demotions", me.Login, orgName)
+		}
+	}
+
+	// Admins after the sync are the kept and protected admins, plus the
+	// approved members promoted in place.
+	promoted := sets.Set[string]{}
+	for user := range want.super.Intersection(have.members).Difference(normalize(frozen)) {
+		if approved.approves(changeAdminGrant, orgName, user) {
+			promoted.Insert(user)
+		}
+	}
+	result := have.super.Difference(demoted).Union(promoted)
+	if n := len(result); n < opt.MinAdmins {
+		return fmt.Errorf("%s would be left with %d admins, at least %d are required (pending invitations do not count): %s", orgName, n, opt.MinAdmins, strings.Join(sets.List(result), ", "))
+	}
+	return nil
+}
+
 type memberships struct {
 	members sets.Set[string]
 	super   sets.Set[string]
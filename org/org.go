@@ -18,20 +18,81 @@ package org
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/config/org"
 	"sigs.k8s.io/prow/pkg/github"
-	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
 	"github.com/uwu-tools/peribolos/options/root"
 )
 
-func Configure(opt root.Options, client github.Client, orgName string, orgConfig org.Config) error {
+// IgnoredBy returns the ignored patterns of the policy along with those of
+// the ignore key of orgConfig, which are neither configured nor dumped.
+func IgnoredBy(ignored config.Patterns, orgConfig config.Config) (config.Patterns, error) {
+	if orgConfig.Ignore == nil {
+		return ignored, nil
+	}
+	if err := orgConfig.Ignore.Validate(); err != nil {
+		return ignored, err
+	}
+	return ignored.Merge(orgConfig.Ignore), nil
+}
+
+func Configure(opt root.Options, client *ghclient.Client, orgName string, orgConfig config.Config) error {
+	// Leave out the ignored teams and repos, as if they were not declared.
+	ignored, err := IgnoredBy(opt.Ignored, orgConfig)
+	if err != nil {
+		return fmt.Errorf("invalid %s ignore patterns: %w", orgName, err)
+	}
+	opt.Ignored = ignored
+	scoped := newScope(opt)
+	orgConfig = scoped.filter(orgConfig)
+
+	// Follow the logins renamed since they were declared, rather than
+	// removing the old login and inviting a user who no longer exists.
+	orgConfig.PinUserIDs(opt.Locked.Users)
+	renames, err := detectRenames(client, orgName, orgConfig)
+	if err != nil {
+		return fmt.Errorf("failed to detect renamed %s logins: %w", orgName, err)
+	}
+	orgConfig.Rename(renames)
+
+	// Leave out the memberships and repo permissions which expired, the
+	// sync then removes them like any other undeclared access.
+	now := time.Now()
+	for _, e := range orgConfig.Expiries() {
+		if e.Expires.Passed(now) {
+			logrus.Infof("Revoking expired access in %s: %s", orgName, e)
+		}
+	}
+	if err := orgConfig.ValidateRepoRoles(); err != nil {
+		return fmt.Errorf("invalid %s repo roles: %w", orgName, err)
+	}
+	linked, err := orgConfig.LinkedTeams()
+	if err != nil {
+		return fmt.Errorf("invalid %s teams: %w", orgName, err)
+	}
+	synced := teamSync{linked: linked, available: idpGroupsByName(client, orgName)}
+	teamSettings, err := orgConfig.TeamSettings()
+	if err != nil {
+		return fmt.Errorf("invalid %s team settings: %w", orgName, err)
+	}
+	orgSourced, teamSourced := memberOrigins(orgConfig)
+	orgConfig.Config = orgConfig.Effective(now)
+	protected := newProtection(opt, orgName, orgConfig.Protected)
+
 	// Ensure that metadata is configured correctly.
 	if !opt.FixOrg {
 		logrus.Infof("Skipping org metadata configuration")
-	} else if err := configureOrgMeta(client, orgName, orgConfig.Metadata); err != nil {
+	} else if err := configureOrgMeta(client, orgName, orgConfig.Metadata, orgConfig.OrgSettings); err != nil {
+		return err
+	} else if err := configureOrgActions(client, orgName, orgConfig.Actions); err != nil {
+		return err
+	} else if err := configureCustomRepoRoles(opt, client, orgName, orgConfig.CustomRepoRoles, protected); err != nil {
 		return err
 	}
 
@@ -40,18 +101,33 @@ func Configure(opt root.Options, client github.Client, orgName string, orgConfig
 		return fmt.Errorf("failed to list %s invitations: %w", orgName, err)
 	}
 
+	// Report or block members without two-factor authentication.
+	without2FA, err := checkTwoFactor(opt, client, orgName, orgConfig.Config)
+	if err != nil {
+		return err
+	}
+
 	// Invite/remove/update members to the org.
 	if !opt.FixOrgMembers {
 		logrus.Infof("Skipping org member configuration")
-	} else if err := configureOrgMembers(opt, client, orgName, orgConfig, invitees); err != nil {
+	} else if err := configureOrgMembers(opt, client, orgName, orgConfig.Config, invitees, without2FA, protected, orgSourced); err != nil {
 		return fmt.Errorf("failed to configure %s members: %w", orgName, err)
 	}
 
 	// Create repositories in the org
 	if !opt.FixRepos {
 		logrus.Info("Skipping org repositories configuration")
-	} else if err := configureRepos(opt, client, orgName, orgConfig); err != nil {
+	} else if err := configureRepos(opt, client, orgName, orgConfig, protected); err != nil {
 		return fmt.Errorf("failed to configure %s repos: %w", orgName, err)
+	} else if err := configureCollaborators(opt, client, orgName, orgConfig, protected); err != nil {
+		return fmt.Errorf("failed to configure %s repo collaborators: %w", orgName, err)
+	}
+
+	// Create/update/delete org and repository webhooks
+	if !opt.FixWebhooks {
+		logrus.Info("Skipping org and repo webhooks configuration")
+	} else if err := configureWebhooks(opt, client, orgName, orgConfig); err != nil {
+		return fmt.Errorf("failed to configure %s webhooks: %w", orgName, err)
 	}
 
 	if !opt.FixTeams {
@@ -60,13 +136,20 @@ func Configure(opt root.Options, client github.Client, orgName string, orgConfig
 	}
 
 	// Find the id and current state of each declared team (create/delete as necessary)
-	githubTeams, err := configureTeams(client, orgName, orgConfig, opt.MaxDelta, opt.IgnoreSecretTeams)
+	githubTeams, err := configureTeams(client, orgName, orgConfig.Config, opt.MaxDelta, opt.IgnoreSecretTeams, protected, newApprovals(opt), scoped, opt.Locked.Teams)
 	if err != nil {
 		return fmt.Errorf("failed to configure %s teams: %w", orgName, err)
 	}
 
-	for name, team := range orgConfig.Teams {
-		err := configureTeamAndMembers(opt, client, githubTeams, name, orgName, team, nil)
+	var customRoles map[string]github.RepoPermissionLevel
+	if opt.FixTeamRepos {
+		if customRoles, err = customRepoRoles(client, orgName, orgConfig); err != nil {
+			return err
+		}
+	}
+
+	for name, team := range orgConfig.Config.Teams {
+		err := configureTeamAndMembers(opt, client, githubTeams, name, orgName, team, nil, protected, synced, teamSourced, teamSettings)
 		if err != nil {
 			return fmt.Errorf("failed to configure %s teams: %w", orgName, err)
 		}
@@ -75,24 +158,31 @@ func Configure(opt root.Options, client github.Client, orgName string, orgConfig
 			logrus.Infof("Skipping team repo permissions configuration")
 			continue
 		}
-		if err := configureTeamRepos(opt, client, githubTeams, name, orgName, team); err != nil {
+		if err := configureTeamRepos(opt, client, githubTeams, name, orgName, team, protected, customRoles); err != nil {
 			return fmt.Errorf("failed to configure %s team %s repos: %w", orgName, name, err)
 		}
 	}
+
+	if err := configureOrgRoles(opt, client, orgName, orgConfig.OrgRoles, githubTeams); err != nil {
+		return fmt.Errorf("failed to configure %s org roles: %w", orgName, err)
+	}
 	return nil
 }
 
 type orgMetadataClient interface {
 	GetOrg(name string) (*github.Organization, error)
 	EditOrg(name string, org github.Organization) (*github.Organization, error)
+	GetOrgSettings(org string) (*ghclient.OrgSettings, error)
+	EditOrgSettings(org string, settings ghclient.OrgSettings) error
 }
 
 // configureOrgMeta will update github to have the non-nil wanted metadata values.
-func configureOrgMeta(client orgMetadataClient, orgName string, want org.Metadata) error {
+func configureOrgMeta(client orgMetadataClient, orgName string, want org.Metadata, settings config.OrgSettings) error {
 	cur, err := client.GetOrg(orgName)
 	if err != nil {
 		return fmt.Errorf("failed to get %s metadata: %w", orgName, err)
 	}
+	before := *cur
 	change := false
 	change = updateString(&cur.BillingEmail, want.BillingEmail) || change
 	change = updateString(&cur.Company, want.Company) || change
@@ -111,7 +201,59 @@ func configureOrgMeta(client orgMetadataClient, orgName string, want org.Metadat
 		if _, err := client.EditOrg(orgName, *cur); err != nil {
 			return fmt.Errorf("failed to edit %s metadata: %w", orgName, err)
 		}
+		record(client, orgName, "org.metadata.edit", orgName, before, *cur)
+	}
+	return configureOrgSettings(client, orgName, settings)
+}
+
+// configureOrgSettings will update github to have the non-nil wanted settings
+// that github.Organization does not expose.
+func configureOrgSettings(client orgMetadataClient, orgName string, want config.OrgSettings) error {
+	if want == (config.OrgSettings{}) {
+		return nil
+	}
+	cur, err := client.GetOrgSettings(orgName)
+	if err != nil {
+		return fmt.Errorf("failed to get %s settings: %w", orgName, err)
+	}
+	setString := func(current, want *string) *string {
+		if want != nil && (current == nil || *want != *current) {
+			return want
+		}
+		return nil
+	}
+	setBool := func(current, want *bool) *bool {
+		if want != nil && (current == nil || *want != *current) {
+			return want
+		}
+		return nil
+	}
+
+	if want := want.TwoFactorRequirementEnabled; want != nil && (cur.TwoFactorRequirementEnabled == nil || *want != *cur.TwoFactorRequirementEnabled) {
+		logrus.Warnf("%s two-factor authentication requirement is not %t as configured, it can only be changed in the GitHub UI", orgName, *want)
+	}
+
+	delta := ghclient.OrgSettings{
+		Blog:                                     setString(cur.Blog, want.Blog),
+		TwitterUsername:                          setString(cur.TwitterUsername, want.TwitterUsername),
+		MembersCanCreatePublicRepositories:       setBool(cur.MembersCanCreatePublicRepositories, want.MembersCanCreatePublicRepositories),
+		MembersCanCreatePrivateRepositories:      setBool(cur.MembersCanCreatePrivateRepositories, want.MembersCanCreatePrivateRepositories),
+		MembersCanCreateInternalRepositories:     setBool(cur.MembersCanCreateInternalRepositories, want.MembersCanCreateInternalRepositories),
+		MembersCanForkPrivateRepositories:        setBool(cur.MembersCanForkPrivateRepositories, want.MembersCanForkPrivateRepositories),
+		MembersCanCreatePages:                    setBool(cur.MembersCanCreatePages, want.MembersCanCreatePages),
+		MembersCanCreatePublicPages:              setBool(cur.MembersCanCreatePublicPages, want.MembersCanCreatePublicPages),
+		MembersCanCreatePrivatePages:             setBool(cur.MembersCanCreatePrivatePages, want.MembersCanCreatePrivatePages),
+		WebCommitSignoffRequired:                 setBool(cur.WebCommitSignoffRequired, want.WebCommitSignoffRequired),
+		DependencyGraphEnabledForNewRepositories: setBool(cur.DependencyGraphEnabledForNewRepositories, want.DependencyGraphEnabledForNewRepositories),
+		SecretScanningEnabledForNewRepositories:  setBool(cur.SecretScanningEnabledForNewRepositories, want.SecretScanningEnabledForNewRepositories),
+	}
+	if delta == (ghclient.OrgSettings{}) {
+		return nil
+	}
+	if err := client.EditOrgSettings(orgName, delta); err != nil {
+		return fmt.Errorf("failed to edit %s settings: %w", orgName, err)
 	}
+	record(client, orgName, "org.settings.edit", orgName, cur, delta)
 	return nil
 }
 
@@ -19,15 +19,21 @@ package org
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"sigs.k8s.io/prow/pkg/config/org"
 	"sigs.k8s.io/prow/pkg/github"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/internal/journal"
 	"github.com/uwu-tools/peribolos/options/root"
 )
 
@@ -348,6 +354,8 @@ func TestConfigureOrgMembers(t *testing.T) {
 		admins      []string
 		members     []string
 		invitations []string
+		frozen      []string
+		protected   []string
 		err         bool
 		remove      []string
 		addAdmins   []string
@@ -395,12 +403,75 @@ func TestConfigureOrgMembers(t *testing.T) {
 			name:   "can remove self with flag",
 			config: org.Config{},
 			opt: root.Options{
-				MaxDelta:    1,
-				RequireSelf: false,
+				MaxDelta:              1,
+				RequireSelf:           false,
+				ConfirmAdminDemotions: true,
 			},
 			admins: []string{"me"},
 			remove: []string{"me"},
 		},
+		{
+			name: "refuse to remove self without confirmation",
+			config: org.Config{
+				Admins: []string{"other"},
+			},
+			opt: root.Options{
+				MaxDelta:          1,
+				MaxAdminDemotions: 1,
+			},
+			admins: []string{"me", "other"},
+			err:    true,
+		},
+		{
+			name: "refuse to demote too many admins",
+			config: org.Config{
+				Admins:  []string{"keep"},
+				Members: []string{"a", "b"},
+			},
+			opt: root.Options{
+				MaxAdminDemotions: 1,
+			},
+			admins: []string{"a", "b", "keep"},
+			err:    true,
+		},
+		{
+			name: "demote many admins with confirmation",
+			config: org.Config{
+				Admins:  []string{"keep"},
+				Members: []string{"a", "b"},
+			},
+			opt: root.Options{
+				MaxAdminDemotions:     1,
+				ConfirmAdminDemotions: true,
+			},
+			admins:     []string{"a", "b", "keep"},
+			addMembers: []string{"a", "b"},
+		},
+		{
+			name: "invited admins do not count towards min admins",
+			config: org.Config{
+				Admins: []string{"new-admin", "other-new-admin"},
+			},
+			opt: root.Options{
+				MaxDelta:          1,
+				MinAdmins:         2,
+				MaxAdminDemotions: 1,
+			},
+			admins: []string{"old-admin"},
+			err:    true,
+		},
+		{
+			name: "promoted members count towards min admins",
+			config: org.Config{
+				Admins: []string{"admin", "member"},
+			},
+			opt: root.Options{
+				MinAdmins: 2,
+			},
+			admins:    []string{"admin"},
+			members:   []string{"member"},
+			addAdmins: []string{"member"},
+		},
 		{
 			name: "reject same person with both roles",
 			config: org.Config{
@@ -500,7 +571,8 @@ func TestConfigureOrgMembers(t *testing.T) {
 				Members: []string{"keep-member", "new-member"},
 			},
 			opt: root.Options{
-				MaxDelta: 0.5,
+				MaxDelta:          0.5,
+				MaxAdminDemotions: 1,
 			},
 			admins:     []string{"keep-admin", "drop-admin"},
 			members:    []string{"keep-member", "drop-member"},
@@ -516,6 +588,138 @@ func TestConfigureOrgMembers(t *testing.T) {
 			},
 			invitations: []string{"invited-admin", "invited-member"},
 		},
+		{
+			name: "do not promote frozen members, but invite new users",
+			config: org.Config{
+				Admins:  []string{"frozen-admin", "new-admin"},
+				Members: []string{"frozen-member", "new-member"},
+			},
+			members:    []string{"frozen-admin", "frozen-member"},
+			frozen:     []string{"frozen-admin", "frozen-member"},
+			addAdmins:  []string{"new-admin"},
+			addMembers: []string{"new-member"},
+		},
+		{
+			name: "do not remove nor demote protected users",
+			config: org.Config{
+				Admins:  []string{"keep-admin"},
+				Members: []string{"break-glass"},
+			},
+			opt: root.Options{
+				MaxDelta: 1,
+			},
+			admins:    []string{"keep-admin", "break-glass"},
+			members:   []string{"Robot", "drop-member"},
+			protected: []string{"break-glass", "robot"},
+			remove:    []string{"drop-member"},
+		},
+		{
+			name: "remove and demote protected users when protections are overridden",
+			config: org.Config{
+				Admins:  []string{"keep-admin"},
+				Members: []string{"break-glass"},
+			},
+			opt: root.Options{
+				MaxDelta:              1,
+				ConfirmAdminDemotions: true,
+				OverrideProtected:     true,
+			},
+			admins:     []string{"keep-admin", "break-glass"},
+			members:    []string{"robot", "drop-member"},
+			protected:  []string{"break-glass", "robot"},
+			remove:     []string{"drop-member", "robot"},
+			addMembers: []string{"break-glass"},
+		},
+		{
+			name: "overridden protections still need approval for bulk removals",
+			config: org.Config{
+				Admins: []string{"keep"},
+			},
+			opt: root.Options{
+				MaxDelta:          1,
+				OverrideProtected: true,
+				RequireApproval:   true,
+				ApprovedChanges:   []string{changeID(changeBulkRemoval, fakeOrg, "b")},
+			},
+			admins:    []string{"keep"},
+			members:   []string{"a", "b", "c", "d", "e", "f"},
+			protected: []string{"a", "b"},
+			remove:    []string{"b"},
+		},
+		{
+			name: "unapproved admin grants are skipped",
+			config: org.Config{
+				Admins:  []string{"keep", "promote", "approved", "grant"},
+				Members: []string{"new-member"},
+			},
+			opt: root.Options{
+				RequireApproval: true,
+				ApprovedChanges: []string{changeID(changeAdminGrant, fakeOrg, "approved")},
+			},
+			admins:     []string{"keep"},
+			members:    []string{"promote"},
+			addAdmins:  []string{"approved"},
+			addMembers: []string{"new-member"},
+		},
+		{
+			name: "unapproved promotions do not count towards min admins",
+			config: org.Config{
+				Admins:  []string{"member"},
+				Members: []string{"old-admin"},
+			},
+			opt: root.Options{
+				MinAdmins:             1,
+				ConfirmAdminDemotions: true,
+				RequireApproval:       true,
+			},
+			admins:  []string{"old-admin"},
+			members: []string{"member"},
+			err:     true,
+		},
+		{
+			name: "approved promotions count towards min admins",
+			config: org.Config{
+				Admins:  []string{"member"},
+				Members: []string{"old-admin"},
+			},
+			opt: root.Options{
+				MinAdmins:             1,
+				ConfirmAdminDemotions: true,
+				RequireApproval:       true,
+				ApprovedChanges:       []string{changeID(changeAdminGrant, fakeOrg, "member")},
+			},
+			admins:     []string{"old-admin"},
+			members:    []string{"member"},
+			addAdmins:  []string{"member"},
+			addMembers: []string{"old-admin"},
+		},
+		{
+			name: "unapproved bulk removals are skipped",
+			config: org.Config{
+				Admins: []string{"keep"},
+			},
+			opt: root.Options{
+				MaxDelta:        1,
+				RequireApproval: true,
+				ApprovedChanges: []string{changeID(changeBulkRemoval, fakeOrg, "b")},
+			},
+			admins:  []string{"keep"},
+			members: []string{"a", "b", "c", "d", "e", "f"},
+			remove:  []string{"b"},
+		},
+		{
+			name: "a few removals need no approval",
+			config: org.Config{
+				Admins: []string{"keep"},
+			},
+			opt: root.Options{
+				MaxDelta:        1,
+				RequireApproval: true,
+			},
+			admins:  []string{"keep"},
+			members: []string{"a", "b"},
+			remove:  []string{"a", "b"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -528,7 +732,8 @@ func TestConfigureOrgMembers(t *testing.T) {
 				newMembers: sets.Set[string]{},
 			}
 
-			err := configureOrgMembers(tc.opt, fc, fakeOrg, tc.config, sets.New[string](tc.invitations...))
+			protected := newProtection(tc.opt, fakeOrg, &config.Protected{Users: tc.protected})
+			err := configureOrgMembers(tc.opt, fc, fakeOrg, tc.config, sets.New[string](tc.invitations...), sets.New[string](tc.frozen...), protected, nil)
 			switch {
 			case err != nil:
 				if !tc.err {
@@ -549,14 +754,268 @@ func TestConfigureOrgMembers(t *testing.T) {
 	}
 }
 
+type recordingClient struct {
+	*fakeClient
+	changes []string
+}
+
+func (c *recordingClient) Record(org, change, target string, before, after interface{}) {
+	c.changes = append(c.changes, fmt.Sprintf("%s %s %s %v->%v", org, change, target, before, after))
+}
+
+func TestRecordOrgMembers(t *testing.T) {
+	rc := &recordingClient{fakeClient: &fakeClient{
+		admins:     sets.New[string]("demote"),
+		members:    sets.New[string]("promote", "remove", "fail"),
+		removed:    sets.Set[string]{},
+		newAdmins:  sets.Set[string]{},
+		newMembers: sets.Set[string]{},
+	}}
+	cfg := org.Config{
+		Admins:  []string{"promote", "fail"},
+		Members: []string{"demote", "invite"},
+	}
+	opt := root.Options{MaxDelta: 1, MaxAdminDemotions: 1}
+	if err := configureOrgMembers(opt, rc, fakeOrg, cfg, sets.Set[string]{}, sets.Set[string]{}, protection{}, nil); err == nil {
+		t.Fatal("Failed to receive error")
+	}
+	sort.Strings(rc.changes)
+	expected := []string{
+		fakeOrg + " org.membership.invite invite ->member",
+		fakeOrg + " org.membership.remove remove member-><nil>",
+		fakeOrg + " org.membership.set demote admin->member",
+		fakeOrg + " org.membership.set promote member->admin",
+	}
+	if diff := cmp.Diff(expected, rc.changes); diff != "" {
+		t.Errorf("Wrong changes recorded (-want +got):\n%s", diff)
+	}
+}
+
+func TestMemberOrigins(t *testing.T) {
+	cfg := config.Config{
+		Members: []config.Member{{Login: "anne"}, {Login: "Bob", Source: "file hr.csv"}},
+		Teams: map[string]config.Team{
+			"eng": {Members: []config.Member{{Login: "carl", Source: "ldap cn=eng"}}},
+		},
+	}
+	orgSourced, teamSourced := memberOrigins(cfg)
+	for _, tc := range []struct {
+		sourced  origins
+		user     string
+		expected string
+	}{
+		{sourced: orgSourced, user: "anne"},
+		{sourced: orgSourced, user: "bob", expected: " (from file hr.csv)"},
+		{sourced: teamSourced["eng"], user: "carl", expected: " (from ldap cn=eng)"},
+		{sourced: teamSourced["other"], user: "carl"},
+	} {
+		if actual := tc.sourced.from(tc.user); actual != tc.expected {
+			t.Errorf("from(%s) = %q, want %q", tc.user, actual, tc.expected)
+		}
+	}
+}
+
+type fakeRenameClient []github.User
+
+func (c fakeRenameClient) ListOrgMemberUsers(org string) ([]github.User, error) {
+	return c, nil
+}
+
+func TestDetectRenames(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   config.Config
+		users    []github.User
+		expected map[string]string
+		err      bool
+	}{
+		{
+			name:   "skip configs without user IDs",
+			config: config.Config{Members: config.NewMembers("anne")},
+			users:  []github.User{{Login: "anne-renamed", ID: 1}},
+		},
+		{
+			name: "detect renamed logins by user ID",
+			config: config.Config{
+				Admins:  []config.Member{{Login: "Anne", ID: 1}},
+				Members: []config.Member{{Login: "bob", ID: 2}, {Login: "carl", ID: 3}},
+			},
+			users: []github.User{
+				{Login: "anne-renamed", ID: 1},
+				{Login: "Bob", ID: 2},
+				{Login: "dave", ID: 4},
+			},
+			expected: map[string]string{"anne": "anne-renamed"},
+		},
+		{
+			name: "reject user IDs declared for two logins",
+			config: config.Config{
+				Admins:  []config.Member{{Login: "anne", ID: 1}},
+				Members: []config.Member{{Login: "bob", ID: 1}},
+			},
+			err: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := detectRenames(fakeRenameClient(tc.users), fakeOrg, tc.config)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("Failed to receive error")
+			default:
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("Wrong renames (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+type fakeLockClient struct {
+	users []github.User
+	teams []github.Team
+	repos []ghclient.RepoID
+}
+
+func (c fakeLockClient) ListOrgMemberUsers(org string) ([]github.User, error) {
+	return c.users, nil
+}
+
+func (c fakeLockClient) ListTeams(org string) ([]github.Team, error) {
+	return c.teams, nil
+}
+
+func (c fakeLockClient) ListOrgRepoIDs(org string) ([]ghclient.RepoID, error) {
+	return c.repos, nil
+}
+
+func TestLock(t *testing.T) {
+	cfg := config.Config{
+		Admins:  []config.Member{{Login: "Anne"}},
+		Members: []config.Member{{Login: "bob", ID: 7}, {Login: "invited"}},
+		Teams: map[string]config.Team{
+			"parent": {
+				Team: org.Team{Previously: []string{"old-parent"}},
+				Children: map[string]config.Team{
+					"child": {},
+				},
+			},
+			"missing": {},
+		},
+		Repos: map[string]config.Repo{
+			"repo":    {},
+			"renamed": {Repo: org.Repo{Previously: []string{"Before"}}},
+			"gone":    {},
+		},
+	}
+	client := fakeLockClient{
+		users: []github.User{{Login: "anne", ID: 1}, {Login: "bob-renamed", ID: 7}},
+		teams: []github.Team{
+			{Slug: "old-parent", Name: "old-parent", ID: 10},
+			{Slug: "child", Name: "child", ID: 12},
+			{Slug: "child-1", Name: "child", ID: 11},
+		},
+		repos: []ghclient.RepoID{{ID: 20, Name: "repo"}, {ID: 21, Name: "before"}, {ID: 22, Name: "fork"}},
+	}
+	cases := []struct {
+		name     string
+		previous config.OrgLock
+		expected config.OrgLock
+	}{
+		{
+			name: "resolves IDs by name",
+			expected: config.OrgLock{
+				Users: map[string]int{"anne": 1, "bob": 7},
+				Teams: map[string]int{"parent": 10, "child": 11},
+				Repos: map[string]int{"repo": 20, "renamed": 21},
+			},
+		},
+		{
+			name: "keeps previous IDs that still exist",
+			previous: config.OrgLock{
+				Users: map[string]int{"anne": 2},
+				Teams: map[string]int{"child": 12, "missing": 13},
+				Repos: map[string]int{"repo": 22, "gone": 23},
+			},
+			expected: config.OrgLock{
+				Users: map[string]int{"anne": 1, "bob": 7},
+				Teams: map[string]int{"parent": 10, "child": 12},
+				Repos: map[string]int{"repo": 22, "renamed": 21},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Lock(client, fakeOrg, cfg, tc.previous)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Wrong lock (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 type fakeTeamClient struct {
-	teams map[string]github.Team
-	max   int
+	teams       map[string]github.Team
+	max         int
+	settings    map[string]ghclient.TeamSettings
+	assignments map[string]ghclient.ReviewAssignment
+	excluded    map[string][]string
+	// reported are the excluded members GitHub reports, if any.
+	reported map[string][]string
+}
+
+func (c *fakeTeamClient) GetTeamSettings(org, teamSlug string) (*ghclient.TeamSettings, error) {
+	if _, ok := c.teams[teamSlug]; !ok {
+		return nil, &ghclient.RequestError{StatusCode: http.StatusNotFound}
+	}
+	settings := c.settings[teamSlug]
+	return &settings, nil
+}
+
+func (c *fakeTeamClient) EditTeamSettings(org, teamSlug string, settings ghclient.TeamSettings) error {
+	cur := c.settings[teamSlug]
+	if settings.NotificationSetting != nil {
+		cur.NotificationSetting = settings.NotificationSetting
+	}
+	if settings.Permission != nil {
+		cur.Permission = settings.Permission
+	}
+	c.settings[teamSlug] = cur
+	return nil
+}
+
+func (c *fakeTeamClient) GetTeamReviewAssignment(org, teamSlug string) (*ghclient.ReviewAssignment, error) {
+	if _, ok := c.teams[teamSlug]; !ok {
+		return nil, &ghclient.RequestError{StatusCode: http.StatusNotFound}
+	}
+	assignment := c.assignments[teamSlug]
+	return &assignment, nil
+}
+
+func (c *fakeTeamClient) SetTeamReviewAssignment(org, teamSlug string, assignment ghclient.ReviewAssignment, excluded []string) error {
+	c.assignments[teamSlug] = assignment
+	c.excluded[teamSlug] = excluded
+	return nil
+}
+
+func (c *fakeTeamClient) ListTeamReviewExcludedMembers(org, teamSlug string) ([]string, bool, error) {
+	excluded, ok := c.reported[teamSlug]
+	return excluded, ok, nil
 }
 
 func makeFakeTeamClient(teams ...github.Team) *fakeTeamClient {
 	fc := fakeTeamClient{
-		teams: map[string]github.Team{},
+		teams:       map[string]github.Team{},
+		settings:    map[string]ghclient.TeamSettings{},
+		assignments: map[string]ghclient.ReviewAssignment{},
+		excluded:    map[string][]string{},
 	}
 	for _, t := range teams {
 		fc.teams[t.Slug] = t
@@ -712,6 +1171,10 @@ func TestConfigureTeams(t *testing.T) {
 		expected          map[string]github.Team
 		deleted           []string
 		delta             float64
+		protected         []string
+		opt               root.Options
+		scope             scope
+		locked            map[string]int
 	}{
 		{
 			name: "do nothing without error",
@@ -854,6 +1317,69 @@ func TestConfigureTeams(t *testing.T) {
 			deleted: []string{"unused"},
 			delta:   0.6,
 		},
+		{
+			name: "do not delete protected teams",
+			teams: []github.Team{
+				{
+					Name: "Security Team",
+					Slug: "security-team",
+					ID:   1,
+				},
+				{
+					Name: "unused",
+					Slug: "unused",
+					ID:   2,
+				},
+			},
+			config:    org.Config{Teams: map[string]org.Team{}},
+			expected:  map[string]github.Team{},
+			deleted:   []string{"unused"},
+			protected: []string{"Security Team"},
+		},
+		{
+			name: "delete protected teams when protections are overridden",
+			teams: []github.Team{
+				{
+					Name: "Security Team",
+					Slug: "security-team",
+					ID:   1,
+				},
+				{
+					Name: "unused",
+					Slug: "unused",
+					ID:   2,
+				},
+			},
+			config:    org.Config{Teams: map[string]org.Team{}},
+			expected:  map[string]github.Team{},
+			deleted:   []string{"security-team", "unused"},
+			protected: []string{"Security Team"},
+			opt:       root.Options{OverrideProtected: true},
+		},
+		{
+			name: "overridden protections still need approval for deletions",
+			teams: []github.Team{
+				{
+					Name: "Security Team",
+					Slug: "security-team",
+					ID:   1,
+				},
+				{
+					Name: "unused",
+					Slug: "unused",
+					ID:   2,
+				},
+			},
+			config:    org.Config{Teams: map[string]org.Team{}},
+			expected:  map[string]github.Team{},
+			deleted:   []string{"security-team"},
+			protected: []string{"Security Team"},
+			opt: root.Options{
+				OverrideProtected: true,
+				RequireApproval:   true,
+				ApprovedChanges:   []string{changeID(changeTeamDelete, fakeOrg, "security-team")},
+			},
+		},
 		{
 			name: "refuse to delete too many teams",
 			teams: []github.Team{
@@ -899,6 +1425,45 @@ func TestConfigureTeams(t *testing.T) {
 			deleted:  []string{"closed"},
 			delta:    1,
 		},
+		{
+			name: "read-only and ignored teams are neither created nor deleted",
+			teams: []github.Team{
+				{Name: "legacy-a", Slug: "legacy-a", ID: 1},
+				{Name: "bots-x", Slug: "bots-x", ID: 2},
+				{Name: "drop", Slug: "drop", ID: 3},
+			},
+			config: org.Config{
+				Teams: map[string]org.Team{
+					"legacy-new": {},
+				},
+			},
+			scope: scope{
+				readOnly: config.Patterns{Teams: []string{"legacy-*"}},
+				ignored:  config.Patterns{Teams: []string{"bots-*"}},
+			},
+			deleted: []string{"drop"},
+			delta:   1,
+		},
+		{
+			name: "match teams by their locked ID",
+			config: org.Config{
+				Teams: map[string]org.Team{
+					"renamed": {},
+					"dup":     {},
+				},
+			},
+			teams: []github.Team{
+				{Name: "renamed-elsewhere", ID: 1, Slug: "renamed-elsewhere"},
+				{Name: "dup", ID: 2, Slug: "dup"},
+				{Name: "dup", ID: 3, Slug: "dup-2"},
+			},
+			locked: map[string]int{"renamed": 1, "dup": 3},
+			expected: map[string]github.Team{
+				"renamed": {Name: "renamed-elsewhere", ID: 1, Slug: "renamed-elsewhere"},
+				"dup":     {Name: "dup", ID: 3, Slug: "dup-2"},
+			},
+			deleted: []string{"dup"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -914,7 +1479,8 @@ func TestConfigureTeams(t *testing.T) {
 			if tc.delta == 0 {
 				tc.delta = 1
 			}
-			actual, err := configureTeams(fc, orgName, tc.config, tc.delta, tc.ignoreSecretTeams)
+			protected := newProtection(tc.opt, orgName, &config.Protected{Teams: tc.protected})
+			actual, err := configureTeams(fc, orgName, tc.config, tc.delta, tc.ignoreSecretTeams, protected, newApprovals(tc.opt), tc.scope, tc.locked)
 			switch {
 			case err != nil:
 				if !tc.err {
@@ -1120,17 +1686,204 @@ func TestConfigureTeam(t *testing.T) {
 	}
 }
 
-func TestConfigureTeamMembers(t *testing.T) {
-	cases := []struct {
-		name           string
-		err            bool
-		members        sets.Set[string]
-		maintainers    sets.Set[string]
+func TestConfigureTeamSettings(t *testing.T) {
+	enabled := "notifications_enabled"
+	disabled := "notifications_disabled"
+	pull := "pull"
+	push := "push"
+	off := false
+	team := github.Team{ID: 1, Slug: "team", Name: "team"}
+	journaled := func(excluded ...string) root.Options {
+		key := journal.Key{Org: fakeOrg, Change: changeReviewAssignment, Target: team.Slug}
+		after := config.ReviewAssignment{ExcludedMembers: excluded}
+		return root.Options{Journaled: map[journal.Key]journal.Entry{key: {After: after}}}
+	}
+	testCases := []struct {
+		name                string
+		opt                 root.Options
+		team                github.Team
+		want                config.TeamSettings
+		settings            ghclient.TeamSettings
+		assignment          ghclient.ReviewAssignment
+		reported            map[string][]string
+		expectedSettings    ghclient.TeamSettings
+		expectedAssignment  ghclient.ReviewAssignment
+		expectedExcluded    []string
+		expectAssignmentSet bool
+		err                 bool
+	}{
+		{
+			name:             "undeclared settings are left alone",
+			team:             team,
+			settings:         ghclient.TeamSettings{NotificationSetting: &enabled, Permission: &pull},
+			expectedSettings: ghclient.TeamSettings{NotificationSetting: &enabled, Permission: &pull},
+		},
+		{
+			name:             "declared settings are updated",
+			team:             team,
+			want:             config.TeamSettings{NotificationSetting: &disabled, Permission: &push},
+			settings:         ghclient.TeamSettings{NotificationSetting: &enabled, Permission: &pull},
+			expectedSettings: ghclient.TeamSettings{NotificationSetting: &disabled, Permission: &push},
+		},
+		{
+			name: "review assignment is enabled with defaults",
+			team: team,
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{}},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectAssignmentSet: true,
+		},
+		{
+			name: "matching review assignment is left alone",
+			team: team,
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{Algorithm: config.LoadBalance, MemberCount: 2, NotifyTeam: true}},
+			assignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "LOAD_BALANCE", MemberCount: 2, NotifyTeam: true,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "LOAD_BALANCE", MemberCount: 2, NotifyTeam: true,
+			},
+		},
+		{
+			name: "review assignment excluding members is always applied without a journal",
+			team: team,
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{ExcludedMembers: []string{"anne"}}},
+			assignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedExcluded:    []string{"anne"},
+			expectAssignmentSet: true,
+		},
+		{
+			name:     "review assignment excluding the reported members is left alone",
+			team:     team,
+			want:     config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{ExcludedMembers: []string{"anne"}}},
+			reported: map[string][]string{team.Slug: {"Anne"}},
+			assignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+		},
+		{
+			name:     "reported members take precedence over the journal",
+			opt:      journaled("anne"),
+			team:     team,
+			want:     config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{ExcludedMembers: []string{"anne"}}},
+			reported: map[string][]string{team.Slug: {}},
+			assignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedExcluded:    []string{"anne"},
+			expectAssignmentSet: true,
+		},
+		{
+			name: "review assignment excluding the journaled members is left alone",
+			opt:  journaled("Anne"),
+			team: team,
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{ExcludedMembers: []string{"anne"}}},
+			assignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+		},
+		{
+			name: "review assignment no longer excluding the journaled members is applied",
+			opt:  journaled("anne"),
+			team: team,
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{}},
+			assignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Enabled: true, Algorithm: "ROUND_ROBIN", MemberCount: 1,
+			},
+			expectAssignmentSet: true,
+		},
+		{
+			name: "disabled review assignment ignores the other settings",
+			team: team,
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{Enabled: &off, MemberCount: 3}},
+			assignment: ghclient.ReviewAssignment{
+				Algorithm: "LOAD_BALANCE", MemberCount: 2,
+			},
+			expectedAssignment: ghclient.ReviewAssignment{
+				Algorithm: "LOAD_BALANCE", MemberCount: 2,
+			},
+		},
+		{
+			name: "settings of missing teams are skipped in dry-run",
+			team: github.Team{Slug: "missing"},
+			want: config.TeamSettings{NotificationSetting: &disabled, ReviewAssignment: &config.ReviewAssignment{}},
+		},
+		{
+			name: "settings of missing teams fail with --confirm",
+			opt:  root.Options{Confirm: true},
+			team: github.Team{Slug: "missing"},
+			want: config.TeamSettings{NotificationSetting: &disabled},
+			err:  true,
+		},
+		{
+			name: "review assignment of missing teams fails with --confirm",
+			opt:  root.Options{Confirm: true},
+			team: github.Team{Slug: "missing"},
+			want: config.TeamSettings{ReviewAssignment: &config.ReviewAssignment{}},
+			err:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := makeFakeTeamClient(team)
+			fc.settings[team.Slug] = tc.settings
+			fc.assignments[team.Slug] = tc.assignment
+			fc.reported = tc.reported
+			err := configureTeamSettings(tc.opt, fc, fakeOrg, tc.team, tc.want)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Fatal("failed to receive error")
+			}
+			if diff := cmp.Diff(tc.expectedSettings, fc.settings[team.Slug]); diff != "" {
+				t.Errorf("unexpected settings (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedAssignment, fc.assignments[team.Slug]); diff != "" {
+				t.Errorf("unexpected review assignment (-want +got):\n%s", diff)
+			}
+			if _, set := fc.excluded[team.Slug]; set != tc.expectAssignmentSet {
+				t.Errorf("review assignment set: %t, expected %t", set, tc.expectAssignmentSet)
+			}
+			if diff := cmp.Diff(tc.expectedExcluded, fc.excluded[team.Slug]); diff != "" {
+				t.Errorf("unexpected excluded members (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigureTeamMembers(t *testing.T) {
+	cases := []struct {
+		name           string
+		err            bool
+		members        sets.Set[string]
+		maintainers    sets.Set[string]
 		remove         sets.Set[string]
 		addMembers     sets.Set[string]
 		addMaintainers sets.Set[string]
 		ignoreInvitees bool
 		invitees       sets.Set[string]
+		protected      sets.Set[string]
 		team           org.Team
 		slug           string
 	}{
@@ -1195,6 +1948,16 @@ func TestConfigureTeamMembers(t *testing.T) {
 			addMembers:     sets.New[string]("new-member"),
 			ignoreInvitees: true,
 		},
+		{
+			name: "do not remove nor demote protected users",
+			team: org.Team{
+				Members: []string{"break-glass"},
+			},
+			maintainers: sets.New[string]("break-glass"),
+			members:     sets.New[string]("robot", "drop-member"),
+			protected:   sets.New[string]("break-glass", "robot"),
+			remove:      sets.New[string]("drop-member"),
+		},
 	}
 
 	for _, tc := range cases {
@@ -1217,7 +1980,7 @@ func TestConfigureTeamMembers(t *testing.T) {
 
 			opts := root.Options{}
 
-			err := configureTeamMembers(opts, fc, "", gt, tc.team, tc.ignoreInvitees)
+			err := configureTeamMembers(opts, fc, "", gt, tc.team, tc.ignoreInvitees, protection{users: tc.protected}, nil)
 			switch {
 			case err != nil:
 				if !tc.err {
@@ -1253,11 +2016,118 @@ func cmpLists(a, b []string) error {
 	return nil
 }
 
-type fakeOrgClient struct {
-	current github.Organization
+type fakeTeamSyncClient struct {
+	org     []ghclient.IdPGroup
+	teams   map[string][]ghclient.IdPGroup
 	changed bool
 }
 
+func (c *fakeTeamSyncClient) ListOrgIdPGroups(org string) ([]ghclient.IdPGroup, error) {
+	return c.org, nil
+}
+
+func (c *fakeTeamSyncClient) ListTeamIdPGroups(org, teamSlug string) ([]ghclient.IdPGroup, error) {
+	groups, ok := c.teams[teamSlug]
+	if !ok {
+		return nil, &ghclient.RequestError{StatusCode: http.StatusNotFound}
+	}
+	return groups, nil
+}
+
+func (c *fakeTeamSyncClient) SetTeamIdPGroups(org, teamSlug string, groups []ghclient.IdPGroup) error {
+	c.teams[teamSlug] = groups
+	c.changed = true
+	return nil
+}
+
+func TestConfigureTeamSync(t *testing.T) {
+	engineering := ghclient.IdPGroup{ID: "1", Name: "Engineering"}
+	sre := ghclient.IdPGroup{ID: "2", Name: "SRE"}
+	cases := []struct {
+		name     string
+		confirm  bool
+		have     map[string][]ghclient.IdPGroup
+		want     []string
+		expected []ghclient.IdPGroup
+		changed  bool
+		err      bool
+	}{
+		{
+			name:     "link the team to groups",
+			have:     map[string][]ghclient.IdPGroup{"team": {engineering}},
+			want:     []string{"Engineering", "SRE"},
+			expected: []ghclient.IdPGroup{engineering, sre},
+			changed:  true,
+		},
+		{
+			name:     "leave linked groups alone",
+			have:     map[string][]ghclient.IdPGroup{"team": {sre, engineering}},
+			want:     []string{"Engineering", "SRE"},
+			expected: []ghclient.IdPGroup{sre, engineering},
+		},
+		{
+			name:    "unlink the team",
+			have:    map[string][]ghclient.IdPGroup{"team": {engineering}},
+			want:    []string{},
+			changed: true,
+		},
+		{
+			name: "reject unknown groups",
+			have: map[string][]ghclient.IdPGroup{"team": {}},
+			want: []string{"Engineering", "Unknown"},
+			err:  true,
+		},
+		{
+			name:     "link teams which do not exist yet during dry-run",
+			have:     map[string][]ghclient.IdPGroup{},
+			want:     []string{"SRE"},
+			expected: []ghclient.IdPGroup{sre},
+			changed:  true,
+		},
+		{
+			name:    "fail on teams which do not exist",
+			confirm: true,
+			have:    map[string][]ghclient.IdPGroup{},
+			want:    []string{"SRE"},
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeTeamSyncClient{
+				org:   []ghclient.IdPGroup{engineering, sre},
+				teams: tc.have,
+			}
+			opts := root.Options{Confirm: tc.confirm}
+			gt := github.Team{Slug: "team", Name: "team"}
+			err := configureTeamSync(opts, fc, fakeOrg, gt, tc.want, idpGroupsByName(fc, fakeOrg))
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("Failed to receive error")
+			default:
+				if fc.changed != tc.changed {
+					t.Errorf("changed = %t, want %t", fc.changed, tc.changed)
+				}
+				if diff := cmp.Diff(tc.expected, fc.teams["team"]); diff != "" {
+					t.Errorf("unexpected IdP groups (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+type fakeOrgClient struct {
+	current  github.Organization
+	changed  bool
+	settings ghclient.OrgSettings
+	edited   *ghclient.OrgSettings
+}
+
 func (o *fakeOrgClient) GetOrg(name string) (*github.Organization, error) {
 	if name == "fail" {
 		return nil, errors.New("injected GetOrg error")
@@ -1274,6 +2144,22 @@ func (o *fakeOrgClient) EditOrg(name string, org github.Organization) (*github.O
 	return &o.current, nil
 }
 
+func (o *fakeOrgClient) GetOrgSettings(name string) (*ghclient.OrgSettings, error) {
+	if name == "fail" {
+		return nil, errors.New("injected GetOrgSettings error")
+	}
+	settings := o.settings
+	return &settings, nil
+}
+
+func (o *fakeOrgClient) EditOrgSettings(name string, settings ghclient.OrgSettings) error {
+	if settings.Blog != nil && *settings.Blog == "fail" {
+		return errors.New("injected EditOrgSettings error")
+	}
+	o.edited = &settings
+	return nil
+}
+
 func TestUpdateBool(t *testing.T) {
 	yes := true
 	no := false
@@ -1407,6 +2293,92 @@ func TestUpdateString(t *testing.T) {
 	}
 }
 
+type fakeTwoFactorClient struct {
+	without []string
+}
+
+func (c fakeTwoFactorClient) ListOrgMembersWithout2FA(org string) ([]github.TeamMember, error) {
+	if org == "fail" {
+		return nil, errors.New("injected ListOrgMembersWithout2FA error")
+	}
+	var ret []github.TeamMember
+	for _, login := range c.without {
+		ret = append(ret, github.TeamMember{Login: login})
+	}
+	return ret, nil
+}
+
+func TestCheckTwoFactor(t *testing.T) {
+	orgConfig := org.Config{
+		Admins:  []string{"Admin"},
+		Members: []string{"member", "other"},
+	}
+	cases := []struct {
+		name     string
+		orgName  string
+		mode     string
+		without  []string
+		expected []string
+		err      bool
+	}{
+		{
+			name:    "ignore does not list members",
+			orgName: "fail",
+			mode:    root.TwoFactorIgnore,
+		},
+		{
+			name:    "report does not freeze",
+			mode:    root.TwoFactorReport,
+			without: []string{"admin", "member", "undeclared"},
+		},
+		{
+			name:     "freeze declared members",
+			mode:     root.TwoFactorFreeze,
+			without:  []string{"admin", "member", "undeclared"},
+			expected: []string{"admin", "member"},
+		},
+		{
+			name:    "fail on declared members",
+			mode:    root.TwoFactorFail,
+			without: []string{"undeclared", "member"},
+			err:     true,
+		},
+		{
+			name:    "do not fail on undeclared members",
+			mode:    root.TwoFactorFail,
+			without: []string{"undeclared"},
+		},
+		{
+			name:    "fail if listing fails",
+			orgName: "fail",
+			mode:    root.TwoFactorReport,
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			orgName := "org"
+			if tc.orgName != "" {
+				orgName = tc.orgName
+			}
+			frozen, err := checkTwoFactor(root.Options{TwoFactor: tc.mode}, fakeTwoFactorClient{without: tc.without}, orgName, orgConfig)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("failed to receive error")
+			default:
+				if err := cmpLists(tc.expected, sets.List(frozen)); err != nil {
+					t.Errorf("wrong users frozen: %v", err)
+				}
+			}
+		})
+	}
+}
+
 func TestConfigureOrgMeta(t *testing.T) {
 	filled := github.Organization{
 		BillingEmail:                 "be",
@@ -1562,7 +2534,7 @@ func TestConfigureOrgMeta(t *testing.T) {
 			fc := fakeOrgClient{
 				current: tc.have,
 			}
-			err := configureOrgMeta(&fc, tc.orgName, tc.want)
+			err := configureOrgMeta(&fc, tc.orgName, tc.want, config.OrgSettings{})
 			switch {
 			case err != nil:
 				if !tc.err {
@@ -1579,6 +2551,92 @@ func TestConfigureOrgMeta(t *testing.T) {
 	}
 }
 
+func TestConfigureOrgSettings(t *testing.T) {
+	yes := true
+	no := false
+	blog := "https://blog.example.com"
+	fail := "fail"
+
+	cases := []struct {
+		name     string
+		orgName  string
+		want     config.OrgSettings
+		have     ghclient.OrgSettings
+		expected *ghclient.OrgSettings
+		err      bool
+	}{
+		{
+			name: "no want means no change",
+			have: ghclient.OrgSettings{Blog: &blog, WebCommitSignoffRequired: &yes},
+		},
+		{
+			name:    "unset settings do not call GetOrgSettings",
+			orgName: fail,
+		},
+		{
+			name: "same values means no change",
+			want: config.OrgSettings{Blog: &blog, WebCommitSignoffRequired: &yes},
+			have: ghclient.OrgSettings{Blog: &blog, WebCommitSignoffRequired: &yes},
+		},
+		{
+			name: "only changed settings are sent",
+			want: config.OrgSettings{
+				Blog:                                    &blog,
+				MembersCanCreatePublicRepositories:      &no,
+				MembersCanForkPrivateRepositories:       &no,
+				SecretScanningEnabledForNewRepositories: &yes,
+			},
+			have: ghclient.OrgSettings{
+				Blog:                               &blog,
+				MembersCanCreatePublicRepositories: &yes,
+				MembersCanForkPrivateRepositories:  &no,
+			},
+			expected: &ghclient.OrgSettings{
+				MembersCanCreatePublicRepositories:      &no,
+				SecretScanningEnabledForNewRepositories: &yes,
+			},
+		},
+		{
+			name: "two-factor requirement is only reported",
+			want: config.OrgSettings{TwoFactorRequirementEnabled: &yes},
+			have: ghclient.OrgSettings{TwoFactorRequirementEnabled: &no},
+		},
+		{
+			name:    "fail if GetOrgSettings fails",
+			orgName: fail,
+			want:    config.OrgSettings{Blog: &blog},
+			err:     true,
+		},
+		{
+			name: "fail if EditOrgSettings fails",
+			want: config.OrgSettings{Blog: &fail},
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.orgName == "" {
+				tc.orgName = "whatever"
+			}
+			fc := fakeOrgClient{settings: tc.have}
+			err := configureOrgSettings(&fc, tc.orgName, tc.want)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("failed to receive error")
+			default:
+				if diff := cmp.Diff(tc.expected, fc.edited); diff != "" {
+					t.Errorf("edited settings differ (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
 func TestDumpOrgConfig(t *testing.T) {
 	empty := ""
 	hello := "Hello"
@@ -1593,10 +2651,23 @@ func TestDumpOrgConfig(t *testing.T) {
 	repoDescription := "awesome testing project"
 	repoHomepage := "https://www.somewhe.re/something/"
 	master := "master-branch"
+	contentType := "json"
+	blog := "https://blog.example.com"
+	all := config.ActionsAll
+	none := config.ActionsNone
+	selected := config.ActionsSelected
+	localOnly := config.ActionsLocalOnly
+	read := config.WorkflowPermissionsRead
+	notificationsEnabled := "notifications_enabled"
+	notificationsDisabled := "notifications_disabled"
+	pullPermission := "pull"
+	pushPermission := "push"
 	cases := []struct {
 		name              string
 		orgOverride       string
 		ignoreSecretTeams bool
+		ignored           config.Patterns
+		ignore            *config.Patterns
 		meta              github.Organization
 		members           []string
 		admins            []string
@@ -1605,7 +2676,17 @@ func TestDumpOrgConfig(t *testing.T) {
 		maintainers       map[string][]string
 		repoPermissions   map[string][]github.Repo
 		repos             []github.FullRepo
-		expected          org.Config
+		orgHooks          []github.Hook
+		repoHooks         map[string][]github.Hook
+		collaborators     map[string][]ghclient.Collaborator
+		orgRoles          []ghclient.OrgRole
+		orgRoleTeams      map[int][]github.Team
+		actions           fakeActionsClient
+		settings          ghclient.OrgSettings
+		idpGroups         map[string][]ghclient.IdPGroup
+		teamSettings      map[string]ghclient.TeamSettings
+		assignments       map[string]ghclient.ReviewAssignment
+		expected          config.Config
 		err               bool
 	}{
 		{
@@ -1717,20 +2798,65 @@ func TestDumpOrgConfig(t *testing.T) {
 					},
 				},
 			},
-			expected: org.Config{
-				Metadata: org.Metadata{
-					Name:                         &hello,
-					BillingEmail:                 &empty,
-					Company:                      &empty,
-					Email:                        &empty,
-					Description:                  &empty,
-					Location:                     &empty,
-					HasOrganizationProjects:      &no,
-					HasRepositoryProjects:        &no,
-					DefaultRepositoryPermission:  &perm,
-					MembersCanCreateRepositories: &yes,
+			settings: ghclient.OrgSettings{
+				Blog:                        &blog,
+				MembersCanCreatePages:       &no,
+				TwoFactorRequirementEnabled: &yes,
+			},
+			actions: fakeActionsClient{
+				orgPerms:    ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: selected},
+				orgSelected: ghclient.SelectedActions{GithubOwnedAllowed: true, PatternsAllowed: []string{"my-org/*"}},
+				orgWorkflow: ghclient.WorkflowPermissions{DefaultWorkflowPermissions: read},
+				repoPerms: map[string]ghclient.RepoActionsPermissions{
+					repoName: {Enabled: true, AllowedActions: localOnly},
 				},
-				Teams: map[string]org.Team{
+				repoWorkflow: map[string]ghclient.WorkflowPermissions{
+					repoName: {DefaultWorkflowPermissions: read},
+				},
+			},
+			orgHooks: []github.Hook{
+				{
+					ID:     1,
+					Name:   "web",
+					Events: []string{"push"},
+					Active: true,
+					Config: github.HookConfig{URL: "https://ci.example.com/hook", ContentType: &contentType},
+				},
+			},
+			repoHooks: map[string][]github.Hook{
+				repoName: {
+					{
+						ID:     2,
+						Name:   "web",
+						Events: []string{"pull_request"},
+						Active: false,
+						Config: github.HookConfig{URL: "https://bot.example.com/hook", ContentType: &contentType},
+					},
+				},
+			},
+			collaborators: map[string][]ghclient.Collaborator{
+				repoName: {{Login: "Contractor", RoleName: "triage-plus"}},
+			},
+			orgRoles: []ghclient.OrgRole{{ID: 1, Name: "all_repo_write"}, {ID: 2, Name: "security_manager"}},
+			orgRoleTeams: map[int][]github.Team{
+				1: {{ID: 6, Slug: "team-6"}, {ID: 5, Slug: "team-5"}},
+			},
+			expected: config.Config{
+				Config: org.Config{
+					Metadata: org.Metadata{
+						Name:                         &hello,
+						BillingEmail:                 &empty,
+						Company:                      &empty,
+						Email:                        &empty,
+						Description:                  &empty,
+						Location:                     &empty,
+						HasOrganizationProjects:      &no,
+						HasRepositoryProjects:        &no,
+						DefaultRepositoryPermission:  &perm,
+						MembersCanCreateRepositories: &yes,
+					},
+				},
+				Teams: config.NewTeams(map[string]org.Team{
 					"friends": {
 						TeamMetadata: org.TeamMetadata{
 							Description: &details,
@@ -1767,21 +2893,62 @@ func TestDumpOrgConfig(t *testing.T) {
 							},
 						},
 					},
+				}),
+				Members: config.NewMembers("george", "jungle", "banana"),
+				Admins:  config.NewMembers("admin", "james", "giant", "peach"),
+				OrgSettings: config.OrgSettings{
+					Blog:                        &blog,
+					MembersCanCreatePages:       &no,
+					TwoFactorRequirementEnabled: &yes,
 				},
-				Members: []string{"george", "jungle", "banana"},
-				Admins:  []string{"admin", "james", "giant", "peach"},
-				Repos: map[string]org.Repo{
+				OrgRoles: map[string][]string{"all_repo_write": {"enemies", "friends"}},
+				Repos: map[string]config.Repo{
 					"project": {
-						Description:      &repoDescription,
-						HomePage:         &repoHomepage,
-						HasProjects:      &yes,
-						AllowMergeCommit: &no,
-						AllowRebaseMerge: &no,
-						AllowSquashMerge: &no,
-						Archived:         &yes,
-						DefaultBranch:    &master,
+						Repo: org.Repo{
+							Description:      &repoDescription,
+							HomePage:         &repoHomepage,
+							HasProjects:      &yes,
+							AllowMergeCommit: &no,
+							AllowRebaseMerge: &no,
+							AllowSquashMerge: &no,
+							Archived:         &yes,
+							DefaultBranch:    &master,
+						},
+						Webhooks: []config.Webhook{
+							{
+								URL:         "https://bot.example.com/hook",
+								Events:      []string{"pull_request"},
+								ContentType: &contentType,
+								Active:      &no,
+							},
+						},
+						Collaborators: map[string]github.RepoPermissionLevel{
+							"Contractor": "triage-plus",
+						},
+						Actions: &config.ActionsPolicy{
+							AllowedActions: &localOnly,
+						},
+					},
+				},
+				Webhooks: []config.Webhook{
+					{
+						URL:         "https://ci.example.com/hook",
+						Events:      []string{"push"},
+						ContentType: &contentType,
+						Active:      &yes,
 					},
 				},
+				Actions: &config.ActionsPolicy{
+					EnabledRepositories: &all,
+					AllowedActions:      &selected,
+					SelectedActions: &config.SelectedActions{
+						GithubOwnedAllowed: &yes,
+						VerifiedAllowed:    &no,
+						PatternsAllowed:    []string{"my-org/*"},
+					},
+					DefaultWorkflowPermissions:   &read,
+					CanApprovePullRequestReviews: &no,
+				},
 			},
 		},
 		{
@@ -1840,20 +3007,22 @@ func TestDumpOrgConfig(t *testing.T) {
 				"team-7": {"banana"},
 				"team-8": {"starfish"},
 			},
-			expected: org.Config{
-				Metadata: org.Metadata{
-					Name:                         &hello,
-					BillingEmail:                 &empty,
-					Company:                      &empty,
-					Email:                        &empty,
-					Description:                  &empty,
-					Location:                     &empty,
-					HasOrganizationProjects:      &no,
-					HasRepositoryProjects:        &no,
-					DefaultRepositoryPermission:  &perm,
-					MembersCanCreateRepositories: &yes,
+			expected: config.Config{
+				Config: org.Config{
+					Metadata: org.Metadata{
+						Name:                         &hello,
+						BillingEmail:                 &empty,
+						Company:                      &empty,
+						Email:                        &empty,
+						Description:                  &empty,
+						Location:                     &empty,
+						HasOrganizationProjects:      &no,
+						HasRepositoryProjects:        &no,
+						DefaultRepositoryPermission:  &perm,
+						MembersCanCreateRepositories: &yes,
+					},
 				},
-				Teams: map[string]org.Team{
+				Teams: config.NewTeams(map[string]org.Team{
 					"friends": {
 						TeamMetadata: org.TeamMetadata{
 							Description: &details,
@@ -1885,13 +3054,262 @@ func TestDumpOrgConfig(t *testing.T) {
 						},
 						Repos: map[string]github.RepoPermissionLevel{},
 					},
-				},
-				Members: []string{"george", "jungle", "banana"},
-				Admins:  []string{"admin", "james", "giant", "peach"},
-				Repos:   map[string]org.Repo{},
-			},
-		},
-	}
+				}),
+				Members: config.NewMembers("george", "jungle", "banana"),
+				Admins:  config.NewMembers("admin", "james", "giant", "peach"),
+				Repos:   map[string]config.Repo{},
+				Actions: &config.ActionsPolicy{EnabledRepositories: &none},
+			},
+		}, {
+			name: "records IdP groups instead of members of synchronized teams",
+			meta: github.Organization{
+				Name:                         hello,
+				MembersCanCreateRepositories: yes,
+				DefaultRepositoryPermission:  string(perm),
+			},
+			admins:  []string{"admin"},
+			members: []string{"george"},
+			teams: []github.Team{
+				{ID: 5, Slug: "eng", Name: "eng"},
+				{ID: 6, Slug: "friends", Name: "friends"},
+			},
+			teamMembers: map[string][]string{"eng": {"george"}, "friends": {"george"}},
+			maintainers: map[string][]string{"eng": {"admin"}, "friends": {}},
+			idpGroups: map[string][]ghclient.IdPGroup{
+				"eng": {{ID: "1", Name: "Engineering"}},
+			},
+			expected: config.Config{
+				Config: org.Config{
+					Metadata: org.Metadata{
+						Name:                         &hello,
+						BillingEmail:                 &empty,
+						Company:                      &empty,
+						Email:                        &empty,
+						Description:                  &empty,
+						Location:                     &empty,
+						HasOrganizationProjects:      &no,
+						HasRepositoryProjects:        &no,
+						DefaultRepositoryPermission:  &perm,
+						MembersCanCreateRepositories: &yes,
+					},
+				},
+				Teams: func() map[string]config.Team {
+					teams := config.NewTeams(map[string]org.Team{
+						"eng": {
+							TeamMetadata: org.TeamMetadata{Description: &empty, Privacy: &pub},
+							Children:     map[string]org.Team{},
+							Repos:        map[string]github.RepoPermissionLevel{},
+						},
+						"friends": {
+							TeamMetadata: org.TeamMetadata{Description: &empty, Privacy: &pub},
+							Members:      []string{"george"},
+							Maintainers:  []string{},
+							Children:     map[string]org.Team{},
+							Repos:        map[string]github.RepoPermissionLevel{},
+						},
+					})
+					eng := teams["eng"]
+					eng.IdPGroups = []string{"Engineering"}
+					teams["eng"] = eng
+					return teams
+				}(),
+				Admins:  config.NewMembers("admin"),
+				Members: config.NewMembers("george"),
+				Repos:   map[string]config.Repo{},
+				Actions: &config.ActionsPolicy{EnabledRepositories: &none},
+			},
+		},
+		{
+			name: "records team settings differing from the defaults",
+			meta: github.Organization{
+				Name:                         hello,
+				MembersCanCreateRepositories: yes,
+				DefaultRepositoryPermission:  string(perm),
+			},
+			admins:  []string{"admin"},
+			members: []string{"george"},
+			teams: []github.Team{
+				{ID: 5, Slug: "eng", Name: "eng"},
+				{ID: 6, Slug: "friends", Name: "friends"},
+			},
+			teamMembers: map[string][]string{"eng": {"george"}, "friends": {"george"}},
+			maintainers: map[string][]string{"eng": {"admin"}, "friends": {}},
+			teamSettings: map[string]ghclient.TeamSettings{
+				"eng":     {NotificationSetting: &notificationsDisabled, Permission: &pushPermission},
+				"friends": {NotificationSetting: &notificationsEnabled, Permission: &pullPermission},
+			},
+			assignments: map[string]ghclient.ReviewAssignment{
+				"eng":     {Enabled: true, Algorithm: "LOAD_BALANCE", MemberCount: 2},
+				"friends": {Algorithm: "ROUND_ROBIN", MemberCount: 1},
+			},
+			expected: config.Config{
+				Config: org.Config{
+					Metadata: org.Metadata{
+						Name:                         &hello,
+						BillingEmail:                 &empty,
+						Company:                      &empty,
+						Email:                        &empty,
+						Description:                  &empty,
+						Location:                     &empty,
+						HasOrganizationProjects:      &no,
+						HasRepositoryProjects:        &no,
+						DefaultRepositoryPermission:  &perm,
+						MembersCanCreateRepositories: &yes,
+					},
+				},
+				Teams: func() map[string]config.Team {
+					teams := config.NewTeams(map[string]org.Team{
+						"eng": {
+							TeamMetadata: org.TeamMetadata{Description: &empty, Privacy: &pub},
+							Members:      []string{"george"},
+							Maintainers:  []string{"admin"},
+							Children:     map[string]org.Team{},
+							Repos:        map[string]github.RepoPermissionLevel{},
+						},
+						"friends": {
+							TeamMetadata: org.TeamMetadata{Description: &empty, Privacy: &pub},
+							Members:      []string{"george"},
+							Maintainers:  []string{},
+							Children:     map[string]org.Team{},
+							Repos:        map[string]github.RepoPermissionLevel{},
+						},
+					})
+					eng := teams["eng"]
+					eng.TeamSettings = config.TeamSettings{
+						NotificationSetting: &notificationsDisabled,
+						Permission:          &pushPermission,
+						ReviewAssignment:    &config.ReviewAssignment{Algorithm: config.LoadBalance, MemberCount: 2},
+					}
+					teams["eng"] = eng
+					return teams
+				}(),
+				Admins:  config.NewMembers("admin"),
+				Members: config.NewMembers("george"),
+				Repos:   map[string]config.Repo{},
+				Actions: &config.ActionsPolicy{EnabledRepositories: &none},
+			},
+		},
+		{
+			name: "leaves out ignored teams, repos and team repo permissions",
+			ignored: config.Patterns{
+				Teams:     []string{"idp-*"},
+				Repos:     []string{"sandbox-*"},
+				TeamRepos: []string{"friends/website"},
+			},
+			meta: github.Organization{
+				Name:                         hello,
+				MembersCanCreateRepositories: yes,
+				DefaultRepositoryPermission:  string(perm),
+			},
+			admins: []string{"admin"},
+			teams: []github.Team{
+				{ID: 5, Slug: "friends", Name: "friends"},
+				{ID: 6, Slug: "idp-sync", Name: "idp-sync"},
+			},
+			teamMembers: map[string][]string{"friends": {}, "idp-sync": {}},
+			maintainers: map[string][]string{"friends": {}, "idp-sync": {}},
+			repoPermissions: map[string][]github.Repo{
+				"friends": {
+					{Name: "pull-repo", Permissions: github.RepoPermissions{Pull: true}},
+					{Name: "website", Permissions: github.RepoPermissions{Admin: true}},
+					{Name: "sandbox-1", Permissions: github.RepoPermissions{Admin: true}},
+				},
+			},
+			repos: []github.FullRepo{{Repo: github.Repo{Name: "sandbox-1"}}},
+			expected: config.Config{
+				Config: org.Config{
+					Metadata: org.Metadata{
+						Name:                         &hello,
+						BillingEmail:                 &empty,
+						Company:                      &empty,
+						Email:                        &empty,
+						Description:                  &empty,
+						Location:                     &empty,
+						HasOrganizationProjects:      &no,
+						HasRepositoryProjects:        &no,
+						DefaultRepositoryPermission:  &perm,
+						MembersCanCreateRepositories: &yes,
+					},
+				},
+				Teams: config.NewTeams(map[string]org.Team{
+					"friends": {
+						TeamMetadata: org.TeamMetadata{
+							Description: &empty,
+							Privacy:     &pub,
+						},
+						Members:     []string{},
+						Maintainers: []string{},
+						Children:    map[string]org.Team{},
+						Repos:       map[string]github.RepoPermissionLevel{"pull-repo": github.Read},
+					},
+				}),
+				Admins:  config.NewMembers("admin"),
+				Repos:   map[string]config.Repo{},
+				Actions: &config.ActionsPolicy{EnabledRepositories: &none},
+				Ignore: &config.Patterns{
+					Teams:     []string{"idp-*"},
+					Repos:     []string{"sandbox-*"},
+					TeamRepos: []string{"friends/website"},
+				},
+			},
+		},
+		{
+			name:    "leaves out the teams of the ignore key",
+			ignored: config.Patterns{Repos: []string{"sandbox-*"}},
+			ignore:  &config.Patterns{Teams: []string{"idp-*"}},
+			meta: github.Organization{
+				Name:                         hello,
+				MembersCanCreateRepositories: yes,
+				DefaultRepositoryPermission:  string(perm),
+			},
+			admins: []string{"admin"},
+			teams: []github.Team{
+				{ID: 5, Slug: "friends", Name: "friends"},
+				{ID: 6, Slug: "idp-sync", Name: "idp-sync"},
+			},
+			teamMembers: map[string][]string{"friends": {}, "idp-sync": {}},
+			maintainers: map[string][]string{"friends": {}, "idp-sync": {}},
+			repoPermissions: map[string][]github.Repo{
+				"friends":  {{Name: "pull-repo", Permissions: github.RepoPermissions{Pull: true}}},
+				"idp-sync": {{Name: "pull-repo", Permissions: github.RepoPermissions{Admin: true}}},
+			},
+			expected: config.Config{
+				Config: org.Config{
+					Metadata: org.Metadata{
+						Name:                         &hello,
+						BillingEmail:                 &empty,
+						Company:                      &empty,
+						Email:                        &empty,
+						Description:                  &empty,
+						Location:                     &empty,
+						HasOrganizationProjects:      &no,
+						HasRepositoryProjects:        &no,
+						DefaultRepositoryPermission:  &perm,
+						MembersCanCreateRepositories: &yes,
+					},
+				},
+				Teams: config.NewTeams(map[string]org.Team{
+					"friends": {
+						TeamMetadata: org.TeamMetadata{
+							Description: &empty,
+							Privacy:     &pub,
+						},
+						Members:     []string{},
+						Maintainers: []string{},
+						Children:    map[string]org.Team{},
+						Repos:       map[string]github.RepoPermissionLevel{"pull-repo": github.Read},
+					},
+				}),
+				Admins:  config.NewMembers("admin"),
+				Repos:   map[string]config.Repo{},
+				Actions: &config.ActionsPolicy{EnabledRepositories: &none},
+				Ignore: &config.Patterns{
+					Teams: []string{"idp-*"},
+					Repos: []string{"sandbox-*"},
+				},
+			},
+		},
+	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1909,8 +3327,25 @@ func TestDumpOrgConfig(t *testing.T) {
 				maintainers:     tc.maintainers,
 				repoPermissions: tc.repoPermissions,
 				repos:           tc.repos,
+				orgHooks:        tc.orgHooks,
+				repoHooks:       tc.repoHooks,
+				collaborators:   tc.collaborators,
+				orgRoles:        tc.orgRoles,
+				orgRoleTeams:    tc.orgRoleTeams,
+				settings:        tc.settings,
+				idpGroups:       tc.idpGroups,
+				teamSettings:    tc.teamSettings,
+				assignments:     tc.assignments,
+			}
+			fc.fakeActionsClient = &tc.actions
+			if fc.orgPerms.EnabledRepositories == "" {
+				fc.orgPerms.EnabledRepositories = config.ActionsNone
+			}
+			ignored, err := IgnoredBy(tc.ignored, config.Config{Ignore: tc.ignore})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
-			actual, err := Dump(fc, orgName, tc.ignoreSecretTeams, "")
+			actual, err := Dump(fc, orgName, tc.ignoreSecretTeams, "", ignored)
 			switch {
 			case err != nil:
 				if !tc.err {
@@ -1930,6 +3365,7 @@ func TestDumpOrgConfig(t *testing.T) {
 }
 
 type fakeDumpClient struct {
+	*fakeActionsClient
 	name            string
 	members         []string
 	admins          []string
@@ -1939,6 +3375,39 @@ type fakeDumpClient struct {
 	maintainers     map[string][]string
 	repoPermissions map[string][]github.Repo
 	repos           []github.FullRepo
+	orgHooks        []github.Hook
+	repoHooks       map[string][]github.Hook
+	collaborators   map[string][]ghclient.Collaborator
+	orgRoles        []ghclient.OrgRole
+	orgRoleTeams    map[int][]github.Team
+	settings        ghclient.OrgSettings
+	idpGroups       map[string][]ghclient.IdPGroup
+	teamSettings    map[string]ghclient.TeamSettings
+	assignments     map[string]ghclient.ReviewAssignment
+}
+
+func (c fakeDumpClient) GetTeamSettings(org, teamSlug string) (*ghclient.TeamSettings, error) {
+	settings := c.teamSettings[teamSlug]
+	return &settings, nil
+}
+
+func (c fakeDumpClient) GetTeamReviewAssignment(org, teamSlug string) (*ghclient.ReviewAssignment, error) {
+	if c.assignments == nil {
+		return nil, errors.New("graphql is not available")
+	}
+	assignment := c.assignments[teamSlug]
+	return &assignment, nil
+}
+
+func (c fakeDumpClient) GetOrgSettings(org string) (*ghclient.OrgSettings, error) {
+	return &c.settings, nil
+}
+
+func (c fakeDumpClient) ListTeamIdPGroups(org, teamSlug string) ([]ghclient.IdPGroup, error) {
+	if c.idpGroups == nil {
+		return nil, errors.New("team synchronization is not enabled")
+	}
+	return c.idpGroups[teamSlug], nil
 }
 
 func (c fakeDumpClient) GetOrg(name string) (*github.Organization, error) {
@@ -2043,20 +3512,100 @@ func (c fakeDumpClient) BotUser() (*github.UserData, error) {
 	return &github.UserData{Login: "admin"}, nil
 }
 
-func fixup(ret *org.Config) {
+func (c fakeDumpClient) ListOrgHooks(org string) ([]github.Hook, error) {
+	return c.orgHooks, nil
+}
+
+func (c fakeDumpClient) ListRepoHooks(org, repo string) ([]github.Hook, error) {
+	return c.repoHooks[repo], nil
+}
+
+func (c fakeDumpClient) ListRepoCollaborators(org, repo string) ([]ghclient.Collaborator, error) {
+	return c.collaborators[repo], nil
+}
+
+func (c fakeDumpClient) ListOrgRoles(org string) ([]ghclient.OrgRole, error) {
+	return c.orgRoles, nil
+}
+
+func (c fakeDumpClient) ListOrgRoleTeams(org string, id int) ([]github.Team, error) {
+	return c.orgRoleTeams[id], nil
+}
+
+func fixup(ret *config.Config) {
 	if ret == nil {
 		return
 	}
-	sort.Strings(ret.Members)
-	sort.Strings(ret.Admins)
+	sortMembers(ret.Members)
+	sortMembers(ret.Admins)
 	for name, team := range ret.Teams {
-		sort.Strings(team.Members)
-		sort.Strings(team.Maintainers)
+		sortMembers(team.Members)
+		sortMembers(team.Maintainers)
 		sort.Strings(team.Previously)
 		ret.Teams[name] = team
 	}
 }
 
+func sortMembers(members []config.Member) {
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Login < members[j].Login
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	yes := true
+	contentType := "json"
+	snapshot := Snapshot{
+		Org:  "org",
+		Time: time.Date(2026, time.March, 4, 5, 6, 7, 0, time.UTC),
+		Config: config.Config{
+			Admins:  config.NewMembers("admin"),
+			Members: config.NewMembers("member", "Invited-Admin"),
+			Teams: config.NewTeams(map[string]org.Team{
+				"team": {Members: []string{"member"}},
+			}),
+			Repos: map[string]config.Repo{
+				"repo": {
+					Repo:     org.Repo{HasWiki: &yes},
+					Webhooks: []config.Webhook{{URL: "https://example.com/repo", ContentType: &contentType}},
+				},
+			},
+			Webhooks: []config.Webhook{{URL: "https://example.com/org", ContentType: &contentType}},
+		},
+		Invitations: []string{"invited", "invited-admin"},
+	}
+
+	path, err := snapshot.Write(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	if base := filepath.Base(path); base != "org-20260304T050607Z.yaml" {
+		t.Errorf("unexpected snapshot file name %s", base)
+	}
+	read, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if diff := cmp.Diff(&snapshot, read); diff != "" {
+		t.Errorf("snapshot changed after round trip (-want +got):\n%s", diff)
+	}
+
+	expected := config.Config{
+		Admins:  config.NewMembers("admin"),
+		Members: config.NewMembers("member", "Invited-Admin", "invited"),
+		Teams:   snapshot.Config.Teams,
+		Repos: map[string]config.Repo{
+			"repo": {Repo: org.Repo{HasWiki: &yes}},
+		},
+	}
+	if diff := cmp.Diff(expected, read.RollbackConfig()); diff != "" {
+		t.Errorf("unexpected rollback config (-want +got):\n%s", diff)
+	}
+	if len(read.Config.Members) != 2 || len(read.Config.Repos["repo"].Webhooks) != 1 {
+		t.Errorf("rollback config modified the snapshot: %+v", read.Config)
+	}
+}
+
 func TestOrgInvitations(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -2128,9 +3677,22 @@ func TestOrgInvitations(t *testing.T) {
 
 type fakeTeamRepoClient struct {
 	repos                            map[string][]github.Repo
+	roles                            map[string]map[string]string
 	failList, failUpdate, failRemove bool
 }
 
+func (c *fakeTeamRepoClient) ListTeamRepoRoles(org, teamSlug string) ([]ghclient.TeamRepoRole, error) {
+	var roles []ghclient.TeamRepoRole
+	for _, repo := range c.repos[teamSlug] {
+		role := c.roles[teamSlug][repo.Name]
+		if role == "" {
+			role = string(github.LevelFromPermissions(repo.Permissions))
+		}
+		roles = append(roles, ghclient.TeamRepoRole{Name: repo.Name, RoleName: role})
+	}
+	return roles, nil
+}
+
 func (c *fakeTeamRepoClient) ListTeamReposBySlug(org, teamSlug string) ([]github.Repo, error) {
 	if c.failList {
 		return nil, errors.New("injected failure to ListTeamRepos")
@@ -2144,6 +3706,18 @@ func (c *fakeTeamRepoClient) UpdateTeamRepoBySlug(org, teamSlug, repo string, pe
 	}
 
 	permissions := github.PermissionsFromTeamPermission(permission)
+	if base, custom := customRoleBases[string(permission)]; custom {
+		permissions = github.PermissionsFromTeamPermission(base)
+		if c.roles == nil {
+			c.roles = map[string]map[string]string{}
+		}
+		if c.roles[teamSlug] == nil {
+			c.roles[teamSlug] = map[string]string{}
+		}
+		c.roles[teamSlug][repo] = string(permission)
+	} else {
+		delete(c.roles[teamSlug], repo)
+	}
 	updated := false
 	for i, repository := range c.repos[teamSlug] {
 		if repository.Name == repo {
@@ -2171,10 +3745,14 @@ func (c *fakeTeamRepoClient) RemoveTeamRepoBySlug(org, teamSlug, repo string) er
 			break
 		}
 	}
+	delete(c.roles[teamSlug], repo)
 
 	return nil
 }
 
+// customRoleBases are the base roles of the custom repo roles of the tests.
+var customRoleBases = map[string]github.TeamPermission{"triage-plus": github.RepoTriage}
+
 func TestConfigureTeamRepos(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -2187,6 +3765,11 @@ func TestConfigureTeamRepos(t *testing.T) {
 		failRemove    bool
 		expected      map[string][]github.Repo
 		expectedErr   bool
+		protected     protection
+		ignored       config.Patterns
+		customRoles   map[string]github.RepoPermissionLevel
+		existingRoles map[string]map[string]string
+		expectedRoles map[string]map[string]string
 	}{
 		{
 			name:        "githubTeams cache not containing team errors",
@@ -2295,6 +3878,43 @@ func TestConfigureTeamRepos(t *testing.T) {
 				{Name: "admin", Permissions: github.RepoPermissions{Pull: true}},
 			}},
 		},
+		{
+			name:        "permissions on protected repos are not removed nor lowered",
+			githubTeams: map[string]github.Team{"team": {ID: 1, Slug: "team"}},
+			teamName:    "team",
+			team: org.Team{
+				Repos: map[string]github.RepoPermissionLevel{
+					"admin": github.Read,
+					"write": github.Admin,
+				},
+			},
+			existingRepos: map[string][]github.Repo{"team": {
+				{Name: "read", Permissions: github.RepoPermissions{Pull: true}},
+				{Name: "write", Permissions: github.RepoPermissions{Pull: true, Triage: true, Push: true}},
+				{Name: "admin", Permissions: github.RepoPermissions{Pull: true, Triage: true, Push: true, Maintain: true, Admin: true}},
+			}},
+			expected: map[string][]github.Repo{"team": {
+				{Name: "read", Permissions: github.RepoPermissions{Pull: true}},
+				{Name: "write", Permissions: github.RepoPermissions{Pull: true, Triage: true, Push: true, Maintain: true, Admin: true}},
+				{Name: "admin", Permissions: github.RepoPermissions{Pull: true, Triage: true, Push: true, Maintain: true, Admin: true}},
+			}},
+			protected: protection{repos: sets.New[string]("read", "write", "admin")},
+		},
+		{
+			name:        "permissions of protected teams are not removed",
+			githubTeams: map[string]github.Team{"team": {ID: 1, Slug: "team"}},
+			teamName:    "team",
+			team: org.Team{
+				Repos: map[string]github.RepoPermissionLevel{},
+			},
+			existingRepos: map[string][]github.Repo{"team": {
+				{Name: "read", Permissions: github.RepoPermissions{Pull: true}},
+			}},
+			expected: map[string][]github.Repo{"team": {
+				{Name: "read", Permissions: github.RepoPermissions{Pull: true}},
+			}},
+			protected: protection{teams: sets.New[string]("team")},
+		},
 		{
 			name:        "failed update errors",
 			failUpdate:  true,
@@ -2373,20 +3993,99 @@ func TestConfigureTeamRepos(t *testing.T) {
 				{Name: "needs-deletion", Permissions: github.RepoPermissions{Pull: true}},
 			}},
 			expectedErr: true,
+		}, {
+			name:        "ignored repos and team repo permissions are left alone",
+			githubTeams: map[string]github.Team{"team": {ID: 1, Slug: "team"}},
+			teamName:    "team",
+			team: org.Team{
+				Repos: map[string]github.RepoPermissionLevel{
+					"sandbox-1": github.Admin,
+					"website":   github.Admin,
+				},
+			},
+			ignored: config.Patterns{
+				Repos:     []string{"sandbox-*"},
+				TeamRepos: []string{"team/website", "team/idp-*"},
+			},
+			existingRepos: map[string][]github.Repo{"team": {
+				{Name: "idp-owned", Permissions: github.RepoPermissions{Pull: true}},
+				{Name: "website", Permissions: github.RepoPermissions{Pull: true}},
+			}},
+			expected: map[string][]github.Repo{"team": {
+				{Name: "idp-owned", Permissions: github.RepoPermissions{Pull: true}},
+				{Name: "website", Permissions: github.RepoPermissions{Pull: true}},
+			}},
+		},
+		{
+			name:        "custom repo roles are granted by name",
+			githubTeams: map[string]github.Team{"team": {ID: 1, Slug: "team"}},
+			teamName:    "team",
+			team: org.Team{
+				Repos: map[string]github.RepoPermissionLevel{
+					"new":      "triage-plus",
+					"upgraded": "triage-plus",
+					"kept":     "triage-plus",
+					"reverted": github.Triage,
+				},
+			},
+			customRoles: map[string]github.RepoPermissionLevel{"triage-plus": github.Triage},
+			existingRepos: map[string][]github.Repo{"team": {
+				{Name: "upgraded", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+				{Name: "kept", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+				{Name: "reverted", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+			}},
+			existingRoles: map[string]map[string]string{"team": {"kept": "triage-plus", "reverted": "triage-plus"}},
+			expected: map[string][]github.Repo{"team": {
+				{Name: "upgraded", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+				{Name: "kept", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+				{Name: "reverted", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+				{Name: "new", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+			}},
+			expectedRoles: map[string]map[string]string{"team": {"kept": "triage-plus", "upgraded": "triage-plus", "new": "triage-plus"}},
+		},
+		{
+			name:        "unknown roles are not granted",
+			githubTeams: map[string]github.Team{"team": {ID: 1, Slug: "team"}},
+			teamName:    "team",
+			team: org.Team{
+				Repos: map[string]github.RepoPermissionLevel{"repo": "unknown"},
+			},
+			existingRepos: map[string][]github.Repo{},
+			expected:      map[string][]github.Repo{},
+			expectedErr:   true,
+		},
+		{
+			name:        "protected custom repo roles are not lowered",
+			githubTeams: map[string]github.Team{"team": {ID: 1, Slug: "team"}},
+			teamName:    "team",
+			team: org.Team{
+				Repos: map[string]github.RepoPermissionLevel{"repo": github.Read},
+			},
+			protected:   protection{teams: sets.New("team")},
+			customRoles: map[string]github.RepoPermissionLevel{"triage-plus": github.Triage},
+			existingRepos: map[string][]github.Repo{"team": {
+				{Name: "repo", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+			}},
+			existingRoles: map[string]map[string]string{"team": {"repo": "triage-plus"}},
+			expected: map[string][]github.Repo{"team": {
+				{Name: "repo", Permissions: github.RepoPermissions{Pull: true, Triage: true}},
+			}},
+			expectedRoles: map[string]map[string]string{"team": {"repo": "triage-plus"}},
 		},
 	}
 
 	for _, testCase := range testCases {
 		client := fakeTeamRepoClient{
+			roles:      testCase.existingRoles,
 			repos:      testCase.existingRepos,
 			failList:   testCase.failList,
 			failUpdate: testCase.failUpdate,
 			failRemove: testCase.failRemove,
 		}
 
-		opts := root.Options{}
+		opts := root.Options{Ignored: testCase.ignored}
 
-		err := configureTeamRepos(opts, &client, testCase.githubTeams, testCase.teamName, "org", testCase.team)
+		err := configureTeamRepos(opts, &client, testCase.githubTeams, testCase.teamName, "org", testCase.team, testCase.protected, testCase.customRoles)
 		if err == nil && testCase.expectedErr {
 			t.Errorf("%s: expected an error but got none", testCase.name)
 		}
@@ -2396,58 +4095,385 @@ func TestConfigureTeamRepos(t *testing.T) {
 		if diff := cmp.Diff(client.repos, testCase.expected); diff != "" {
 			t.Errorf("%s: got incorrect team repos: %s", testCase.name, diff)
 		}
+		if testCase.customRoles != nil {
+			if diff := cmp.Diff(testCase.expectedRoles, client.roles); diff != "" {
+				t.Errorf("%s: got incorrect team repo roles: %s", testCase.name, diff)
+			}
+		}
 	}
 }
 
-type fakeRepoClient struct {
-	t     *testing.T
-	repos map[string]github.FullRepo
+type fakeCustomRepoRoleClient struct {
+	roles   []ghclient.CustomRepoRole
+	changed []string
 }
 
-func (f fakeRepoClient) GetRepo(owner, name string) (github.FullRepo, error) {
-	repo, ok := f.repos[name]
-	if !ok {
-		return repo, fmt.Errorf("repo not found")
-	}
-	return repo, nil
+func (c *fakeCustomRepoRoleClient) ListCustomRepoRoles(org string) ([]ghclient.CustomRepoRole, error) {
+	return c.roles, nil
 }
 
-func (f fakeRepoClient) GetRepos(orgName string, isUser bool) ([]github.Repo, error) {
-	if orgName == "fail" {
-		return nil, fmt.Errorf("injected GetRepos failure")
-	}
-
-	repos := make([]github.Repo, 0, len(f.repos))
-	for _, repo := range f.repos {
-		repos = append(repos, repo.Repo)
-	}
+func (c *fakeCustomRepoRoleClient) CreateCustomRepoRole(org string, role ghclient.CustomRepoRole) error {
+	c.changed = append(c.changed, "create "+role.Name)
+	return nil
+}
 
-	// sort for deterministic output
-	sort.Slice(repos, func(i, j int) bool {
-		return repos[i].Name < repos[j].Name
-	})
+func (c *fakeCustomRepoRoleClient) UpdateCustomRepoRole(org string, role ghclient.CustomRepoRole) error {
+	c.changed = append(c.changed, fmt.Sprintf("update %d %s", role.ID, role.Name))
+	return nil
+}
 
-	return repos, nil
+func (c *fakeCustomRepoRoleClient) DeleteCustomRepoRole(org string, id int) error {
+	c.changed = append(c.changed, fmt.Sprintf("delete %d", id))
+	return nil
 }
 
-func (f fakeRepoClient) CreateRepo(owner string, isUser bool, repoReq github.RepoCreateRequest) (*github.FullRepo, error) {
-	if *repoReq.Name == "fail" {
-		return nil, fmt.Errorf("injected CreateRepo failure")
+func TestConfigureCustomRepoRoles(t *testing.T) {
+	description := "Triage and label"
+	existing := []ghclient.CustomRepoRole{
+		{ID: 1, Name: "triage-plus", Description: description, BaseRole: "triage", Permissions: []string{"remove_label", "add_label"}},
+		{ID: 2, Name: "write-plus", BaseRole: "write", Permissions: []string{"manage_webhooks"}},
+		{ID: 3, Name: "legacy", BaseRole: "read"},
 	}
-
-	if _, hasRepo := f.repos[*repoReq.Name]; hasRepo {
-		f.t.Errorf("CreateRepo() called on repo that already exists")
-		return nil, fmt.Errorf("CreateRepo() called on repo that already exists")
+	testCases := []struct {
+		name      string
+		opt       root.Options
+		want      map[string]config.CustomRepoRole
+		protected []string
+		expected  []string
+	}{
+		{
+			name: "roles are not managed when nil",
+		},
+		{
+			name: "roles are created, updated and deleted",
+			want: map[string]config.CustomRepoRole{
+				"triage-plus": {Description: &description, BaseRole: github.Triage, Permissions: []string{"add_label", "remove_label"}},
+				"write-plus":  {BaseRole: github.Write, Permissions: []string{"manage_webhooks", "edit_repo_metadata"}},
+				"read-plus":   {BaseRole: github.Read, Permissions: []string{"view_secret_scanning_alerts"}},
+			},
+			expected: []string{"create read-plus", "update 2 write-plus", "delete 3"},
+		},
+		{
+			name:      "protected roles are not deleted",
+			want:      map[string]config.CustomRepoRole{},
+			protected: []string{"Legacy", "write-plus"},
+			expected:  []string{"delete 1"},
+		},
+		{
+			name: "unapproved deletions are skipped",
+			opt: root.Options{
+				RequireApproval: true,
+				ApprovedChanges: []string{changeID(changeRoleDelete, fakeOrg, "legacy")},
+			},
+			want:     map[string]config.CustomRepoRole{},
+			expected: []string{"delete 3"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeCustomRepoRoleClient{roles: existing}
+			protected := protection{repoRoles: normalizeNames(tc.protected)}
+			if err := configureCustomRepoRoles(tc.opt, client, fakeOrg, tc.want, protected); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, client.changed); diff != "" {
+				t.Errorf("Wrong changes (-want +got):\n%s", diff)
+			}
+		})
 	}
-
-	repo := repoReq.ToRepo()
-	f.repos[*repoReq.Name] = *repo
-
-	return repo, nil
 }
 
-func (f fakeRepoClient) UpdateRepo(owner, name string, want github.RepoUpdateRequest) (*github.FullRepo, error) {
-	if name == "fail" {
+func TestCustomRepoRoles(t *testing.T) {
+	client := &fakeCustomRepoRoleClient{roles: []ghclient.CustomRepoRole{{ID: 1, Name: "org-role", BaseRole: "write"}}}
+	testCases := []struct {
+		name     string
+		config   config.Config
+		expected map[string]github.RepoPermissionLevel
+	}{
+		{
+			name:     "org is not queried without custom roles",
+			config:   config.Config{Teams: map[string]config.Team{"team": {Repos: map[string]config.TeamRepo{"repo": {Permission: github.Write}}}}},
+			expected: map[string]github.RepoPermissionLevel{},
+		},
+		{
+			name: "declared roles are used",
+			config: config.Config{
+				CustomRepoRoles: map[string]config.CustomRepoRole{"declared": {BaseRole: github.Read}},
+				Teams:           map[string]config.Team{"team": {Repos: map[string]config.TeamRepo{"repo": {Permission: "declared"}}}},
+			},
+			expected: map[string]github.RepoPermissionLevel{"declared": github.Read},
+		},
+		{
+			name: "roles of the org are used when undeclared",
+			config: config.Config{
+				Teams: map[string]config.Team{"team": {Children: map[string]config.Team{"child": {Repos: map[string]config.TeamRepo{"repo": {Permission: "org-role"}}}}}},
+			},
+			expected: map[string]github.RepoPermissionLevel{"org-role": github.Write},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := customRepoRoles(client, fakeOrg, tc.config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Wrong roles (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeOrgRoleClient struct {
+	teams   map[int][]github.Team
+	changed []string
+}
+
+func (c *fakeOrgRoleClient) ListOrgRoles(org string) ([]ghclient.OrgRole, error) {
+	return []ghclient.OrgRole{{ID: 138, Name: "security_manager"}, {ID: 8132, Name: "all_repo_read"}}, nil
+}
+
+func (c *fakeOrgRoleClient) ListOrgRoleTeams(org string, id int) ([]github.Team, error) {
+	return c.teams[id], nil
+}
+
+func (c *fakeOrgRoleClient) AssignOrgRoleTeam(org, teamSlug string, id int) error {
+	c.changed = append(c.changed, fmt.Sprintf("assign %d %s", id, teamSlug))
+	return nil
+}
+
+func (c *fakeOrgRoleClient) RemoveOrgRoleTeam(org, teamSlug string, id int) error {
+	c.changed = append(c.changed, fmt.Sprintf("remove %d %s", id, teamSlug))
+	return nil
+}
+
+func TestConfigureOrgRoles(t *testing.T) {
+	githubTeams := map[string]github.Team{
+		"Security": {ID: 1, Slug: "security", Name: "Security"},
+		"auditors": {ID: 2, Slug: "auditors", Name: "auditors"},
+	}
+	testCases := []struct {
+		name     string
+		want     map[string][]string
+		teams    map[int][]github.Team
+		readOnly config.Patterns
+		expected []string
+		err      bool
+	}{
+		{
+			name:  "undeclared roles are left alone",
+			teams: map[int][]github.Team{138: {{Slug: "others", Name: "others"}}},
+		},
+		{
+			name: "declared roles are assigned to the declared teams only",
+			want: map[string][]string{"security_manager": {"Security", "auditors"}, "all_repo_read": {}},
+			teams: map[int][]github.Team{
+				138:  {{Slug: "security", Name: "Security"}, {Slug: "others", Name: "others"}},
+				8132: {{Slug: "auditors", Name: "auditors"}},
+			},
+			expected: []string{"remove 8132 auditors", "remove 138 others", "assign 138 auditors"},
+		},
+		{
+			name:     "read-only teams are left alone",
+			want:     map[string][]string{"security_manager": {"Security"}},
+			teams:    map[int][]github.Team{138: {{Slug: "legacy", Name: "legacy"}}},
+			readOnly: config.Patterns{Teams: []string{"legacy", "Security"}},
+		},
+		{
+			name: "unknown roles and teams fail",
+			want: map[string][]string{"billing_manager": {"Security"}, "security_manager": {"missing"}},
+			err:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeOrgRoleClient{teams: tc.teams}
+			opt := root.Options{ReadOnly: tc.readOnly}
+			err := configureOrgRoles(opt, client, fakeOrg, tc.want, githubTeams)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("Failed to receive error")
+			}
+			if diff := cmp.Diff(tc.expected, client.changed); diff != "" {
+				t.Errorf("Wrong changes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeCollaboratorClient struct {
+	collaborators map[string][]ghclient.Collaborator
+	invitations   map[string][]ghclient.RepoInvitation
+	changed       []string
+}
+
+func (c *fakeCollaboratorClient) ListRepoCollaborators(org, repo string) ([]ghclient.Collaborator, error) {
+	collaborators, ok := c.collaborators[repo]
+	if !ok {
+		return nil, &ghclient.RequestError{StatusCode: http.StatusNotFound}
+	}
+	return collaborators, nil
+}
+
+func (c *fakeCollaboratorClient) ListRepoInvitations(org, repo string) ([]ghclient.RepoInvitation, error) {
+	return c.invitations[repo], nil
+}
+
+func (c *fakeCollaboratorClient) SetRepoCollaborator(org, repo, user, role string) error {
+	c.changed = append(c.changed, fmt.Sprintf("set %s %s %s", repo, user, role))
+	return nil
+}
+
+func (c *fakeCollaboratorClient) RemoveRepoCollaborator(org, repo, user string) error {
+	c.changed = append(c.changed, fmt.Sprintf("remove %s %s", repo, user))
+	return nil
+}
+
+func TestConfigureCollaborators(t *testing.T) {
+	existing := map[string][]ghclient.Collaborator{
+		"repo":  {{Login: "Keep", RoleName: "write"}, {Login: "promote", RoleName: "read"}, {Login: "drop", RoleName: "admin"}},
+		"infra": {{Login: "ops", RoleName: "maintain"}, {Login: "old", RoleName: "read"}},
+	}
+	testCases := []struct {
+		name      string
+		opt       root.Options
+		repos     map[string]config.Repo
+		invited   map[string][]ghclient.RepoInvitation
+		protected protection
+		expected  []string
+		err       bool
+	}{
+		{
+			name:  "collaborators are not managed when nil",
+			repos: map[string]config.Repo{"repo": {}},
+		},
+		{
+			name: "collaborators are granted their role and undeclared ones removed",
+			repos: map[string]config.Repo{"repo": {Collaborators: map[string]github.RepoPermissionLevel{
+				"keep":    github.Write,
+				"promote": "triage-plus",
+				"New":     github.Read,
+			}}},
+			expected: []string{"set repo New read", "set repo promote triage-plus", "remove repo drop"},
+		},
+		{
+			name: "invited collaborators are not invited again",
+			repos: map[string]config.Repo{"repo": {Collaborators: map[string]github.RepoPermissionLevel{
+				"keep":    github.Write,
+				"promote": github.Read,
+				"drop":    github.Admin,
+				"invited": github.Read,
+			}}},
+			invited: map[string][]ghclient.RepoInvitation{"repo": {{Invitee: github.User{Login: "Invited"}}}},
+		},
+		{
+			name: "protected collaborators are neither removed nor demoted",
+			repos: map[string]config.Repo{
+				"repo":  {Collaborators: map[string]github.RepoPermissionLevel{"keep": github.Read, "promote": github.Read}},
+				"infra": {Collaborators: map[string]github.RepoPermissionLevel{"ops": github.Read}},
+			},
+			protected: protection{users: sets.New[string]("keep", "drop"), repos: sets.New[string]("infra")},
+		},
+		{
+			name:  "read-only repos are left alone",
+			opt:   root.Options{ReadOnly: config.Patterns{Repos: []string{"repo"}}},
+			repos: map[string]config.Repo{"repo": {Collaborators: map[string]github.RepoPermissionLevel{}}},
+		},
+		{
+			name:  "missing repos are skipped in dry-run",
+			repos: map[string]config.Repo{"new": {Collaborators: map[string]github.RepoPermissionLevel{"someone": github.Read}}},
+		},
+		{
+			name:  "missing repos fail with --confirm",
+			opt:   root.Options{Confirm: true},
+			repos: map[string]config.Repo{"new": {Collaborators: map[string]github.RepoPermissionLevel{"someone": github.Read}}},
+			err:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeCollaboratorClient{collaborators: existing, invitations: tc.invited}
+			err := configureCollaborators(tc.opt, client, fakeOrg, config.Config{Repos: tc.repos}, tc.protected)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("Failed to receive error")
+			}
+			if diff := cmp.Diff(tc.expected, client.changed); diff != "" {
+				t.Errorf("Wrong changes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeRepoClient struct {
+	*fakeActionsClient
+	t     *testing.T
+	repos map[string]github.FullRepo
+	ids   map[string]int
+}
+
+func (f fakeRepoClient) ListOrgRepoIDs(org string) ([]ghclient.RepoID, error) {
+	var ids []ghclient.RepoID
+	for key, id := range f.ids {
+		if repo, ok := f.repos[key]; ok {
+			ids = append(ids, ghclient.RepoID{ID: id, Name: repo.Name})
+		}
+	}
+	return ids, nil
+}
+
+func (f fakeRepoClient) GetRepo(owner, name string) (github.FullRepo, error) {
+	repo, ok := f.repos[name]
+	if !ok {
+		return repo, fmt.Errorf("repo not found")
+	}
+	return repo, nil
+}
+
+func (f fakeRepoClient) GetRepos(orgName string, isUser bool) ([]github.Repo, error) {
+	if orgName == "fail" {
+		return nil, fmt.Errorf("injected GetRepos failure")
+	}
+
+	repos := make([]github.Repo, 0, len(f.repos))
+	for _, repo := range f.repos {
+		repos = append(repos, repo.Repo)
+	}
+
+	// sort for deterministic output
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+
+	return repos, nil
+}
+
+func (f fakeRepoClient) CreateRepo(owner string, isUser bool, repoReq github.RepoCreateRequest) (*github.FullRepo, error) {
+	if *repoReq.Name == "fail" {
+		return nil, fmt.Errorf("injected CreateRepo failure")
+	}
+
+	if _, hasRepo := f.repos[*repoReq.Name]; hasRepo {
+		f.t.Errorf("CreateRepo() called on repo that already exists")
+		return nil, fmt.Errorf("CreateRepo() called on repo that already exists")
+	}
+
+	repo := repoReq.ToRepo()
+	f.repos[*repoReq.Name] = *repo
+
+	return repo, nil
+}
+
+func (f fakeRepoClient) UpdateRepo(owner, name string, want github.RepoUpdateRequest) (*github.FullRepo, error) {
+	if name == "fail" {
 		return nil, fmt.Errorf("injected UpdateRepo failure")
 	}
 	if want.Archived != nil && !*want.Archived {
@@ -2496,10 +4522,57 @@ func (f fakeRepoClient) UpdateRepo(owner, name string, want github.RepoUpdateReq
 	return &have, nil
 }
 
+func (f fakeRepoClient) DeleteRepo(owner, name string) error {
+	if name == "fail" {
+		return fmt.Errorf("injected DeleteRepo failure")
+	}
+	if _, exists := f.repos[name]; !exists {
+		f.t.Errorf("DeleteRepo() called on repo that does not exist")
+		return fmt.Errorf("DeleteRepo() called on repo that does not exist")
+	}
+	delete(f.repos, name)
+	return nil
+}
+
+func (f fakeRepoClient) GenerateRepo(templateOwner, templateRepo string, req ghclient.GenerateRepoRequest) (*github.FullRepo, error) {
+	if templateRepo == "fail" {
+		return nil, fmt.Errorf("injected GenerateRepo failure")
+	}
+	return f.CreateRepo(req.Owner, false, github.RepoCreateRequest{
+		RepoRequest: github.RepoRequest{Name: &req.Name, Description: req.Description, Private: req.Private},
+	})
+}
+
+func (f fakeRepoClient) ForkRepo(owner, repo string, req ghclient.ForkRepoRequest) (*github.FullRepo, error) {
+	if repo == "fail" {
+		return nil, fmt.Errorf("injected ForkRepo failure")
+	}
+	if repo == "pending" {
+		// GitHub has accepted the fork but not created it yet.
+		return &github.FullRepo{Repo: github.Repo{Name: req.Name}}, nil
+	}
+	return f.CreateRepo(req.Organization, false, github.RepoCreateRequest{
+		RepoRequest: github.RepoRequest{Name: &req.Name},
+	})
+}
+
+func (f fakeRepoClient) TransferRepo(owner, name, newOwner string) error {
+	if name == "fail" {
+		return fmt.Errorf("injected TransferRepo failure")
+	}
+	if _, exists := f.repos[name]; !exists {
+		f.t.Errorf("TransferRepo() called on repo that does not exist")
+		return fmt.Errorf("TransferRepo() called on repo that does not exist")
+	}
+	delete(f.repos, name)
+	return nil
+}
+
 func makeFakeRepoClient(t *testing.T, repos ...github.FullRepo) fakeRepoClient {
 	fc := fakeRepoClient{
-		repos: make(map[string]github.FullRepo, len(repos)),
-		t:     t,
+		fakeActionsClient: &fakeActionsClient{},
+		repos:             make(map[string]github.FullRepo, len(repos)),
+		t:                 t,
 	}
 	for _, repo := range repos {
 		fc.repos[repo.Name] = repo
@@ -2524,9 +4597,9 @@ func TestConfigureRepos(t *testing.T) {
 
 	newName := "new"
 	newDescription := "A new repository."
-	newConfigRepo := org.Repo{
+	newConfigRepo := config.Repo{Repo: org.Repo{
 		Description: &newDescription,
-	}
+	}}
 	newRepo := github.Repo{
 		Name:        newName,
 		Description: newDescription,
@@ -2537,12 +4610,18 @@ func TestConfigureRepos(t *testing.T) {
 		Name: fail,
 	}
 
+	templateName := "upstream/template"
+	failTemplateName := "upstream/fail"
+	pendingName := "upstream/pending"
+
 	testCases := []struct {
 		description     string
 		opts            root.Options
-		orgConfig       org.Config
+		orgConfig       config.Config
 		orgNameOverride string
 		repos           []github.FullRepo
+		repoIDs         map[string]int
+		protected       protection
 
 		expectError   bool
 		expectedRepos []github.Repo
@@ -2553,22 +4632,22 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "survives nil repos config",
-			orgConfig: org.Config{
+			orgConfig: config.Config{
 				Repos: nil,
 			},
 			expectedRepos: []github.Repo{},
 		},
 		{
 			description: "survives empty repos config",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{},
 			},
 			expectedRepos: []github.Repo{},
 		},
 		{
 			description: "nonexistent repo is created",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
 					newName: newConfigRepo,
 				},
 			},
@@ -2579,8 +4658,8 @@ func TestConfigureRepos(t *testing.T) {
 		{
 			description:     "GetRepos failure is propagated",
 			orgNameOverride: "fail",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
 					newName: newConfigRepo,
 				},
 			},
@@ -2591,8 +4670,8 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "CreateRepo failure is propagated",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
 					fail: newConfigRepo,
 				},
 			},
@@ -2603,8 +4682,8 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "duplicate repo names different only by case are detected",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
 					"repo": newConfigRepo,
 					"REPO": newConfigRepo,
 				},
@@ -2616,8 +4695,8 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "existing repo is updated",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
 					oldName: newConfigRepo,
 				},
 			},
@@ -2632,8 +4711,8 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "UpdateRepo failure is propagated",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
 					"fail": newConfigRepo,
 				},
 			},
@@ -2647,9 +4726,9 @@ func TestConfigureRepos(t *testing.T) {
 			// Archived repositories are read-only, and updates fail with 403:
 			// "Repository was archived so is read-only."
 			description: "request to unarchive a repo fails, repo is read-only",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Archived: &no, Description: &updated},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Archived: &no, Description: &updated}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Archived: true, Description: "OLD"}}},
@@ -2662,9 +4741,9 @@ func TestConfigureRepos(t *testing.T) {
 			// Archived repositories are read-only, and updates fail with 403:
 			// "Repository was archived so is read-only."
 			description: "no field changes on archived repo",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Archived: &yes, Description: &updated},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Archived: &yes, Description: &updated}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Archived: true, Description: "OLD"}}},
@@ -2673,9 +4752,9 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "request to archive repo fails when not allowed, but updates other fields",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Archived: &yes, Description: &updated},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Archived: &yes, Description: &updated}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Archived: false, Description: "OLD"}}},
@@ -2687,9 +4766,9 @@ func TestConfigureRepos(t *testing.T) {
 			opts: root.Options{
 				AllowRepoArchival: true,
 			},
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Archived: &yes},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Archived: &yes}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Archived: false}}},
@@ -2697,9 +4776,9 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "request to publish a private repo fails when not allowed, but updates other fields",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Private: &no, Description: &updated},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Private: &no, Description: &updated}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Private: true, Description: "OLD"}}},
@@ -2711,19 +4790,109 @@ func TestConfigureRepos(t *testing.T) {
 			opts: root.Options{
 				AllowRepoPublish: true,
 			},
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Private: &no},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Private: &no}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Private: true}}},
 			expectedRepos: []github.Repo{{Name: oldName, Private: false}},
 		},
+		{
+			description: "unapproved high-risk changes are skipped, other fields are updated",
+			opts: root.Options{
+				AllowRepoArchival: true,
+				AllowRepoPublish:  true,
+				RequireApproval:   true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Private: &no, Description: &updated}},
+					newName: {Repo: org.Repo{Archived: &yes}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName, Private: true, Description: "OLD"}},
+				{Repo: github.Repo{Name: newName}},
+			},
+			expectedRepos: []github.Repo{
+				{Name: newName},
+				{Name: oldName, Private: true, Description: updated},
+			},
+		},
+		{
+			description: "read-only repos are not changed and read-only or ignored repos are not archived",
+			opts: root.Options{
+				AllowRepoArchival: true,
+				UnmanagedRepos:    root.UnmanagedReposArchive,
+				ReadOnly:          config.Patterns{Repos: []string{"old"}},
+				Ignored:           config.Patterns{Repos: []string{"sandbox-*"}},
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Description: &updated}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName, Description: "OLD"}},
+				{Repo: github.Repo{Name: "sandbox-1"}},
+				{Repo: github.Repo{Name: "unmanaged"}},
+			},
+			expectedRepos: []github.Repo{
+				{Name: oldName, Description: "OLD"},
+				{Name: "sandbox-1"},
+				{Name: "unmanaged", Archived: true},
+			},
+		},
+		{
+			description: "previous names of read-only repos are not archived",
+			opts: root.Options{
+				AllowRepoArchival: true,
+				UnmanagedRepos:    root.UnmanagedReposArchive,
+				ReadOnly:          config.Patterns{Repos: []string{newName}},
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}, Description: &updated}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName, Description: "OLD"}},
+				{Repo: github.Repo{Name: "unmanaged"}},
+			},
+			expectedRepos: []github.Repo{
+				{Name: oldName, Description: "OLD"},
+				{Name: "unmanaged", Archived: true},
+			},
+		},
+		{
+			description: "approved high-risk changes are applied",
+			opts: root.Options{
+				AllowRepoArchival: true,
+				AllowRepoPublish:  true,
+				RequireApproval:   true,
+				ApprovedChanges:   []string{"repo.publish:test-org/old", "repo.archive:test-org/new"},
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Private: &no}},
+					newName: {Repo: org.Repo{Archived: &yes}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName, Private: true}},
+				{Repo: github.Repo{Name: newName}},
+			},
+			expectedRepos: []github.Repo{
+				{Name: newName, Archived: true},
+				{Name: oldName},
+			},
+		},
 		{
 			description: "renaming a repo is successful",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					newName: {Previously: []string{oldName}},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Description: "renamed repo"}}},
@@ -2731,9 +4900,9 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "renaming a repo by just changing case is successful",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					"repo": {Previously: []string{"REPO"}},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					"repo": {Repo: org.Repo{Previously: []string{"REPO"}}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: "REPO", Description: "renamed repo"}}},
@@ -2741,10 +4910,10 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "dup between a repo name and a previous name is detected",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					newName: {Previously: []string{oldName}},
-					oldName: {Description: &newDescription},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}}},
+					oldName: {Repo: org.Repo{Description: &newDescription}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Description: "this repo shall not be touched"}}},
@@ -2753,10 +4922,10 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "dup between two previous names is detected",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					"wants-projects": {Previously: []string{oldName}, HasProjects: &yes, HasWiki: &no},
-					"wants-wiki":     {Previously: []string{oldName}, HasProjects: &no, HasWiki: &yes},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					"wants-projects": {Repo: org.Repo{Previously: []string{oldName}, HasProjects: &yes, HasWiki: &no}},
+					"wants-wiki":     {Repo: org.Repo{Previously: []string{oldName}, HasProjects: &no, HasWiki: &yes}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: oldName, Description: "this repo shall not be touched"}}},
@@ -2765,9 +4934,9 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "error detected when both a repo and a repo of its previous name exist",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					newName: {Previously: []string{oldName}, Description: &newDescription},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}, Description: &newDescription}},
 				},
 			},
 			repos: []github.FullRepo{
@@ -2782,9 +4951,9 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "error detected when multiple previous repos exist",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					newName: {Previously: []string{oldName, "even-older"}, Description: &newDescription},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName, "even-older"}, Description: &newDescription}},
 				},
 			},
 			repos: []github.FullRepo{
@@ -2799,9 +4968,9 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "repos are renamed to defined case even without explicit `previously` field",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					"CamelCase": {Description: &newDescription},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					"CamelCase": {Repo: org.Repo{Description: &newDescription}},
 				},
 			},
 			repos:         []github.FullRepo{{Repo: github.Repo{Name: "CAMELCASE", Description: newDescription}}},
@@ -2809,48 +4978,395 @@ func TestConfigureRepos(t *testing.T) {
 		},
 		{
 			description: "avoid creating archived repo",
-			orgConfig: org.Config{
-				Repos: map[string]org.Repo{
-					oldName: {Archived: &yes},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Repo: org.Repo{Archived: &yes}},
 				},
 			},
 			repos:         []github.FullRepo{},
 			expectError:   true,
 			expectedRepos: []github.Repo{},
 		},
-	}
-	for _, tc := range testCases {
-		t.Run(tc.description, func(t *testing.T) {
-			fc := makeFakeRepoClient(t, tc.repos...)
-			var err error
-			if len(tc.orgNameOverride) > 0 {
-				err = configureRepos(tc.opts, fc, tc.orgNameOverride, tc.orgConfig)
-			} else {
-				err = configureRepos(tc.opts, fc, orgName, tc.orgConfig)
-			}
-			if err != nil && !tc.expectError {
-				t.Errorf("%s: unexpected error: %v", tc.description, err)
-			}
-			if err == nil && tc.expectError {
-				t.Errorf("%s: expected error, got none", tc.description)
-			}
-
-			reposAfter, err := fc.GetRepos(orgName, isOrg)
-			if err != nil {
-				t.Fatalf("%s: unexpected GetRepos error: %v", tc.description, err)
-			}
-			if !reflect.DeepEqual(reposAfter, tc.expectedRepos) {
-				t.Errorf("%s: unexpected repos after configureRepos():\n%s", tc.description, cmp.Diff(reposAfter, tc.expectedRepos))
-			}
-		})
-	}
+		{
+			description: "nonexistent repo is generated from its template and updated",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {
+						Repo:     org.Repo{Description: &newDescription, HasWiki: &yes},
+						OnCreate: &config.RepoCreateOptions{Template: &templateName},
+					},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{{Name: newName, Description: newDescription, HasWiki: true}, oldRepo},
+		},
+		{
+			description: "GenerateRepo failure is propagated",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {OnCreate: &config.RepoCreateOptions{Template: &failTemplateName}},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectError:   true,
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "nonexistent repo is forked and updated",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {
+						Repo:     org.Repo{Description: &newDescription, HasWiki: &yes},
+						OnCreate: &config.RepoCreateOptions{ForkOf: &templateName},
+					},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{{Name: newName, Description: newDescription, HasWiki: true}, oldRepo},
+		},
+		{
+			description: "fork is updated once ready",
+			opts:        root.Options{Confirm: true},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {
+						Repo:     org.Repo{Description: &newDescription, HasWiki: &yes},
+						OnCreate: &config.RepoCreateOptions{ForkOf: &templateName},
+					},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{{Name: newName, Description: newDescription, HasWiki: true}, oldRepo},
+		},
+		{
+			description: "fork not ready in time is updated on the next run",
+			opts:        root.Options{Confirm: true},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {
+						Repo:     org.Repo{Description: &newDescription, HasWiki: &yes},
+						OnCreate: &config.RepoCreateOptions{ForkOf: &pendingName},
+					},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "forked repo cannot declare its visibility",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {
+						Repo:     org.Repo{Private: &yes},
+						OnCreate: &config.RepoCreateOptions{ForkOf: &templateName},
+					},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectError:   true,
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "repo with conflicting creation options is not created",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {OnCreate: &config.RepoCreateOptions{Template: &templateName, ForkOf: &templateName}},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectError:   true,
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "request to delete a repo fails when not allowed",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Delete: &yes},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectError:   true,
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "request to delete a repo succeeds when allowed",
+			opts: root.Options{
+				AllowRepoDeletion: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Delete: &yes},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{},
+		},
+		{
+			description: "repo marked for deletion is not created",
+			opts: root.Options{
+				AllowRepoDeletion: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Description: &newDescription}, Delete: &yes},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "DeleteRepo failure is propagated",
+			opts: root.Options{
+				AllowRepoDeletion: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					fail: {Delete: &yes},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: failRepo}},
+			expectError:   true,
+			expectedRepos: []github.Repo{failRepo},
+		},
+		{
+			description: "request to transfer a repo fails when not allowed",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {TransferTo: &updated},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectError:   true,
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "request to transfer a repo succeeds when allowed",
+			opts: root.Options{
+				AllowRepoTransfer: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {TransferTo: &updated},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{},
+		},
+		{
+			description: "already transferred repo is not created",
+			opts: root.Options{
+				AllowRepoTransfer: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {TransferTo: &updated},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: oldRepo}},
+			expectedRepos: []github.Repo{oldRepo},
+		},
+		{
+			description: "unmanaged repos are left alone by default",
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: "unmanaged"}},
+			},
+			expectedRepos: []github.Repo{{Name: newName}, {Name: "unmanaged"}},
+		},
+		{
+			description: "request to archive unmanaged repos fails when not allowed",
+			opts: root.Options{
+				UnmanagedRepos: root.UnmanagedReposArchive,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: "unmanaged"}},
+			},
+			expectError:   true,
+			expectedRepos: []github.Repo{{Name: oldName}, {Name: "unmanaged"}},
+		},
+		{
+			description: "unmanaged repos fail the run when requested",
+			opts: root.Options{
+				FailOnUnmanagedRepos: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: "unmanaged"}},
+			},
+			expectError:   true,
+			expectedRepos: []github.Repo{{Name: newName}, {Name: "unmanaged"}},
+		},
+		{
+			description: "allowlisted unmanaged repos do not fail the run",
+			opts: root.Options{
+				FailOnUnmanagedRepos:    true,
+				UnmanagedReposAllowlist: []string{"UNMANAGED-*"},
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: "unmanaged-sandbox"}},
+			},
+			expectedRepos: []github.Repo{{Name: oldName}, {Name: "unmanaged-sandbox"}},
+		},
+		{
+			description: "unmanaged repos are archived when allowed",
+			opts: root.Options{
+				UnmanagedRepos:    root.UnmanagedReposArchive,
+				AllowRepoArchival: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Previously: []string{oldName}}},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: "unmanaged"}},
+			},
+			expectedRepos: []github.Repo{{Name: newName}, {Name: "unmanaged", Archived: true}},
+		},
+		{
+			description: "protected repos are neither deleted, transferred nor archived",
+			opts: root.Options{
+				AllowRepoDeletion: true,
+				AllowRepoTransfer: true,
+				UnmanagedRepos:    root.UnmanagedReposArchive,
+				AllowRepoArchival: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Delete: &yes},
+					newName: {TransferTo: &updated},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: newName}},
+				{Repo: github.Repo{Name: "Unmanaged"}},
+			},
+			protected:     protection{repos: normalizeNames([]string{"OLD", newName, "unmanaged"})},
+			expectedRepos: []github.Repo{{Name: "Unmanaged"}, {Name: newName}, {Name: oldName}},
+		},
+		{
+			description: "protected repos are deleted, transferred and archived when protections are overridden",
+			opts: root.Options{
+				AllowRepoDeletion: true,
+				AllowRepoTransfer: true,
+				UnmanagedRepos:    root.UnmanagedReposArchive,
+				AllowRepoArchival: true,
+				OverrideProtected: true,
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Delete: &yes},
+					newName: {TransferTo: &updated},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: newName}},
+				{Repo: github.Repo{Name: "Unmanaged"}},
+			},
+			protected:     newProtection(root.Options{OverrideProtected: true}, orgName, &config.Protected{Repos: []string{"OLD", newName, "unmanaged"}}),
+			expectedRepos: []github.Repo{{Name: "Unmanaged", Archived: true}},
+		},
+		{
+			description: "overridden protections still need approval for high-risk repo changes",
+			opts: root.Options{
+				AllowRepoDeletion: true,
+				AllowRepoTransfer: true,
+				UnmanagedRepos:    root.UnmanagedReposArchive,
+				AllowRepoArchival: true,
+				OverrideProtected: true,
+				RequireApproval:   true,
+				ApprovedChanges:   []string{changeID(changeRepoDelete, orgName, oldName)},
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					oldName: {Delete: &yes},
+					newName: {TransferTo: &updated},
+				},
+			},
+			repos: []github.FullRepo{
+				{Repo: github.Repo{Name: oldName}},
+				{Repo: github.Repo{Name: newName}},
+				{Repo: github.Repo{Name: "Unmanaged"}},
+			},
+			protected:     newProtection(root.Options{OverrideProtected: true}, orgName, &config.Protected{Repos: []string{"OLD", newName, "unmanaged"}}),
+			expectedRepos: []github.Repo{{Name: "Unmanaged"}, {Name: newName}},
+		},
+		{
+			description: "repos are matched by their locked ID",
+			opts: root.Options{
+				Locked: config.OrgLock{Repos: map[string]int{newName: 42}},
+			},
+			orgConfig: config.Config{
+				Repos: map[string]config.Repo{
+					newName: {Repo: org.Repo{Description: &updated}},
+				},
+			},
+			repos:         []github.FullRepo{{Repo: github.Repo{Name: "renamed-on-github"}}},
+			repoIDs:       map[string]int{"renamed-on-github": 42},
+			expectedRepos: []github.Repo{{Name: newName, Description: updated}},
+		},
+	}
+	forkWait, forkPoll = 0, 0
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			fc := makeFakeRepoClient(t, tc.repos...)
+			fc.ids = tc.repoIDs
+			var err error
+			if len(tc.orgNameOverride) > 0 {
+				err = configureRepos(tc.opts, fc, tc.orgNameOverride, tc.orgConfig, tc.protected)
+			} else {
+				err = configureRepos(tc.opts, fc, orgName, tc.orgConfig, tc.protected)
+			}
+			if err != nil && !tc.expectError {
+				t.Errorf("%s: unexpected error: %v", tc.description, err)
+			}
+			if err == nil && tc.expectError {
+				t.Errorf("%s: expected error, got none", tc.description)
+			}
+
+			reposAfter, err := fc.GetRepos(orgName, isOrg)
+			if err != nil {
+				t.Fatalf("%s: unexpected GetRepos error: %v", tc.description, err)
+			}
+			if !reflect.DeepEqual(reposAfter, tc.expectedRepos) {
+				t.Errorf("%s: unexpected repos after configureRepos():\n%s", tc.description, cmp.Diff(reposAfter, tc.expectedRepos))
+			}
+		})
+	}
 }
 
 func TestValidateRepos(t *testing.T) {
 	description := "cool repo"
+	template := "org/template-repo"
+	yes := true
 	testCases := []struct {
 		description string
-		config      map[string]org.Repo
+		config      map[string]config.Repo
 		expectError bool
 	}{
 		{
@@ -2858,42 +5374,93 @@ func TestValidateRepos(t *testing.T) {
 		},
 		{
 			description: "handles empty map",
-			config:      map[string]org.Repo{},
+			config:      map[string]config.Repo{},
 		},
 		{
 			description: "handles valid config",
-			config: map[string]org.Repo{
-				"repo": {Description: &description},
+			config: map[string]config.Repo{
+				"repo": {Repo: org.Repo{Description: &description}},
 			},
 		},
 		{
 			description: "finds repo names duplicate when normalized",
-			config: map[string]org.Repo{
-				"repo": {Description: &description},
-				"Repo": {Description: &description},
+			config: map[string]config.Repo{
+				"repo": {Repo: org.Repo{Description: &description}},
+				"Repo": {Repo: org.Repo{Description: &description}},
 			},
 			expectError: true,
 		},
 		{
 			description: "finds name confict between previous and current names",
-			config: map[string]org.Repo{
-				"repo":     {Previously: []string{"conflict"}},
-				"conflict": {Description: &description},
+			config: map[string]config.Repo{
+				"repo":     {Repo: org.Repo{Previously: []string{"conflict"}}},
+				"conflict": {Repo: org.Repo{Description: &description}},
 			},
 			expectError: true,
 		},
 		{
 			description: "finds name confict between two previous names",
-			config: map[string]org.Repo{
-				"repo":         {Previously: []string{"conflict"}},
-				"another-repo": {Previously: []string{"conflict"}},
+			config: map[string]config.Repo{
+				"repo":         {Repo: org.Repo{Previously: []string{"conflict"}}},
+				"another-repo": {Repo: org.Repo{Previously: []string{"conflict"}}},
+			},
+			expectError: true,
+		},
+		{
+			description: "finds repo both generated from a template and forked",
+			config: map[string]config.Repo{
+				"repo": {OnCreate: &config.RepoCreateOptions{Template: &template, ForkOf: &template}},
+			},
+			expectError: true,
+		},
+		{
+			description: "finds template combined with auto init",
+			config: map[string]config.Repo{
+				"repo": {OnCreate: &config.RepoCreateOptions{
+					RepoCreateOptions: org.RepoCreateOptions{AutoInit: &yes},
+					Template:          &template,
+				}},
+			},
+			expectError: true,
+		},
+		{
+			description: "finds malformed template reference",
+			config: map[string]config.Repo{
+				"repo": {OnCreate: &config.RepoCreateOptions{Template: &description}},
+			},
+			expectError: true,
+		},
+		{
+			description: "allows template including all branches",
+			config: map[string]config.Repo{
+				"repo": {OnCreate: &config.RepoCreateOptions{Template: &template, IncludeAllBranches: &yes}},
+			},
+		},
+		{
+			description: "finds repo both deleted and transferred",
+			config: map[string]config.Repo{
+				"repo": {Delete: &yes, TransferTo: &description},
+			},
+			expectError: true,
+		},
+		{
+			description: "finds invalid actions policy",
+			config: map[string]config.Repo{
+				"repo": {Actions: &config.ActionsPolicy{AllowedActions: &description}},
+			},
+			expectError: true,
+		},
+		{
+			description: "finds org only actions settings",
+			config: map[string]config.Repo{
+				"repo": {Actions: &config.ActionsPolicy{SelectedRepositories: []string{"repo"}}},
 			},
 			expectError: true,
 		},
 		{
 			description: "allows case-duplicate name between former and current name",
-			config: map[string]org.Repo{
-				"repo": {Previously: []string{"REPO"}},
+			config: map[string]config.Repo{
+				"repo": {Repo: org.Repo{Previously: []string{"REPO"}}},
 			},
 		},
 	}
@@ -2910,6 +5477,43 @@ func TestValidateRepos(t *testing.T) {
 	}
 }
 
+func TestFindUnmanagedRepos(t *testing.T) {
+	repos := []github.Repo{{Name: "Managed"}, {Name: "renamed"}, {Name: "sandbox-1"}, {Name: "other"}}
+	managed := sets.New[string]("managed", "renamed")
+
+	testCases := []struct {
+		description string
+		allowlist   []string
+		expected    []string
+	}{
+		{
+			description: "managed repos are matched case-insensitively",
+			expected:    []string{"sandbox-1", "other"},
+		},
+		{
+			description: "allowlisted repos are not unmanaged",
+			allowlist:   []string{"sandbox-*"},
+			expected:    []string{"other"},
+		},
+		{
+			description: "everything can be allowlisted",
+			allowlist:   []string{"*"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var actual []string
+			for _, repo := range findUnmanagedRepos(repos, managed, tc.allowlist) {
+				actual = append(actual, repo.Name)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("%s: unexpected unmanaged repos: %s", tc.description, cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
 func TestNewRepoUpdateRequest(t *testing.T) {
 	repoName := "repo-name"
 	newRepoName := "renamed-repo"
@@ -3009,3 +5613,473 @@ func TestNewRepoUpdateRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureHooks(t *testing.T) {
+	yes := true
+	no := false
+	jsonType := "json"
+	formType := "form"
+	t.Setenv("HOOK_SECRET", "hunter2")
+	cases := []struct {
+		name    string
+		have    []github.Hook
+		want    []config.Webhook
+		created []string
+		edited  []int
+		deleted []int
+		err     bool
+	}{
+		{
+			name: "nothing to do",
+			have: []github.Hook{
+				{ID: 1, Events: []string{"push"}, Active: true, Config: github.HookConfig{URL: "https://a"}},
+			},
+			want: []config.Webhook{
+				{URL: "https://a", Events: []string{"push"}},
+			},
+		},
+		{
+			name: "create missing webhooks",
+			want: []config.Webhook{
+				{URL: "https://a", Events: []string{"push"}, Active: &yes},
+				{URL: "https://b"},
+			},
+			created: []string{"https://a", "https://b"},
+		},
+		{
+			name: "edit webhooks with different settings",
+			have: []github.Hook{
+				{ID: 1, Events: []string{"push"}, Active: true, Config: github.HookConfig{URL: "https://a"}},
+				{ID: 2, Events: []string{"push"}, Active: true, Config: github.HookConfig{URL: "https://b", ContentType: &formType}},
+				{ID: 3, Events: []string{"push"}, Active: true, Config: github.HookConfig{URL: "https://c"}},
+			},
+			want: []config.Webhook{
+				{URL: "https://a", Events: []string{"push", "pull_request"}},
+				{URL: "https://b", ContentType: &jsonType},
+				{URL: "https://c", Active: &no},
+			},
+			edited: []int{1, 2, 3},
+		},
+		{
+			name: "delete undeclared and duplicated webhooks",
+			have: []github.Hook{
+				{ID: 1, Config: github.HookConfig{URL: "https://a"}},
+				{ID: 2, Config: github.HookConfig{URL: "https://a"}},
+				{ID: 3, Config: github.HookConfig{URL: "https://b"}},
+			},
+			want: []config.Webhook{
+				{URL: "https://a"},
+			},
+			deleted: []int{2, 3},
+		},
+		{
+			name: "empty list deletes every webhook",
+			have: []github.Hook{
+				{ID: 1, Config: github.HookConfig{URL: "https://a"}},
+			},
+			want:    []config.Webhook{},
+			deleted: []int{1},
+		},
+		{
+			name: "resolve secret from env",
+			want: []config.Webhook{
+				{URL: "https://a", Secret: &config.SecretRef{Env: "HOOK_SECRET"}},
+			},
+			created: []string{"https://a"},
+		},
+		{
+			name: "fail on unresolvable secret",
+			want: []config.Webhook{
+				{URL: "https://a", Secret: &config.SecretRef{Env: "MISSING_HOOK_SECRET"}},
+			},
+			err: true,
+		},
+		{
+			name: "reject duplicated urls",
+			want: []config.Webhook{
+				{URL: "https://a"},
+				{URL: "https://a"},
+			},
+			err: true,
+		},
+		{
+			name: "reject missing url",
+			want: []config.Webhook{
+				{Events: []string{"push"}},
+			},
+			err: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created []string
+			var edited, deleted []int
+			err := configureHooks(tc.have, tc.want,
+				func(req github.HookRequest) error {
+					if req.Name != "web" {
+						t.Errorf("unexpected hook name %q", req.Name)
+					}
+					if req.Config.URL == "https://a" && tc.want[0].Secret != nil && (req.Config.Secret == nil || *req.Config.Secret != "hunter2") {
+						t.Errorf("secret not resolved: %v", req.Config.Secret)
+					}
+					created = append(created, req.Config.URL)
+					return nil
+				},
+				func(id int, req github.HookRequest) error {
+					edited = append(edited, id)
+					return nil
+				},
+				func(hook github.Hook) error {
+					deleted = append(deleted, hook.ID)
+					return nil
+				},
+			)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("failed to receive error")
+			default:
+				sort.Strings(created)
+				sort.Ints(edited)
+				sort.Ints(deleted)
+				if diff := cmp.Diff(tc.created, created); diff != "" {
+					t.Errorf("created differs: %s", diff)
+				}
+				if diff := cmp.Diff(tc.edited, edited); diff != "" {
+					t.Errorf("edited differs: %s", diff)
+				}
+				if diff := cmp.Diff(tc.deleted, deleted); diff != "" {
+					t.Errorf("deleted differs: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+type fakeActionsClient struct {
+	orgPerms     ghclient.OrgActionsPermissions
+	orgRepos     []ghclient.ActionsRepo
+	orgSelected  ghclient.SelectedActions
+	orgWorkflow  ghclient.WorkflowPermissions
+	repoPerms    map[string]ghclient.RepoActionsPermissions
+	repoSelected map[string]ghclient.SelectedActions
+	repoWorkflow map[string]ghclient.WorkflowPermissions
+	repoIDs      map[string]int
+	edits        []string
+}
+
+func (c *fakeActionsClient) GetOrgActionsPermissions(org string) (*ghclient.OrgActionsPermissions, error) {
+	if org == "fail" {
+		return nil, errors.New("injected GetOrgActionsPermissions error")
+	}
+	perms := c.orgPerms
+	return &perms, nil
+}
+
+func (c *fakeActionsClient) EditOrgActionsPermissions(org string, perms ghclient.OrgActionsPermissions) error {
+	c.edits = append(c.edits, "EditOrgActionsPermissions")
+	c.orgPerms = perms
+	return nil
+}
+
+func (c *fakeActionsClient) ListOrgActionsRepos(org string) ([]ghclient.ActionsRepo, error) {
+	if c.orgPerms.EnabledRepositories != config.ActionsSelected {
+		return nil, errors.New("repositories are not selected")
+	}
+	return c.orgRepos, nil
+}
+
+func (c *fakeActionsClient) SetOrgActionsRepos(org string, ids []int) error {
+	c.edits = append(c.edits, "SetOrgActionsRepos")
+	c.orgRepos = nil
+	for _, id := range ids {
+		for name, repoID := range c.repoIDs {
+			if id == repoID {
+				c.orgRepos = append(c.orgRepos, ghclient.ActionsRepo{ID: id, Name: name})
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fakeActionsClient) GetRepoID(owner, repo string) (int, error) {
+	id, ok := c.repoIDs[repo]
+	if !ok {
+		return 0, fmt.Errorf("repo %s not found", repo)
+	}
+	return id, nil
+}
+
+func (c *fakeActionsClient) GetOrgSelectedActions(org string) (*ghclient.SelectedActions, error) {
+	if c.orgPerms.AllowedActions != config.ActionsSelected {
+		return nil, errors.New("actions are not selected")
+	}
+	selected := c.orgSelected
+	return &selected, nil
+}
+
+func (c *fakeActionsClient) EditOrgSelectedActions(org string, selected ghclient.SelectedActions) error {
+	c.edits = append(c.edits, "EditOrgSelectedActions")
+	c.orgSelected = selected
+	return nil
+}
+
+func (c *fakeActionsClient) GetOrgWorkflowPermissions(org string) (*ghclient.WorkflowPermissions, error) {
+	perms := c.orgWorkflow
+	return &perms, nil
+}
+
+func (c *fakeActionsClient) EditOrgWorkflowPermissions(org string, perms ghclient.WorkflowPermissions) error {
+	c.edits = append(c.edits, "EditOrgWorkflowPermissions")
+	c.orgWorkflow = perms
+	return nil
+}
+
+func (c *fakeActionsClient) GetRepoActionsPermissions(owner, repo string) (*ghclient.RepoActionsPermissions, error) {
+	perms, ok := c.repoPerms[repo]
+	if !ok {
+		return nil, &ghclient.RequestError{StatusCode: http.StatusNotFound}
+	}
+	return &perms, nil
+}
+
+func (c *fakeActionsClient) EditRepoActionsPermissions(owner, repo string, perms ghclient.RepoActionsPermissions) error {
+	c.edits = append(c.edits, "EditRepoActionsPermissions")
+	c.repoPerms[repo] = perms
+	return nil
+}
+
+func (c *fakeActionsClient) GetRepoSelectedActions(owner, repo string) (*ghclient.SelectedActions, error) {
+	if c.repoPerms[repo].AllowedActions != config.ActionsSelected {
+		return nil, errors.New("actions are not selected")
+	}
+	selected := c.repoSelected[repo]
+	return &selected, nil
+}
+
+func (c *fakeActionsClient) EditRepoSelectedActions(owner, repo string, selected ghclient.SelectedActions) error {
+	c.edits = append(c.edits, "EditRepoSelectedActions")
+	if c.repoSelected == nil {
+		c.repoSelected = map[string]ghclient.SelectedActions{}
+	}
+	c.repoSelected[repo] = selected
+	return nil
+}
+
+func (c *fakeActionsClient) GetRepoWorkflowPermissions(owner, repo string) (*ghclient.WorkflowPermissions, error) {
+	perms := c.repoWorkflow[repo]
+	return &perms, nil
+}
+
+func (c *fakeActionsClient) EditRepoWorkflowPermissions(owner, repo string, perms ghclient.WorkflowPermissions) error {
+	c.edits = append(c.edits, "EditRepoWorkflowPermissions")
+	if c.repoWorkflow == nil {
+		c.repoWorkflow = map[string]ghclient.WorkflowPermissions{}
+	}
+	c.repoWorkflow[repo] = perms
+	return nil
+}
+
+func TestConfigureOrgActions(t *testing.T) {
+	yes := true
+	no := false
+	all := config.ActionsAll
+	selected := config.ActionsSelected
+	localOnly := config.ActionsLocalOnly
+	read := config.WorkflowPermissionsRead
+	bogus := "bogus"
+	repoIDs := map[string]int{"foo": 1, "bar": 2, "baz": 3}
+
+	cases := []struct {
+		name     string
+		orgName  string
+		current  fakeActionsClient
+		policy   *config.ActionsPolicy
+		expected fakeActionsClient
+		err      bool
+	}{
+		{
+			name:     "nil policy is not managed",
+			current:  fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all}},
+			expected: fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all}},
+		},
+		{
+			name:     "nothing to do",
+			current:  fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all}},
+			policy:   &config.ActionsPolicy{EnabledRepositories: &all, AllowedActions: &all},
+			expected: fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all}},
+		},
+		{
+			name:    "restrict allowed actions",
+			current: fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all}},
+			policy:  &config.ActionsPolicy{AllowedActions: &localOnly},
+			expected: fakeActionsClient{
+				orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: localOnly},
+				edits:    []string{"EditOrgActionsPermissions"},
+			},
+		},
+		{
+			name:    "select repositories and actions without reading the previous selection",
+			current: fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all}},
+			policy: &config.ActionsPolicy{
+				EnabledRepositories:  &selected,
+				SelectedRepositories: []string{"bar", "foo"},
+				AllowedActions:       &selected,
+				SelectedActions: &config.SelectedActions{
+					GithubOwnedAllowed: &yes,
+					PatternsAllowed:    []string{"my-org/*"},
+				},
+			},
+			expected: fakeActionsClient{
+				orgPerms:    ghclient.OrgActionsPermissions{EnabledRepositories: selected, AllowedActions: selected},
+				orgRepos:    []ghclient.ActionsRepo{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}},
+				orgSelected: ghclient.SelectedActions{GithubOwnedAllowed: true, PatternsAllowed: []string{"my-org/*"}},
+				edits:       []string{"EditOrgActionsPermissions", "SetOrgActionsRepos", "EditOrgSelectedActions"},
+			},
+		},
+		{
+			name: "keep selected repositories and merge selected actions",
+			current: fakeActionsClient{
+				orgPerms:    ghclient.OrgActionsPermissions{EnabledRepositories: selected, AllowedActions: selected},
+				orgRepos:    []ghclient.ActionsRepo{{ID: 1, Name: "foo"}},
+				orgSelected: ghclient.SelectedActions{GithubOwnedAllowed: true, PatternsAllowed: []string{"my-org/*"}},
+			},
+			policy: &config.ActionsPolicy{
+				EnabledRepositories:  &selected,
+				SelectedRepositories: []string{"FOO"},
+				AllowedActions:       &selected,
+				SelectedActions:      &config.SelectedActions{VerifiedAllowed: &yes},
+			},
+			expected: fakeActionsClient{
+				orgPerms:    ghclient.OrgActionsPermissions{EnabledRepositories: selected, AllowedActions: selected},
+				orgRepos:    []ghclient.ActionsRepo{{ID: 1, Name: "foo"}},
+				orgSelected: ghclient.SelectedActions{GithubOwnedAllowed: true, VerifiedAllowed: true, PatternsAllowed: []string{"my-org/*"}},
+				edits:       []string{"EditOrgSelectedActions"},
+			},
+		},
+		{
+			name: "update workflow permissions",
+			current: fakeActionsClient{
+				orgPerms:    ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all},
+				orgWorkflow: ghclient.WorkflowPermissions{DefaultWorkflowPermissions: "write", CanApprovePullRequestReviews: true},
+			},
+			policy: &config.ActionsPolicy{DefaultWorkflowPermissions: &read, CanApprovePullRequestReviews: &no},
+			expected: fakeActionsClient{
+				orgPerms:    ghclient.OrgActionsPermissions{EnabledRepositories: all, AllowedActions: all},
+				orgWorkflow: ghclient.WorkflowPermissions{DefaultWorkflowPermissions: read},
+				edits:       []string{"EditOrgWorkflowPermissions"},
+			},
+		},
+		{
+			name:    "fails on unknown repo",
+			current: fakeActionsClient{orgPerms: ghclient.OrgActionsPermissions{EnabledRepositories: selected}},
+			policy:  &config.ActionsPolicy{EnabledRepositories: &selected, SelectedRepositories: []string{"missing"}},
+			err:     true,
+		},
+		{
+			name:   "fails on invalid policy",
+			policy: &config.ActionsPolicy{AllowedActions: &bogus},
+			err:    true,
+		},
+		{
+			name:   "fails on repo settings",
+			policy: &config.ActionsPolicy{Enabled: &yes},
+			err:    true,
+		},
+		{
+			name:    "fails if GetOrgActionsPermissions fails",
+			orgName: "fail",
+			policy:  &config.ActionsPolicy{AllowedActions: &all},
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			orgName := "org"
+			if tc.orgName != "" {
+				orgName = tc.orgName
+			}
+			fc := tc.current
+			fc.repoIDs = repoIDs
+			err := configureOrgActions(&fc, orgName, tc.policy)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("failed to receive error")
+			default:
+				fc.repoIDs = nil
+				if diff := cmp.Diff(tc.expected, fc, cmp.AllowUnexported(fakeActionsClient{})); diff != "" {
+					t.Errorf("actions differ (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigureRepoActions(t *testing.T) {
+	yes := true
+	no := false
+	all := config.ActionsAll
+	selected := config.ActionsSelected
+	write := config.WorkflowPermissionsWrite
+
+	cases := []struct {
+		name     string
+		current  ghclient.RepoActionsPermissions
+		policy   *config.ActionsPolicy
+		expected fakeActionsClient
+	}{
+		{
+			name:    "nil policy is not managed",
+			current: ghclient.RepoActionsPermissions{Enabled: true, AllowedActions: all},
+			expected: fakeActionsClient{
+				repoPerms: map[string]ghclient.RepoActionsPermissions{"repo": {Enabled: true, AllowedActions: all}},
+			},
+		},
+		{
+			name:    "disable actions",
+			current: ghclient.RepoActionsPermissions{Enabled: true, AllowedActions: all},
+			policy:  &config.ActionsPolicy{Enabled: &no, DefaultWorkflowPermissions: &write},
+			expected: fakeActionsClient{
+				repoPerms: map[string]ghclient.RepoActionsPermissions{"repo": {}},
+				edits:     []string{"EditRepoActionsPermissions"},
+			},
+		},
+		{
+			name:    "override allowed actions and workflow permissions",
+			current: ghclient.RepoActionsPermissions{Enabled: true, AllowedActions: all},
+			policy: &config.ActionsPolicy{
+				AllowedActions:               &selected,
+				SelectedActions:              &config.SelectedActions{VerifiedAllowed: &yes},
+				DefaultWorkflowPermissions:   &write,
+				CanApprovePullRequestReviews: &yes,
+			},
+			expected: fakeActionsClient{
+				repoPerms:    map[string]ghclient.RepoActionsPermissions{"repo": {Enabled: true, AllowedActions: selected}},
+				repoSelected: map[string]ghclient.SelectedActions{"repo": {VerifiedAllowed: true, PatternsAllowed: []string{}}},
+				repoWorkflow: map[string]ghclient.WorkflowPermissions{"repo": {DefaultWorkflowPermissions: write, CanApprovePullRequestReviews: true}},
+				edits:        []string{"EditRepoActionsPermissions", "EditRepoSelectedActions", "EditRepoWorkflowPermissions"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakeActionsClient{repoPerms: map[string]ghclient.RepoActionsPermissions{"repo": tc.current}}
+			if err := configureRepoActions(&fc, "org", "repo", tc.policy); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fc, cmp.AllowUnexported(fakeActionsClient{})); diff != "" {
+				t.Errorf("actions differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
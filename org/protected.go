@@ -0,0 +1,77 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+// protection holds the users, teams, repos and custom repo roles which
+// must not be removed nor demoted, by their normalized login or lowercase
+// name. The zero value protects nothing.
+type protection struct {
+	users     sets.Set[string]
+	teams     sets.Set[string]
+	repos     sets.Set[string]
+	repoRoles sets.Set[string]
+}
+
+// newProtection returns the protections of the org, or none when they are
+// overridden.
+func newProtection(opt root.Options, orgName string, protected *config.Protected) protection {
+	if protected == nil {
+		return protection{}
+	}
+	if opt.OverrideProtected {
+		logrus.Warnf("Protections of %s are overridden, protected users, teams and repos may be removed or demoted", orgName)
+		return protection{}
+	}
+	return protection{
+		users:     normalize(sets.New[string](protected.Users...)),
+		teams:     normalizeNames(protected.Teams),
+		repos:     normalizeNames(protected.Repos),
+		repoRoles: normalizeNames(protected.RepoRoles),
+	}
+}
+
+func (p protection) user(login string) bool {
+	return p.users.Has(github.NormLogin(login))
+}
+
+func (p protection) team(name string) bool {
+	return p.teams.Has(strings.ToLower(name))
+}
+
+func (p protection) repo(name string) bool {
+	return p.repos.Has(strings.ToLower(name))
+}
+
+func (p protection) repoRole(name string) bool {
+	return p.repoRoles.Has(strings.ToLower(name))
+}
+
+// demotes reports whether changing a permission from have to want lowers it.
+func demotes(have, want github.RepoPermissionLevel) bool {
+	return !config.Grants(want, have)
+}
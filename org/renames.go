@@ -0,0 +1,57 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+)
+
+type renameClient interface {
+	ListOrgMemberUsers(org string) ([]github.User, error)
+}
+
+// detectRenames returns the current logins of the org members declared with
+// a user ID whose login was renamed, keyed by their normalized declared
+// login. Each rename is reported so that the config can be updated.
+func detectRenames(client renameClient, orgName string, cfg config.Config) (map[string]string, error) {
+	ids, err := cfg.UserIDs()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	users, err := client.ListOrgMemberUsers(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s members: %w", orgName, err)
+	}
+	renames := map[string]string{}
+	for _, user := range users {
+		login, ok := ids[user.ID]
+		if !ok || github.NormLogin(login) == github.NormLogin(user.Login) {
+			continue
+		}
+		logrus.Warnf("User %d of %s renamed login %s to %s, update the config", user.ID, orgName, login, user.Login)
+		renames[github.NormLogin(login)] = user.Login
+	}
+	return renames, nil
+}
@@ -18,13 +18,20 @@ package org
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/config/org"
 	"sigs.k8s.io/prow/pkg/github"
-	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
 	"github.com/uwu-tools/peribolos/options/root"
 )
 
@@ -33,9 +40,15 @@ type repoClient interface {
 	GetRepos(orgName string, isUser bool) ([]github.Repo, error)
 	CreateRepo(owner string, isUser bool, repo github.RepoCreateRequest) (*github.FullRepo, error)
 	UpdateRepo(owner, name string, repo github.RepoUpdateRequest) (*github.FullRepo, error)
+	DeleteRepo(owner, name string) error
+	TransferRepo(owner, name, newOwner string) error
+	GenerateRepo(templateOwner, templateRepo string, req ghclient.GenerateRepoRequest) (*github.FullRepo, error)
+	ForkRepo(owner, repo string, req ghclient.ForkRepoRequest) (*github.FullRepo, error)
+	ListOrgRepoIDs(org string) ([]ghclient.RepoID, error)
+	repoActionsClient
 }
 
-func configureRepos(opt root.Options, client repoClient, orgName string, orgConfig org.Config) error {
+func configureRepos(opt root.Options, client repoClient, orgName string, orgConfig config.Config, protected protection) error {
 	if err := validateRepos(orgConfig.Repos); err != nil {
 		return err
 	}
@@ -49,14 +62,37 @@ func configureRepos(opt root.Options, client repoClient, orgName string, orgConf
 	for _, repo := range repoList {
 		byName[strings.ToLower(repo.Name)] = repo
 	}
+	// Repos renamed outside of the config are matched by their locked ID.
+	byID := map[int]string{}
+	if len(opt.Locked.Repos) > 0 {
+		ids, err := client.ListOrgRepoIDs(orgName)
+		if err != nil {
+			return fmt.Errorf("failed to list repo IDs: %w", err)
+		}
+		for _, repo := range ids {
+			byID[repo.ID] = repo.Name
+		}
+	}
 
 	var allErrors []error
+	managed := sets.Set[string]{}
+	approved := newApprovals(opt)
+	scoped := newScope(opt)
 
 	for wantName, wantRepo := range orgConfig.Repos {
 		repoLogger := logrus.WithField("repo", wantName)
+		if scoped.readOnlyRepo(wantName) {
+			repoLogger.Info("repo is read-only, skipping")
+			// It is still declared under its current and previous names.
+			for _, name := range lockedRepoNames(opt.Locked.Repos, byID, wantName, wantRepo.Previously) {
+				managed.Insert(strings.ToLower(name))
+			}
+			continue
+		}
 		pastErrors := len(allErrors)
 		var existing *github.FullRepo = nil
-		for _, possibleName := range append([]string{wantName}, wantRepo.Previously...) {
+		for _, possibleName := range lockedRepoNames(opt.Locked.Repos, byID, wantName, wantRepo.Previously) {
+			managed.Insert(strings.ToLower(possibleName))
 			if repo, exists := byName[strings.ToLower(possibleName)]; exists {
 				switch {
 				case existing == nil:
@@ -77,6 +113,52 @@ func configureRepos(opt root.Options, client repoClient, orgName string, orgConf
 			continue
 		}
 
+		if wantRepo.Delete != nil && *wantRepo.Delete {
+			switch {
+			case existing == nil:
+				repoLogger.Debug("repo is marked for deletion and does not exist")
+			case protected.repo(existing.Name):
+				repoLogger.Warnf("Not deleting protected repo %s from %s", existing.Name, orgName)
+			case !opt.AllowRepoDeletion:
+				repoLogger.Error("repo is marked for deletion but this is not allowed by default (see --allow-repo-deletion)")
+				allErrors = append(allErrors, fmt.Errorf("asked to delete repo %s but this is not allowed by default (see --allow-repo-deletion)", existing.Name))
+			case !approved.allow(changeRepoDelete, orgName, existing.Name):
+				// Skipped until approved.
+			default:
+				repoLogger.Info("repo is marked for deletion, deleting")
+				if err := client.DeleteRepo(orgName, existing.Name); err != nil {
+					repoLogger.WithError(err).Error("failed to delete repository")
+					allErrors = append(allErrors, err)
+				} else {
+					record(client, orgName, "repo.delete", existing.Name, *existing, nil)
+				}
+			}
+			continue
+		}
+
+		if wantRepo.TransferTo != nil {
+			switch {
+			case existing == nil:
+				repoLogger.Infof("repo does not exist, assuming it was transferred to %s", *wantRepo.TransferTo)
+			case protected.repo(existing.Name):
+				repoLogger.Warnf("Not transferring protected repo %s from %s", existing.Name, orgName)
+			case !opt.AllowRepoTransfer:
+				repoLogger.Error("repo is marked for transfer but this is not allowed by default (see --allow-repo-transfer)")
+				allErrors = append(allErrors, fmt.Errorf("asked to transfer repo %s but this is not allowed by default (see --allow-repo-transfer)", existing.Name))
+			case !approved.allow(changeRepoTransfer, orgName, existing.Name):
+				// Skipped until approved.
+			default:
+				repoLogger.Infof("repo is marked for transfer, transferring to %s", *wantRepo.TransferTo)
+				if err := client.TransferRepo(orgName, existing.Name, *wantRepo.TransferTo); err != nil {
+					repoLogger.WithError(err).Error("failed to transfer repository")
+					allErrors = append(allErrors, err)
+				} else {
+					record(client, orgName, "repo.transfer", existing.Name, orgName, *wantRepo.TransferTo)
+				}
+			}
+			continue
+		}
+
 		if existing == nil {
 			if wantRepo.Archived != nil && *wantRepo.Archived {
 				repoLogger.Error("repo does not exist but is configured as archived: not creating")
@@ -84,12 +166,19 @@ func configureRepos(opt root.Options, client repoClient, orgName string, orgConf
 				continue
 			}
 			repoLogger.Info("repo does not exist, creating")
-			created, err := client.CreateRepo(orgName, false, newRepoCreateRequest(wantName, wantRepo))
+			created, err := createRepo(client, orgName, wantName, wantRepo)
 			if err != nil {
 				repoLogger.WithError(err).Error("failed to create repository")
 				allErrors = append(allErrors, err)
 			} else {
 				existing = created
+				record(client, orgName, "repo.create", wantName, nil, *created)
+				if isFork(wantRepo) && opt.Confirm {
+					if existing = waitForFork(client, orgName, wantName); existing == nil {
+						repoLogger.Warnf("fork is not ready after %s, its settings will be applied on the next run", forkWait)
+						continue
+					}
+				}
 			}
 		}
 
@@ -101,8 +190,8 @@ func configureRepos(opt root.Options, client repoClient, orgName string, orgConf
 				}
 			}
 			repoLogger.Info("repo exists, considering an update")
-			delta := newRepoUpdateRequest(*existing, wantName, wantRepo)
-			if deltaErrors := sanitizeRepoDelta(opt, &delta); len(deltaErrors) > 0 {
+			delta := newRepoUpdateRequest(*existing, wantName, wantRepo.Repo)
+			if deltaErrors := sanitizeRepoDelta(opt, approved, orgName, existing.Name, &delta); len(deltaErrors) > 0 {
 				for _, err := range deltaErrors {
 					repoLogger.WithError(err).Error("requested repo change is not allowed, removing from delta")
 				}
@@ -113,19 +202,152 @@ func configureRepos(opt root.Options, client repoClient, orgName string, orgConf
 				if _, err := client.UpdateRepo(orgName, existing.Name, delta); err != nil {
 					repoLogger.WithError(err).Error("failed to update repository")
 					allErrors = append(allErrors, err)
+				} else {
+					record(client, orgName, "repo.update", existing.Name, *existing, delta)
 				}
 			}
+			err := configureRepoActions(client, orgName, existing.Name, wantRepo.Actions)
+			if err != nil && ghclient.IsNotFound(err) && !opt.Confirm {
+				repoLogger.Warn("Running dry-run, repo does not exist yet, cannot retrieve actions permissions, ignoring...")
+			} else if err != nil {
+				repoLogger.WithError(err).Error("failed to configure actions permissions")
+				allErrors = append(allErrors, err)
+			}
 		}
 	}
 
+	allErrors = append(allErrors, configureUnmanagedRepos(opt, approved, client, orgName, repoList, managed, protected)...)
+
 	return utilerrors.NewAggregate(allErrors)
 }
 
-func validateRepos(repos map[string]org.Repo) error {
+// lockedRepoNames returns the current and previous names of the repo, along
+// with the current name of the repo locked for wantName when it was renamed.
+func lockedRepoNames(locked map[string]int, byID map[int]string, wantName string, previously []string) []string {
+	names := append([]string{wantName}, previously...)
+	id, ok := locked[wantName]
+	if !ok {
+		return names
+	}
+	current, ok := byID[id]
+	if !ok {
+		logrus.Warnf("Locked repo %d of %s no longer exists, matching it by name", id, wantName)
+		return names
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, current) {
+			return names
+		}
+	}
+	logrus.Infof("Matched repo %s by its locked ID %d, it is currently named %s", wantName, id, current)
+	return append(names, current)
+}
+
+// configureUnmanagedRepos reports or archives the repos that are not declared
+// in the config under their current or any previous name, nor allowlisted.
+// Protected repos are reported but never archived.
+func configureUnmanagedRepos(opt root.Options, approved approvals, client repoClient, orgName string, repos []github.Repo, managed sets.Set[string], protected protection) []error {
+	ignore := opt.UnmanagedRepos == "" || opt.UnmanagedRepos == root.UnmanagedReposIgnore
+	if ignore && !opt.FailOnUnmanagedRepos {
+		return nil
+	}
+
+	// Read-only and ignored repos are never archived nor reported.
+	allowlist := append(append(slices.Clip(opt.UnmanagedReposAllowlist), opt.ReadOnly.Repos...), opt.Ignored.Repos...)
+	unmanaged := findUnmanagedRepos(repos, managed, allowlist)
+	if len(unmanaged) == 0 {
+		logrus.Debugf("All repos in %s are declared in the config", orgName)
+		return nil
+	}
+	names := make([]string, 0, len(unmanaged))
+	for _, repo := range unmanaged {
+		names = append(names, repo.Name)
+	}
+	sort.Strings(names)
+	logrus.Warnf("Found %d repos in %s not declared in the config: %s", len(names), orgName, strings.Join(names, ", "))
+
+	var errs []error
+	if opt.FailOnUnmanagedRepos {
+		errs = append(errs, fmt.Errorf("%d repos are not declared in the config (see --fail-on-unmanaged-repos): %s", len(names), strings.Join(names, ", ")))
+	}
+	if opt.UnmanagedRepos != root.UnmanagedReposArchive {
+		return errs
+	}
+
+	for _, repo := range unmanaged {
+		repoLogger := logrus.WithField("repo", repo.Name)
+		if repo.Archived {
+			repoLogger.Debug("unmanaged repo is already archived")
+			continue
+		}
+		if protected.repo(repo.Name) {
+			repoLogger.Warnf("Not archiving protected repo %s of %s", repo.Name, orgName)
+			continue
+		}
+		if !opt.AllowRepoArchival {
+			repoLogger.Error("repo is not declared in the config but archiving it is not allowed by default (see --allow-repo-archival)")
+			errs = append(errs, fmt.Errorf("asked to archive unmanaged repo %s but this is not allowed by default (see --allow-repo-archival)", repo.Name))
+			continue
+		}
+		if !approved.allow(changeRepoArchive, orgName, repo.Name) {
+			continue
+		}
+		repoLogger.Info("repo is not declared in the config, archiving")
+		archived := true
+		if _, err := client.UpdateRepo(orgName, repo.Name, github.RepoUpdateRequest{Archived: &archived}); err != nil {
+			repoLogger.WithError(err).Error("failed to archive repository")
+			errs = append(errs, err)
+		} else {
+			record(client, orgName, "repo.archive", repo.Name, repo, github.RepoUpdateRequest{Archived: &archived})
+		}
+	}
+	return errs
+}
+
+// findUnmanagedRepos returns the repos whose lowercase name is neither managed
+// nor matched by any of the allowlist glob patterns.
+func findUnmanagedRepos(repos []github.Repo, managed sets.Set[string], allowlist []string) []github.Repo {
+	var unmanaged []github.Repo
+	for _, repo := range repos {
+		name := strings.ToLower(repo.Name)
+		if managed.Has(name) || matchesAny(name, allowlist) {
+			continue
+		}
+		unmanaged = append(unmanaged, repo)
+	}
+	return unmanaged
+}
+
+// matchesAny returns true if name matches any of the case-insensitive glob patterns.
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if match, err := doublestar.Match(strings.ToLower(pattern), strings.ToLower(name)); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRepos(repos map[string]config.Repo) error {
 	seen := map[string]string{}
 	var dups []string
+	var conflicts []string
+
+	var invalid []error
 
 	for wantName, repo := range repos {
+		if repo.Delete != nil && *repo.Delete && repo.TransferTo != nil {
+			conflicts = append(conflicts, wantName)
+		}
+		if err := validateRepoCreateOptions(repo.OnCreate); err != nil {
+			invalid = append(invalid, fmt.Errorf("%s: %w", wantName, err))
+		}
+		if repo.OnCreate != nil && repo.OnCreate.ForkOf != nil && repo.Private != nil {
+			invalid = append(invalid, fmt.Errorf("%s: private cannot be used with fork_of, forks keep the visibility of their upstream", wantName))
+		}
+		if err := validateActionsPolicy(repo.Actions, false); err != nil {
+			invalid = append(invalid, fmt.Errorf("%s actions: %w", wantName, err))
+		}
 		toCheck := append([]string{wantName}, repo.Previously...)
 		for _, name := range toCheck {
 			normName := strings.ToLower(name)
@@ -143,10 +365,111 @@ func validateRepos(repos map[string]org.Repo) error {
 		return fmt.Errorf("found duplicate repo names (GitHub repo names are case-insensitive): %s", strings.Join(dups, ", "))
 	}
 
+	if len(conflicts) > 0 {
+		return fmt.Errorf("repos cannot be both deleted and transferred: %s", strings.Join(conflicts, ", "))
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("found invalid repo settings: %w", utilerrors.NewAggregate(invalid))
+	}
+
+	return nil
+}
+
+// validateRepoCreateOptions ensures a repo is created in exactly one way.
+func validateRepoCreateOptions(opts *config.RepoCreateOptions) error {
+	if opts == nil {
+		return nil
+	}
+	scratch := opts.AutoInit != nil || opts.GitignoreTemplate != nil || opts.LicenseTemplate != nil
+	switch {
+	case opts.Template != nil && opts.ForkOf != nil:
+		return fmt.Errorf("template and fork_of are mutually exclusive")
+	case (opts.Template != nil || opts.ForkOf != nil) && scratch:
+		return fmt.Errorf("auto_init, gitignore_template and license_template cannot be used with template or fork_of")
+	case opts.IncludeAllBranches != nil && opts.Template == nil:
+		return fmt.Errorf("include_all_branches requires template")
+	}
+	for _, source := range []*string{opts.Template, opts.ForkOf} {
+		if source == nil {
+			continue
+		}
+		if _, _, err := splitRepoName(*source); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func newRepoCreateRequest(name string, definition org.Repo) github.RepoCreateRequest {
+// splitRepoName splits an owner/name repository reference.
+func splitRepoName(fullName string) (string, string, error) {
+	owner, name, found := strings.Cut(fullName, "/")
+	if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("%q is not an owner/name repository reference", fullName)
+	}
+	return owner, name, nil
+}
+
+// forkWait bounds the wait for a fork to be ready, as GitHub creates forks
+// asynchronously, and forkPoll is the interval of the checks.
+var (
+	forkWait = 5 * time.Minute
+	forkPoll = 5 * time.Second
+)
+
+// isFork returns whether repo is created as a fork.
+func isFork(repo config.Repo) bool {
+	return repo.OnCreate != nil && repo.OnCreate.ForkOf != nil
+}
+
+// waitForFork returns the fork once GitHub has created it, or nil when it is
+// not ready within forkWait.
+func waitForFork(client repoClient, orgName, name string) *github.FullRepo {
+	deadline := time.Now().Add(forkWait)
+	for {
+		repo, err := client.GetRepo(orgName, name)
+		if err == nil {
+			return &repo
+		}
+		if time.Now().Add(forkPoll).After(deadline) {
+			logrus.WithError(err).Debugf("Fork %s/%s is not ready", orgName, name)
+			return nil
+		}
+		time.Sleep(forkPoll)
+	}
+}
+
+// createRepo creates a missing repo from its template, as a fork or from scratch.
+// The declared settings the template and fork endpoints do not accept are
+// applied by the update following the creation.
+func createRepo(client repoClient, orgName, name string, repo config.Repo) (*github.FullRepo, error) {
+	switch {
+	case repo.OnCreate != nil && repo.OnCreate.Template != nil:
+		owner, template, err := splitRepoName(*repo.OnCreate.Template)
+		if err != nil {
+			return nil, err
+		}
+		return client.GenerateRepo(owner, template, ghclient.GenerateRepoRequest{
+			Owner:              orgName,
+			Name:               name,
+			Description:        repo.Description,
+			IncludeAllBranches: repo.OnCreate.IncludeAllBranches,
+			Private:            repo.Private,
+		})
+	case repo.OnCreate != nil && repo.OnCreate.ForkOf != nil:
+		owner, upstream, err := splitRepoName(*repo.OnCreate.ForkOf)
+		if err != nil {
+			return nil, err
+		}
+		return client.ForkRepo(owner, upstream, ghclient.ForkRepoRequest{
+			Organization: orgName,
+			Name:         name,
+		})
+	}
+	return client.CreateRepo(orgName, false, newRepoCreateRequest(name, repo))
+}
+
+func newRepoCreateRequest(name string, definition config.Repo) github.RepoCreateRequest {
 	repoCreate := github.RepoCreateRequest{
 		RepoRequest: github.RepoRequest{
 			Name:                     &name,
@@ -210,7 +533,9 @@ func newRepoUpdateRequest(current github.FullRepo, name string, repo org.Repo) g
 	return repoUpdate
 }
 
-func sanitizeRepoDelta(opt root.Options, delta *github.RepoUpdateRequest) []error {
+// sanitizeRepoDelta removes the changes of delta which are not allowed, and
+// skips the high-risk changes which are not approved.
+func sanitizeRepoDelta(opt root.Options, approved approvals, orgName, repoName string, delta *github.RepoUpdateRequest) []error {
 	var errs []error
 	if delta.Archived != nil && !*delta.Archived {
 		delta.Archived = nil
@@ -224,6 +549,12 @@ func sanitizeRepoDelta(opt root.Options, delta *github.RepoUpdateRequest) []erro
 		delta.Private = nil
 		errs = append(errs, fmt.Errorf("asked to publish a private repo but this is not allowed by default (see --allow-repo-publish)"))
 	}
+	if delta.Archived != nil && !approved.allow(changeRepoArchive, orgName, repoName) {
+		delta.Archived = nil
+	}
+	if delta.Private != nil && !*delta.Private && !approved.allow(changeRepoPublish, orgName, repoName) {
+		delta.Private = nil
+	}
 
 	return errs
 }
@@ -0,0 +1,321 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+type customRepoRoleClient interface {
+	ListCustomRepoRoles(org string) ([]ghclient.CustomRepoRole, error)
+	CreateCustomRepoRole(org string, role ghclient.CustomRepoRole) error
+	UpdateCustomRepoRole(org string, role ghclient.CustomRepoRole) error
+	DeleteCustomRepoRole(org string, id int) error
+}
+
+// configureCustomRepoRoles creates, updates and deletes the custom
+// repository roles of the org until they match want. Roles are not managed
+// when want is nil. Undeclared roles are only deleted once approved, and
+// protected roles are never deleted.
+func configureCustomRepoRoles(opt root.Options, client customRepoRoleClient, orgName string, want map[string]config.CustomRepoRole, protected protection) error {
+	if want == nil {
+		return nil
+	}
+	roles, err := client.ListCustomRepoRoles(orgName)
+	if err != nil {
+		return fmt.Errorf("failed to list %s custom repo roles: %w", orgName, err)
+	}
+	have := map[string]ghclient.CustomRepoRole{}
+	for _, role := range roles {
+		have[role.Name] = role
+	}
+
+	approved := newApprovals(opt)
+	var errs []error
+	for _, name := range sets.List(sets.KeySet(want)) {
+		role := customRepoRole(name, want[name])
+		current, ok := have[name]
+		switch {
+		case !ok:
+			if err := client.CreateCustomRepoRole(orgName, role); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create custom repo role %s: %w", name, err))
+				continue
+			}
+			record(client, orgName, "org.repo_role.create", name, nil, role)
+		case !sameCustomRepoRole(current, role):
+			role.ID = current.ID
+			if err := client.UpdateCustomRepoRole(orgName, role); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update custom repo role %s: %w", name, err))
+				continue
+			}
+			record(client, orgName, "org.repo_role.update", name, current, role)
+		}
+	}
+	for _, role := range roles {
+		if _, ok := want[role.Name]; ok {
+			continue
+		}
+		if protected.repoRole(role.Name) {
+			logrus.Warnf("Not deleting protected custom repo role %s from %s", role.Name, orgName)
+			continue
+		}
+		if !approved.allow(changeRoleDelete, orgName, role.Name) {
+			continue
+		}
+		if err := client.DeleteCustomRepoRole(orgName, role.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete custom repo role %s: %w", role.Name, err))
+			continue
+		}
+		record(client, orgName, "org.repo_role.delete", role.Name, role, nil)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// customRepoRole returns the GitHub form of the declared role.
+func customRepoRole(name string, want config.CustomRepoRole) ghclient.CustomRepoRole {
+	role := ghclient.CustomRepoRole{
+		Name:        name,
+		BaseRole:    string(want.BaseRole),
+		Permissions: append([]string{}, want.Permissions...),
+	}
+	if want.Description != nil {
+		role.Description = *want.Description
+	}
+	sort.Strings(role.Permissions)
+	return role
+}
+
+// sameCustomRepoRole reports whether the roles grant the same permissions,
+// whatever their order.
+func sameCustomRepoRole(have, want ghclient.CustomRepoRole) bool {
+	return have.Description == want.Description && have.BaseRole == want.BaseRole &&
+		sets.New(have.Permissions...).Equal(sets.New(want.Permissions...))
+}
+
+// customRepoRoles returns the base roles of the custom repository roles
+// teams may be granted: the declared ones, plus the ones of the org when
+// a team is granted an undeclared role. The org is not queried otherwise,
+// as custom roles are not available to every org.
+func customRepoRoles(client customRepoRoleClient, orgName string, cfg config.Config) (map[string]github.RepoPermissionLevel, error) {
+	roles := map[string]github.RepoPermissionLevel{}
+	for name, role := range cfg.CustomRepoRoles {
+		roles[name] = role.BaseRole
+	}
+	if cfg.CustomRepoRoles != nil || !grantsUnknownRole(cfg.Teams) {
+		return roles, nil
+	}
+	have, err := client.ListCustomRepoRoles(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s custom repo roles: %w", orgName, err)
+	}
+	for _, role := range have {
+		roles[role.Name] = github.RepoPermissionLevel(role.BaseRole)
+	}
+	return roles, nil
+}
+
+// grantsUnknownRole reports whether a team is granted a role which is not
+// a permission level.
+func grantsUnknownRole(teams map[string]config.Team) bool {
+	for _, team := range teams {
+		for _, grant := range team.Repos {
+			if !config.IsPermissionLevel(grant.Permission) {
+				return true
+			}
+		}
+		if grantsUnknownRole(team.Children) {
+			return true
+		}
+	}
+	return false
+}
+
+type orgRoleClient interface {
+	ListOrgRoles(org string) ([]ghclient.OrgRole, error)
+	ListOrgRoleTeams(org string, id int) ([]github.Team, error)
+	AssignOrgRoleTeam(org, teamSlug string, id int) error
+	RemoveOrgRoleTeam(org, teamSlug string, id int) error
+}
+
+// configureOrgRoles assigns each organization role of want to the declared
+// teams only. Roles missing from want are left alone, as are read-only
+// teams.
+func configureOrgRoles(opt root.Options, client orgRoleClient, orgName string, want map[string][]string, githubTeams map[string]github.Team) error {
+	if len(want) == 0 {
+		return nil
+	}
+	scoped := newScope(opt)
+	roles, err := client.ListOrgRoles(orgName)
+	if err != nil {
+		return fmt.Errorf("failed to list %s org roles: %w", orgName, err)
+	}
+	ids := map[string]int{}
+	for _, role := range roles {
+		ids[role.Name] = role.ID
+	}
+
+	var errs []error
+	for _, roleName := range sets.List(sets.KeySet(want)) {
+		id, ok := ids[roleName]
+		if !ok {
+			errs = append(errs, fmt.Errorf("org role %s does not exist", roleName))
+			continue
+		}
+		wantSlugs := map[string]string{}
+		for _, name := range want[roleName] {
+			gt, ok := githubTeams[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("org role %s names unknown team %s", roleName, name))
+				continue
+			}
+			wantSlugs[gt.Slug] = name
+		}
+		teams, err := client.ListOrgRoleTeams(orgName, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list teams of org role %s: %w", roleName, err))
+			continue
+		}
+		haveSlugs := sets.New[string]()
+		for _, t := range teams {
+			haveSlugs.Insert(t.Slug)
+			if _, ok := wantSlugs[t.Slug]; ok || scoped.readOnlyTeam(t.Name) || scoped.readOnlyTeam(t.Slug) {
+				continue
+			}
+			if err := client.RemoveOrgRoleTeam(orgName, t.Slug, id); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove org role %s from team %s: %w", roleName, t.Slug, err))
+				continue
+			}
+			logrus.Infof("Removed org role %s from team %s", roleName, t.Slug)
+			record(client, orgName, "org.role.remove", roleName+"/"+t.Slug, roleName, nil)
+		}
+		for _, slug := range sets.List(sets.KeySet(wantSlugs)) {
+			if haveSlugs.Has(slug) || scoped.readOnlyTeam(wantSlugs[slug]) {
+				continue
+			}
+			if err := client.AssignOrgRoleTeam(orgName, slug, id); err != nil {
+				errs = append(errs, fmt.Errorf("failed to assign org role %s to team %s: %w", roleName, slug, err))
+				continue
+			}
+			logrus.Infof("Assigned org role %s to team %s", roleName, slug)
+			record(client, orgName, "org.role.assign", roleName+"/"+slug, nil, roleName)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type collaboratorClient interface {
+	ListRepoCollaborators(org, repo string) ([]ghclient.Collaborator, error)
+	ListRepoInvitations(org, repo string) ([]ghclient.RepoInvitation, error)
+	SetRepoCollaborator(org, repo, user, role string) error
+	RemoveRepoCollaborator(org, repo, user string) error
+}
+
+// configureCollaborators grants the collaborators of each repo declaring
+// them their role, and removes the undeclared direct collaborators. Invited
+// users count as collaborators. Protected users, and the collaborators of
+// protected repos, are neither removed nor demoted.
+func configureCollaborators(opt root.Options, client collaboratorClient, orgName string, orgConfig config.Config, protected protection) error {
+	scoped := newScope(opt)
+	var errs []error
+	for _, repoName := range sets.List(sets.KeySet(orgConfig.Repos)) {
+		if orgConfig.Repos[repoName].Collaborators == nil || scoped.readOnlyRepo(repoName) {
+			continue
+		}
+		if err := configureRepoCollaborators(opt, client, orgName, repoName, orgConfig, protected); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func configureRepoCollaborators(opt root.Options, client collaboratorClient, orgName, repoName string, orgConfig config.Config, protected protection) error {
+	collaborators, err := client.ListRepoCollaborators(orgName, repoName)
+	if err != nil && ghclient.IsNotFound(err) && !opt.Confirm {
+		logrus.Warnf("Running dry-run, repo %s does not exist yet, cannot retrieve its collaborators, ignoring...", repoName)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to list repo %s collaborators: %w", repoName, err)
+	}
+	invitations, err := client.ListRepoInvitations(orgName, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to list repo %s invitations: %w", repoName, err)
+	}
+
+	have := map[string]ghclient.Collaborator{}
+	for _, c := range collaborators {
+		have[github.NormLogin(c.Login)] = c
+	}
+	invited := sets.New[string]()
+	for _, i := range invitations {
+		invited.Insert(github.NormLogin(i.Invitee.Login))
+	}
+	want := map[string]github.RepoPermissionLevel{}
+	logins := map[string]string{}
+	for login, role := range orgConfig.Repos[repoName].Collaborators {
+		want[github.NormLogin(login)] = role
+		logins[github.NormLogin(login)] = login
+	}
+
+	var errs []error
+	for _, user := range sets.List(sets.KeySet(want)) {
+		role := want[user]
+		current, ok := have[user]
+		switch {
+		case !ok && invited.Has(user):
+			continue
+		case ok && current.RoleName == string(role):
+			continue
+		case ok && demotes(orgConfig.Level(github.RepoPermissionLevel(current.RoleName)), orgConfig.Level(role)) && (protected.user(user) || protected.repo(repoName)):
+			logrus.Warnf("Not lowering protected %s role of collaborator %s on repo %s to %s", current.RoleName, user, repoName, role)
+			continue
+		}
+		if err := client.SetRepoCollaborator(orgName, repoName, logins[user], string(role)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to grant %s to collaborator %s on repo %s: %w", role, user, repoName, err))
+			continue
+		}
+		var before interface{}
+		if ok {
+			before = current.RoleName
+		}
+		record(client, orgName, "repo.collaborator.set", repoName+"/"+user, before, role)
+	}
+	for _, user := range sets.List(sets.KeySet(have)) {
+		if _, ok := want[user]; ok {
+			continue
+		}
+		if protected.user(user) || protected.repo(repoName) {
+			logrus.Warnf("Not removing protected collaborator %s from repo %s", user, repoName)
+			continue
+		}
+		if err := client.RemoveRepoCollaborator(orgName, repoName, have[user].Login); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove collaborator %s from repo %s: %w", user, repoName, err))
+			continue
+		}
+		record(client, orgName, "repo.collaborator.remove", repoName+"/"+user, have[user].RoleName, nil)
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+// scope holds the glob patterns of the read-only and ignored teams and
+// repos. The zero value manages everything.
+type scope struct {
+	readOnly config.Patterns
+	ignored  config.Patterns
+}
+
+func newScope(opt root.Options) scope {
+	return scope{readOnly: opt.ReadOnly, ignored: opt.Ignored}
+}
+
+// readOnlyTeam reports whether the team must not be created, changed nor
+// deleted.
+func (s scope) readOnlyTeam(name string) bool {
+	return matchesAny(name, s.readOnly.Teams) || s.ignoredTeam(name)
+}
+
+func (s scope) ignoredTeam(name string) bool {
+	return matchesAny(name, s.ignored.Teams)
+}
+
+// readOnlyRepo reports whether the repo must not be created, changed nor
+// deleted.
+func (s scope) readOnlyRepo(name string) bool {
+	return matchesAny(name, s.readOnly.Repos) || s.ignoredRepo(name)
+}
+
+func (s scope) ignoredRepo(name string) bool {
+	return matchesAny(name, s.ignored.Repos)
+}
+
+// readOnlyTeamRepo reports whether the permission of the team on the repo
+// must not be changed.
+func (s scope) readOnlyTeamRepo(team, repo string) bool {
+	return matchesAny(team+"/"+repo, s.readOnly.TeamRepos) || s.ignoredTeamRepo(team, repo)
+}
+
+// ignoredTeamRepo reports whether the permission of the team on the repo
+// is hidden from the run.
+func (s scope) ignoredTeamRepo(team, repo string) bool {
+	return s.ignoredRepo(repo) || matchesAny(team+"/"+repo, s.ignored.TeamRepos)
+}
+
+// filter returns the config without the ignored teams and repos.
+func (s scope) filter(cfg config.Config) config.Config {
+	if len(s.ignored.Teams) > 0 {
+		cfg.Teams = s.filterTeams(cfg.Teams)
+	}
+	if len(s.ignored.Repos) > 0 {
+		repos := make(map[string]config.Repo, len(cfg.Repos))
+		for name, repo := range cfg.Repos {
+			if !s.ignoredRepo(name) {
+				repos[name] = repo
+			}
+		}
+		cfg.Repos = repos
+	}
+	return cfg
+}
+
+func (s scope) filterTeams(teams map[string]config.Team) map[string]config.Team {
+	if teams == nil {
+		return nil
+	}
+	out := make(map[string]config.Team, len(teams))
+	for name, team := range teams {
+		if s.ignoredTeam(name) {
+			continue
+		}
+		team.Children = s.filterTeams(team.Children)
+		out[name] = team
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/yaml"
+)
+
+// Snapshot is the state of an org observed before applying a config.
+type Snapshot struct {
+	Org  string    `json:"org"`
+	Time time.Time `json:"time"`
+	// Config is the dumped org.
+	Config config.Config `json:"config"`
+	// Invitations are the users with a pending org invitation.
+	Invitations []string `json:"invitations,omitempty"`
+}
+
+type snapshotClient interface {
+	dumpClient
+	ListOrgInvitations(org string) ([]github.OrgInvitation, error)
+}
+
+// TakeSnapshot dumps the org along with its pending invitations. Ignored
+// resources are left out, and remain ignored when rolling back.
+func TakeSnapshot(client snapshotClient, orgName string, ignoreSecretTeams bool, appID string, ignored config.Patterns) (*Snapshot, error) {
+	cfg, err := Dump(client, orgName, ignoreSecretTeams, appID, ignored)
+	if err != nil {
+		return nil, err
+	}
+	invitations, err := client.ListOrgInvitations(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s invitations: %w", orgName, err)
+	}
+	snapshot := Snapshot{Org: orgName, Time: time.Now().UTC(), Config: *cfg}
+	for _, i := range invitations {
+		if i.Login != "" {
+			snapshot.Invitations = append(snapshot.Invitations, i.Login)
+		}
+	}
+	sort.Strings(snapshot.Invitations)
+	return &snapshot, nil
+}
+
+// Write writes the snapshot to a timestamped file of dir and returns its path.
+func (s Snapshot) Write(dir string) (string, error) {
+	raw, err := yaml.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s snapshot: %w", s.Org, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot dir: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", s.Org, s.Time.Format("20060102T150405Z")))
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write %s snapshot: %w", s.Org, err)
+	}
+	return path, nil
+}
+
+// ReadSnapshot reads a snapshot written by Snapshot.Write.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var s Snapshot
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("failed to load snapshot %s: %w", path, err)
+	}
+	if s.Org == "" {
+		return nil, fmt.Errorf("snapshot %s does not name an org", path)
+	}
+	return &s, nil
+}
+
+// RollbackConfig returns the config restoring the org to the snapshot.
+// Users who had a pending invitation are invited again as members.
+// Webhooks are left alone, as their secrets cannot be dumped.
+func (s Snapshot) RollbackConfig() config.Config {
+	cfg := s.Config
+	cfg.Webhooks = nil
+	cfg.Repos = make(map[string]config.Repo, len(s.Config.Repos))
+	for name, repo := range s.Config.Repos {
+		repo.Webhooks = nil
+		cfg.Repos[name] = repo
+	}
+
+	cfg.Members = slices.Clip(cfg.Members)
+	declared := sets.Set[string]{}
+	for _, m := range append(append([]config.Member{}, cfg.Admins...), cfg.Members...) {
+		declared.Insert(github.NormLogin(m.Login))
+	}
+	for _, login := range s.Invitations {
+		if !declared.Has(github.NormLogin(login)) {
+			cfg.Members = append(cfg.Members, config.Member{Login: login})
+		}
+	}
+	return cfg
+}
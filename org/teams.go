@@ -17,7 +17,9 @@ limitations under the License.
 package org
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -26,6 +28,9 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/internal/journal"
 	"github.com/uwu-tools/peribolos/options/root"
 )
 
@@ -36,7 +41,7 @@ type teamClient interface {
 }
 
 // configureTeams returns the ids for all expected team names, creating/deleting teams as necessary.
-func configureTeams(client teamClient, orgName string, orgConfig org.Config, maxDelta float64, ignoreSecretTeams bool) (map[string]github.Team, error) {
+func configureTeams(client teamClient, orgName string, orgConfig org.Config, maxDelta float64, ignoreSecretTeams bool, protected protection, approved approvals, scoped scope, locked map[string]int) (map[string]github.Team, error) {
 	if err := validateTeamNames(orgConfig); err != nil {
 		return nil, err
 	}
@@ -53,6 +58,9 @@ func configureTeams(client teamClient, orgName string, orgConfig org.Config, max
 		if ignoreSecretTeams && org.Privacy(t.Privacy) == org.Secret {
 			continue
 		}
+		if scoped.ignoredTeam(t.Name) || scoped.ignoredTeam(t.Slug) {
+			continue
+		}
 		teams[t.Slug] = t
 		slugs.Insert(t.Slug)
 	}
@@ -60,36 +68,17 @@ func configureTeams(client teamClient, orgName string, orgConfig org.Config, max
 		logrus.Debugf("Found %d non-secret teams", len(teamList))
 	}
 
-	// What is the lowest ID for each team?
-	older := map[string][]github.Team{}
-	names := map[string]github.Team{}
-	for _, t := range teams {
-		logger := logrus.WithFields(logrus.Fields{"id": t.ID, "name": t.Name})
-		n := t.Name
-		switch val, ok := names[n]; {
-		case !ok: // first occurrence of the name
-			logger.Debug("First occurrence of this team name.")
-			names[n] = t
-		case ok && t.ID < val.ID: // t has the lower ID, replace and send current to older set
-			logger.Debugf("Replacing previous recorded team (%d) with this one due to smaller ID.", val.ID)
-			names[n] = t
-			older[n] = append(older[n], val)
-		default: // t does not have smallest id, add it to older set
-			logger.Debugf("Adding team (%d) to older set as a smaller ID is already recoded for it.", val.ID)
-			older[n] = append(older[n], val)
-		}
-	}
-
 	// What team are we using for each configured name, and which names are missing?
 	matches := map[string]github.Team{}
 	missing := map[string]org.Team{}
 	used := sets.Set[string]{}
+	matcher := newTeamMatcher(teams, locked)
 	var match func(teams map[string]org.Team)
 	match = func(teams map[string]org.Team) {
 		for name, orgTeam := range teams {
 			logger := logrus.WithField("name", name)
 			match(orgTeam.Children)
-			t := findTeam(names, name, orgTeam.Previously...)
+			t := matcher.match(name, orgTeam.Previously...)
 			if t == nil {
 				missing[name] = orgTeam
 				logger.Debug("Could not find team in GitHub for this configuration.")
@@ -104,6 +93,15 @@ func configureTeams(client teamClient, orgName string, orgConfig org.Config, max
 
 	// First compute teams we will delete, ensure we are not deleting too many
 	unused := slugs.Difference(used)
+	for slug := range unused {
+		if protected.team(teams[slug].Name) || protected.team(slug) {
+			logrus.Warnf("Not deleting protected team %s(%s) from %s", slug, teams[slug].Name, orgName)
+			unused.Delete(slug)
+		} else if scoped.readOnlyTeam(teams[slug].Name) || scoped.readOnlyTeam(slug) {
+			logrus.Infof("Not deleting read-only team %s(%s) from %s", slug, teams[slug].Name, orgName)
+			unused.Delete(slug)
+		}
+	}
 	if delta := float64(len(unused)) / float64(len(slugs)); delta > maxDelta {
 		return nil, fmt.Errorf("cannot delete %d teams or %.3f of %s teams (exceeds limit of %.3f)", len(unused), delta, orgName, maxDelta)
 	}
@@ -111,6 +109,10 @@ func configureTeams(client teamClient, orgName string, orgConfig org.Config, max
 	// Create any missing team names
 	var failures []string
 	for name, orgTeam := range missing {
+		if scoped.readOnlyTeam(name) {
+			logrus.Warnf("Not creating read-only team %s in %s", name, orgName)
+			continue
+		}
 		t := &github.Team{Name: name}
 		if orgTeam.Description != nil {
 			t.Description = *orgTeam.Description
@@ -124,6 +126,7 @@ func configureTeams(client teamClient, orgName string, orgConfig org.Config, max
 			failures = append(failures, name)
 			continue
 		}
+		record(client, orgName, "team.create", name, nil, t)
 		matches[name] = *t
 		// t.Slug may include a slug already present in slugs if other actors are deleting teams.
 		used.Insert(t.Slug)
@@ -143,10 +146,15 @@ func configureTeams(client teamClient, orgName string, orgConfig org.Config, max
 	}
 	// Delete undeclared teams.
 	for slug := range unused {
+		if !approved.allow(changeTeamDelete, orgName, slug) {
+			continue
+		}
 		if err := client.DeleteTeamBySlug(orgName, slug); err != nil {
 			str := fmt.Sprintf("%s(%s)", slug, teams[slug].Name)
 			logrus.WithError(err).Warnf("Failed to delete team %s from %s", str, orgName)
 			failures = append(failures, str)
+		} else {
+			record(client, orgName, "team.delete", slug, teams[slug], nil)
 		}
 	}
 	if n := len(failures); n > 0 {
@@ -182,6 +190,67 @@ func validateTeamNames(orgConfig org.Config) error {
 	return nil
 }
 
+// teamMatcher matches configured teams with the teams of the org, by
+// their locked ID first, then by their current or previous names.
+type teamMatcher struct {
+	locked map[string]int
+	byID   map[int]github.Team
+	// names are the teams with the lowest ID of each name, older are the
+	// other teams of the same name.
+	names map[string]github.Team
+	older map[string][]github.Team
+}
+
+func newTeamMatcher(teams map[string]github.Team, locked map[string]int) teamMatcher {
+	m := teamMatcher{
+		locked: locked,
+		byID:   map[int]github.Team{},
+		names:  map[string]github.Team{},
+		older:  map[string][]github.Team{},
+	}
+	// What is the lowest ID for each team?
+	for _, t := range teams {
+		m.byID[t.ID] = t
+		logger := logrus.WithFields(logrus.Fields{"id": t.ID, "name": t.Name})
+		n := t.Name
+		switch val, ok := m.names[n]; {
+		case !ok: // first occurrence of the name
+			logger.Debug("First occurrence of this team name.")
+			m.names[n] = t
+		case ok && t.ID < val.ID: // t has the lower ID, replace and send current to older set
+			logger.Debugf("Replacing previous recorded team (%d) with this one due to smaller ID.", val.ID)
+			m.names[n] = t
+			m.older[n] = append(m.older[n], val)
+		default: // t does not have smallest id, add it to older set
+			logger.Debugf("Adding team (%d) to older set as a smaller ID is already recoded for it.", val.ID)
+			m.older[n] = append(m.older[n], t)
+		}
+	}
+	return m
+}
+
+// match returns the team locked for name if it still exists, or the team
+// with the lowest ID of the first name found. Teams sharing that name are
+// reported, since only the lockfile pins which one is meant.
+func (m teamMatcher) match(name string, previousNames ...string) *github.Team {
+	if id, ok := m.locked[name]; ok {
+		if t, ok := m.byID[id]; ok {
+			return &t
+		}
+		logrus.Warnf("Locked team %d of %s no longer exists, matching it by name", id, name)
+	}
+	t := findTeam(m.names, name, previousNames...)
+	if t != nil && len(m.older[t.Name]) > 0 {
+		ids := []int{}
+		for _, o := range m.older[t.Name] {
+			ids = append(ids, o.ID)
+		}
+		sort.Ints(ids)
+		logrus.Warnf("Teams %v share the name %s with team %d, using the oldest one (see peribolos lock)", ids, t.Name, t.ID)
+	}
+	return t
+}
+
 // findTeam returns teams[n] for the first n in [name, previousNames, ...] that is in teams.
 func findTeam(teams map[string]github.Team, name string, previousNames ...string) *github.Team {
 	if t, ok := teams[name]; ok {
@@ -195,31 +264,58 @@ func findTeam(teams map[string]github.Team, name string, previousNames ...string
 	return nil
 }
 
-func configureTeamAndMembers(opt root.Options, client github.Client, githubTeams map[string]github.Team, name, orgName string, team org.Team, parent *int) error {
+type teamAndMembersClient interface {
+	github.Client
+	teamSyncClient
+	editTeamClient
+}
+
+func configureTeamAndMembers(opt root.Options, client teamAndMembersClient, githubTeams map[string]github.Team, name, orgName string, team org.Team, parent *int, protected protection, synced teamSync, sourced map[string]origins, settings map[string]config.TeamSettings) error {
+	readOnly := newScope(opt).readOnlyTeam(name)
 	gt, ok := githubTeams[name]
-	if !ok { // configureTeams is buggy if this is the case
+	if !ok && readOnly {
+		logrus.Infof("Skipping missing read-only team %s", name)
+		return nil
+	} else if !ok { // configureTeams is buggy if this is the case
 		return fmt.Errorf("%s not found in id list", name)
 	}
 
-	// Configure team metadata
-	err := configureTeam(client, orgName, name, team, gt, parent)
-	if err != nil {
-		return fmt.Errorf("failed to update %s metadata: %w", name, err)
-	}
+	var err error
+	if readOnly {
+		logrus.Infof("Skipping read-only team %s", name)
+	} else {
+		// Configure team metadata
+		err = configureTeam(client, orgName, name, team, gt, parent)
+		if err != nil {
+			return fmt.Errorf("failed to update %s metadata: %w", name, err)
+		}
+		if err = configureTeamSettings(opt, client, orgName, gt, settings[name]); err != nil {
+			return err
+		}
 
-	// Configure team members
-	if !opt.FixTeamMembers {
-		logrus.Infof("Skipping %s member configuration", name)
-	} else if err = configureTeamMembers(opt, client, orgName, gt, team, opt.IgnoreInvitees); err != nil {
-		if opt.Confirm {
-			return fmt.Errorf("failed to update %s members: %w", name, err)
+		// Link the team to IdP groups, which then manage its members.
+		if groups, ok := synced.linked[name]; ok && opt.FixTeamMembers {
+			if err = configureTeamSync(opt, client, orgName, gt, groups, synced.available); err != nil {
+				return err
+			}
+		}
+
+		// Configure team members
+		if !opt.FixTeamMembers {
+			logrus.Infof("Skipping %s member configuration", name)
+		} else if synced.synchronized(name) {
+			logrus.Infof("Skipping %s member configuration, members are synchronized with IdP groups", name)
+		} else if err = configureTeamMembers(opt, client, orgName, gt, team, opt.IgnoreInvitees, protected, sourced[name]); err != nil {
+			if opt.Confirm {
+				return fmt.Errorf("failed to update %s members: %w", name, err)
+			}
+			logrus.WithError(err).Warnf("failed to update %s members: %s", name, err)
+			return nil
 		}
-		logrus.WithError(err).Warnf("failed to update %s members: %s", name, err)
-		return nil
 	}
 
 	for childName, childTeam := range team.Children {
-		err = configureTeamAndMembers(opt, client, githubTeams, childName, orgName, childTeam, &gt.ID)
+		err = configureTeamAndMembers(opt, client, githubTeams, childName, orgName, childTeam, &gt.ID, protected, synced, sourced, settings)
 		if err != nil {
 			return fmt.Errorf("failed to update %s child teams: %w", name, err)
 		}
@@ -230,10 +326,17 @@ func configureTeamAndMembers(opt root.Options, client github.Client, githubTeams
 
 type editTeamClient interface {
 	EditTeam(org string, team github.Team) (*github.Team, error)
+	GetTeamSettings(org, teamSlug string) (*ghclient.TeamSettings, error)
+	EditTeamSettings(org, teamSlug string, settings ghclient.TeamSettings) error
+	GetTeamReviewAssignment(org, teamSlug string) (*ghclient.ReviewAssignment, error)
+	SetTeamReviewAssignment(org, teamSlug string, assignment ghclient.ReviewAssignment, excluded []string) error
+	ListTeamReviewExcludedMembers(org, teamSlug string) ([]string, bool, error)
 }
 
-// configureTeam patches the team name/description/privacy when values differ.
+// configureTeam patches the team name/description/privacy when values differ,
+// then the declared settings.
 func configureTeam(client editTeamClient, orgName, teamName string, team org.Team, gt github.Team, parent *int) error {
+	before := gt
 	// Do we need to reconfigure any team settings?
 	patch := false
 	if gt.Name != teamName {
@@ -275,10 +378,129 @@ func configureTeam(client editTeamClient, orgName, teamName string, team org.Tea
 		if _, err := client.EditTeam(orgName, gt); err != nil {
 			return fmt.Errorf("failed to edit %s team %s(%s): %w", orgName, gt.Slug, gt.Name, err)
 		}
+		record(client, orgName, "team.edit", gt.Slug, before, gt)
+	}
+	return nil
+}
+
+// configureTeamSettings updates the declared settings of the team which
+// differ. The members excluded from the review assignment are compared with
+// the ones GitHub reports, or with the ones last recorded in the journal when
+// the API does not report them.
+func configureTeamSettings(opt root.Options, client editTeamClient, orgName string, gt github.Team, want config.TeamSettings) error {
+	if want.NotificationSetting != nil || want.Permission != nil {
+		cur, err := client.GetTeamSettings(orgName, gt.Slug)
+		if err != nil && ghclient.IsNotFound(err) && !opt.Confirm {
+			logrus.Warnf("Running dry-run, team %s does not exist yet, cannot retrieve its settings, ignoring...", gt.Slug)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to get %s team %s settings: %w", orgName, gt.Slug, err)
+		}
+		var edit ghclient.TeamSettings
+		if want.NotificationSetting != nil && (cur.NotificationSetting == nil || *cur.NotificationSetting != *want.NotificationSetting) {
+			edit.NotificationSetting = want.NotificationSetting
+		}
+		if want.Permission != nil && (cur.Permission == nil || *cur.Permission != *want.Permission) {
+			edit.Permission = want.Permission
+		}
+		if edit != (ghclient.TeamSettings{}) {
+			if err := client.EditTeamSettings(orgName, gt.Slug, edit); err != nil {
+				return fmt.Errorf("failed to edit %s team %s settings: %w", orgName, gt.Slug, err)
+			}
+			record(client, orgName, "team.settings.edit", gt.Slug, *cur, edit)
+		}
+	}
+
+	if want.ReviewAssignment == nil {
+		return nil
+	}
+	cur, err := client.GetTeamReviewAssignment(orgName, gt.Slug)
+	if err != nil && ghclient.IsNotFound(err) && !opt.Confirm {
+		logrus.Warnf("Running dry-run, team %s does not exist yet, cannot retrieve its review assignment, ignoring...", gt.Slug)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get %s team %s review assignment: %w", orgName, gt.Slug, err)
+	}
+	assignment := reviewAssignment(*want.ReviewAssignment)
+	excluded := want.ReviewAssignment.ExcludedMembers
+	if sameReviewAssignment(*cur, assignment) {
+		if !assignment.Enabled {
+			return nil
+		}
+		same, err := sameExcluded(opt, client, orgName, gt.Slug, excluded)
+		if err != nil {
+			return fmt.Errorf("failed to get %s team %s review assignment excluded members: %w", orgName, gt.Slug, err)
+		}
+		if same {
+			return nil
+		}
 	}
+	if err := client.SetTeamReviewAssignment(orgName, gt.Slug, assignment, excluded); err != nil {
+		return fmt.Errorf("failed to set %s team %s review assignment: %w", orgName, gt.Slug, err)
+	}
+	record(client, orgName, changeReviewAssignment, gt.Slug, *cur, *want.ReviewAssignment)
 	return nil
 }
 
+// changeReviewAssignment is the journaled change of a team review
+// assignment, which records the excluded members.
+const changeReviewAssignment = "team.review_assignment.set"
+
+// sameExcluded reports whether the excluded members are the ones excluded
+// from the review assignment of the team. When the API does not report them,
+// they are compared with the ones last applied according to the journal, or
+// with none without a journal entry.
+func sameExcluded(opt root.Options, client editTeamClient, orgName, slug string, excluded []string) (bool, error) {
+	current, ok, err := client.ListTeamReviewExcludedMembers(orgName, slug)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		return normalize(sets.New(current...)).Equal(normalize(sets.New(excluded...))), nil
+	}
+	logrus.Debugf("GitHub does not report the excluded members of team %s, comparing with the journal", slug)
+	entry, ok := opt.Journaled[journal.Key{Org: orgName, Change: changeReviewAssignment, Target: slug}]
+	if !ok {
+		return len(excluded) == 0, nil
+	}
+	raw, err := json.Marshal(entry.After)
+	if err != nil {
+		return false, nil
+	}
+	var last config.ReviewAssignment
+	if err := json.Unmarshal(raw, &last); err != nil {
+		return false, nil
+	}
+	return normalize(sets.New(last.ExcludedMembers...)).Equal(normalize(sets.New(excluded...))), nil
+}
+
+// reviewAssignment returns the GitHub form of the declared assignment,
+// filling in the defaults.
+func reviewAssignment(want config.ReviewAssignment) ghclient.ReviewAssignment {
+	a := ghclient.ReviewAssignment{
+		Enabled:     want.Enabled == nil || *want.Enabled,
+		Algorithm:   strings.ToUpper(want.Algorithm),
+		MemberCount: want.MemberCount,
+		NotifyTeam:  want.NotifyTeam,
+	}
+	if a.Algorithm == "" {
+		a.Algorithm = strings.ToUpper(config.RoundRobin)
+	}
+	if a.MemberCount == 0 {
+		a.MemberCount = 1
+	}
+	return a
+}
+
+// sameReviewAssignment reports whether the assignments route reviews the
+// same way. The other settings of disabled assignments do not matter.
+func sameReviewAssignment(have, want ghclient.ReviewAssignment) bool {
+	if !have.Enabled || !want.Enabled {
+		return have.Enabled == want.Enabled
+	}
+	return have == want
+}
+
 // teamMembersClient can list/remove/update people to a team.
 type teamMembersClient interface {
 	ListTeamMembersBySlug(org, teamSlug, role string) ([]github.TeamMember, error)
@@ -288,7 +510,8 @@ type teamMembersClient interface {
 }
 
 // configureTeamMembers will add/update people to the appropriate role on the team, and remove anyone else.
-func configureTeamMembers(opt root.Options, client teamMembersClient, orgName string, gt github.Team, team org.Team, ignoreInvitees bool) error {
+// Protected users are not removed nor demoted.
+func configureTeamMembers(opt root.Options, client teamMembersClient, orgName string, gt github.Team, team org.Team, ignoreInvitees bool, protected protection, sourced origins) error {
 	// Get desired state
 	wantMaintainers := sets.New[string](team.Maintainers...)
 	wantMembers := sets.New[string](team.Members...)
@@ -327,6 +550,9 @@ func configureTeamMembers(opt root.Options, client teamMembersClient, orgName st
 		}
 	}
 
+	want := memberships{members: wantMembers, super: wantMaintainers}
+	have := memberships{members: haveMembers, super: haveMaintainers}
+	have.normalize()
 	adder := func(user string, super bool) error {
 		if invitees.Has(user) {
 			logrus.Infof("Waiting for %s to accept invitation to %s(%s)", user, gt.Slug, gt.Name)
@@ -336,20 +562,30 @@ func configureTeamMembers(opt root.Options, client teamMembersClient, orgName st
 		if super {
 			role = github.RoleMaintainer
 		}
+		if !super && have.super.Has(user) && protected.user(user) {
+			logrus.Warnf("Not demoting protected maintainer %s of %s(%s)", user, gt.Slug, gt.Name)
+			return nil
+		}
 		tm, err := client.UpdateTeamMembershipBySlug(orgName, gt.Slug, user, super)
 		if err != nil {
 			// Augment the error with the operation we attempted so that the error makes sense after return
 			err = fmt.Errorf("UpdateTeamMembership(%s(%s), %s, %t) failed: %w", gt.Slug, gt.Name, user, super, err)
 			logrus.Warnf("%s", err.Error())
 		} else if tm.State == github.StatePending {
-			logrus.Infof("Invited %s to %s(%s) as a %s", user, gt.Slug, gt.Name, role)
+			logrus.Infof("Invited %s to %s(%s) as a %s%s", user, gt.Slug, gt.Name, role, sourced.from(user))
+			record(client, orgName, "team.membership.invite", gt.Slug+"/"+user, roleOf(have, user, github.RoleMaintainer), role)
 		} else {
-			logrus.Infof("Set %s as a %s of %s(%s)", user, role, gt.Slug, gt.Name)
+			logrus.Infof("Set %s as a %s of %s(%s)%s", user, role, gt.Slug, gt.Name, sourced.from(user))
+			record(client, orgName, "team.membership.set", gt.Slug+"/"+user, roleOf(have, user, github.RoleMaintainer), role)
 		}
 		return err
 	}
 
 	remover := func(user string) error {
+		if protected.user(user) {
+			logrus.Warnf("Not removing protected user %s from team %s(%s)", user, gt.Slug, gt.Name)
+			return nil
+		}
 		err := client.RemoveTeamMembershipBySlug(orgName, gt.Slug, user)
 		if err != nil {
 			// Augment the error with the operation we attempted so that the error makes sense after return
@@ -357,12 +593,11 @@ func configureTeamMembers(opt root.Options, client teamMembersClient, orgName st
 			logrus.Warnf("%s", err.Error())
 		} else {
 			logrus.Infof("Removed %s from team %s(%s)", user, gt.Slug, gt.Name)
+			record(client, orgName, "team.membership.remove", gt.Slug+"/"+user, roleOf(have, user, github.RoleMaintainer), nil)
 		}
 		return err
 	}
 
-	want := memberships{members: wantMembers, super: wantMaintainers}
-	have := memberships{members: haveMembers, super: haveMaintainers}
 	return configureMembers(have, want, invitees, adder, remover)
 }
 
@@ -385,16 +620,30 @@ type teamRepoClient interface {
 	ListTeamReposBySlug(org, teamSlug string) ([]github.Repo, error)
 	UpdateTeamRepoBySlug(org, teamSlug, repo string, permission github.TeamPermission) error
 	RemoveTeamRepoBySlug(org, teamSlug, repo string) error
+	ListTeamRepoRoles(org, teamSlug string) ([]ghclient.TeamRepoRole, error)
 }
 
 // configureTeamRepos updates the list of repos that the team has permissions for when necessary.
-func configureTeamRepos(opt root.Options, client teamRepoClient, githubTeams map[string]github.Team, name, orgName string, team org.Team) error {
+// Permissions of protected teams and on protected repos are not removed nor lowered.
+// customRoles are the base roles of the custom repository roles teams may be granted.
+func configureTeamRepos(opt root.Options, client teamRepoClient, githubTeams map[string]github.Team, name, orgName string, team org.Team, protected protection, customRoles map[string]github.RepoPermissionLevel) error {
+	scoped := newScope(opt)
+	if scoped.readOnlyTeam(name) {
+		logrus.Infof("Skipping repo permissions of read-only team %s", name)
+		return nil
+	}
 	gt, ok := githubTeams[name]
 	if !ok { // configureTeams is buggy if this is the case
 		return fmt.Errorf("%s not found in id list", name)
 	}
 
-	want := team.Repos
+	// Read-only and ignored permissions are left alone.
+	want := map[string]github.RepoPermissionLevel{}
+	for repo, permission := range team.Repos {
+		if !scoped.readOnlyTeamRepo(name, repo) {
+			want[repo] = permission
+		}
+	}
 	have := map[string]github.RepoPermissionLevel{}
 	repos, err := client.ListTeamReposBySlug(orgName, gt.Slug)
 	if err != nil && strings.Contains(err.Error(), "404") && !opt.Confirm {
@@ -403,8 +652,32 @@ func configureTeamRepos(opt root.Options, client teamRepoClient, githubTeams map
 		return fmt.Errorf("failed to list team %d(%s) repos: %w", gt.ID, name, err)
 	}
 	for _, repo := range repos {
+		if scoped.readOnlyTeamRepo(name, repo.Name) {
+			continue
+		}
 		have[repo.Name] = github.LevelFromPermissions(repo.Permissions)
 	}
+	// The permissions of a custom role are those of its base role, only
+	// the role name tells them apart.
+	if len(customRoles) > 0 && len(have) > 0 {
+		roles, err := client.ListTeamRepoRoles(orgName, gt.Slug)
+		if err != nil {
+			return fmt.Errorf("failed to list team %d(%s) repo roles: %w", gt.ID, name, err)
+		}
+		for _, role := range roles {
+			if _, custom := customRoles[role.RoleName]; custom && have[role.Name] != "" {
+				have[role.Name] = github.RepoPermissionLevel(role.RoleName)
+			}
+		}
+	}
+	// level returns the permission level granted by permission, which may
+	// name a custom role.
+	level := func(permission github.RepoPermissionLevel) github.RepoPermissionLevel {
+		if base, ok := customRoles[string(permission)]; ok {
+			return base
+		}
+		return permission
+	}
 
 	actions := map[string]github.RepoPermissionLevel{}
 	for wantRepo, wantPermission := range want {
@@ -425,6 +698,10 @@ func configureTeamRepos(opt root.Options, client teamRepoClient, githubTeams map
 
 	var updateErrors []error
 	for repo, permission := range actions {
+		if havePermission, haveRepo := have[repo]; haveRepo && demotes(level(havePermission), level(permission)) && (protected.team(name) || protected.repo(repo)) {
+			logrus.Warnf("Not lowering protected %s permission of team %s(%s) on repo %s to %s", havePermission, gt.Slug, name, repo, permission)
+			continue
+		}
 		var err error
 		switch permission {
 		case github.None:
@@ -439,15 +716,33 @@ func configureTeamRepos(opt root.Options, client teamRepoClient, githubTeams map
 			err = client.UpdateTeamRepoBySlug(orgName, gt.Slug, repo, github.RepoTriage)
 		case github.Maintain:
 			err = client.UpdateTeamRepoBySlug(orgName, gt.Slug, repo, github.RepoMaintain)
+		default:
+			if _, ok := customRoles[string(permission)]; !ok {
+				err = fmt.Errorf("unknown role %s", permission)
+				break
+			}
+			err = client.UpdateTeamRepoBySlug(orgName, gt.Slug, repo, github.TeamPermission(permission))
 		}
 
 		if err != nil {
 			updateErrors = append(updateErrors, fmt.Errorf("failed to update team %d(%s) permissions on repo %s to %s: %w", gt.ID, name, repo, permission, err))
+			continue
+		}
+		var before, after interface{}
+		if havePermission, haveRepo := have[repo]; haveRepo {
+			before = havePermission
+		}
+		change := "team.repo.set"
+		if permission == github.None {
+			change = "team.repo.remove"
+		} else {
+			after = permission
 		}
+		record(client, orgName, change, gt.Slug+"/"+repo, before, after)
 	}
 
 	for childName, childTeam := range team.Children {
-		if err := configureTeamRepos(opt, client, githubTeams, childName, orgName, childTeam); err != nil {
+		if err := configureTeamRepos(opt, client, githubTeams, childName, orgName, childTeam, protected, customRoles); err != nil {
 			updateErrors = append(updateErrors, fmt.Errorf("failed to configure %s child team %s repos: %w", orgName, childName, err))
 		}
 	}
@@ -0,0 +1,115 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/ghclient"
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+type teamSyncClient interface {
+	ListOrgIdPGroups(org string) ([]ghclient.IdPGroup, error)
+	ListTeamIdPGroups(org, teamSlug string) ([]ghclient.IdPGroup, error)
+	SetTeamIdPGroups(org, teamSlug string, groups []ghclient.IdPGroup) error
+}
+
+// teamSync holds the IdP groups of the teams whose membership is
+// synchronized with an identity provider.
+type teamSync struct {
+	linked    map[string][]string
+	available func() (map[string]ghclient.IdPGroup, error)
+}
+
+// synchronized reports whether the membership of the team is synchronized with
+// IdP groups, and must not be reconciled.
+func (s teamSync) synchronized(name string) bool {
+	return len(s.linked[name]) > 0
+}
+
+// idpGroupsByName returns the IdP groups of the org by name, listing them
+// once.
+func idpGroupsByName(client teamSyncClient, orgName string) func() (map[string]ghclient.IdPGroup, error) {
+	var groups map[string]ghclient.IdPGroup
+	return func() (map[string]ghclient.IdPGroup, error) {
+		if groups != nil {
+			return groups, nil
+		}
+		list, err := client.ListOrgIdPGroups(orgName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s IdP groups: %w", orgName, err)
+		}
+		groups = make(map[string]ghclient.IdPGroup, len(list))
+		for _, g := range list {
+			groups[g.Name] = g
+		}
+		return groups, nil
+	}
+}
+
+// configureTeamSync links the team with the wanted IdP groups, replacing
+// its current groups.
+func configureTeamSync(opt root.Options, client teamSyncClient, orgName string, gt github.Team, want []string, available func() (map[string]ghclient.IdPGroup, error)) error {
+	have, err := client.ListTeamIdPGroups(orgName, gt.Slug)
+	if err != nil && ghclient.IsNotFound(err) && !opt.Confirm {
+		logrus.Warnf("Running dry-run, team %s does not exist yet, cannot retrieve IdP groups, ignoring...", gt.Slug)
+	} else if err != nil {
+		return fmt.Errorf("failed to list team %s IdP groups: %w", gt.Slug, err)
+	}
+	haveNames := sets.Set[string]{}
+	for _, g := range have {
+		haveNames.Insert(g.Name)
+	}
+	if haveNames.Equal(sets.New[string](want...)) {
+		return nil
+	}
+
+	var groups []ghclient.IdPGroup
+	if len(want) > 0 {
+		byName, err := available()
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, name := range want {
+			g, ok := byName[name]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			groups = append(groups, g)
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("team %s is linked to unknown IdP groups: %s", gt.Slug, strings.Join(missing, ", "))
+		}
+	}
+
+	logrus.Infof("Linking team %s to IdP groups %v", gt.Slug, want)
+	if err := client.SetTeamIdPGroups(orgName, gt.Slug, groups); err != nil {
+		return fmt.Errorf("failed to link team %s to IdP groups: %w", gt.Slug, err)
+	}
+	record(client, orgName, "team.idp_groups.set", gt.Slug, sets.List(haveNames), want)
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/config/org"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+type twoFactorClient interface {
+	ListOrgMembersWithout2FA(org string) ([]github.TeamMember, error)
+}
+
+// checkTwoFactor reports the org members without two-factor authentication,
+// split between the ones declared in the config and the others. It fails in
+// --two-factor=fail mode if any declared member lacks it, and returns the
+// declared members whose role is frozen in --two-factor=freeze mode. GitHub
+// only reports the two-factor authentication of members, so the users who
+// are not members yet are invited regardless.
+func checkTwoFactor(opt root.Options, client twoFactorClient, orgName string, orgConfig org.Config) (sets.Set[string], error) {
+	frozen := sets.Set[string]{}
+	if opt.TwoFactor == "" || opt.TwoFactor == root.TwoFactorIgnore {
+		return frozen, nil
+	}
+
+	ms, err := client.ListOrgMembersWithout2FA(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s members without two-factor authentication: %w", orgName, err)
+	}
+	without := sets.Set[string]{}
+	for _, m := range ms {
+		without.Insert(github.NormLogin(m.Login))
+	}
+	if len(without) == 0 {
+		logrus.Infof("All members of %s have enabled two-factor authentication", orgName)
+		return frozen, nil
+	}
+
+	declared := normalize(sets.New[string](orgConfig.Admins...).Insert(orgConfig.Members...))
+	inConfig := without.Intersection(declared)
+	undeclared := without.Difference(declared)
+	if n := len(inConfig); n > 0 {
+		logrus.Warnf("%d members declared in the %s config have not enabled two-factor authentication: %s", n, orgName, strings.Join(sets.List(inConfig), ", "))
+	}
+	if n := len(undeclared); n > 0 {
+		logrus.Warnf("%d members of %s missing from the config have not enabled two-factor authentication: %s", n, orgName, strings.Join(sets.List(undeclared), ", "))
+	}
+
+	switch opt.TwoFactor {
+	case root.TwoFactorFail:
+		if n := len(inConfig); n > 0 {
+			return nil, fmt.Errorf("%d members declared in the %s config have not enabled two-factor authentication (see --two-factor): %s", n, orgName, strings.Join(sets.List(inConfig), ", "))
+		}
+	case root.TwoFactorFreeze:
+		return inConfig, nil
+	}
+	return frozen, nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright RelEngFam Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package org
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+
+	"github.com/uwu-tools/peribolos/internal/config"
+	"github.com/uwu-tools/peribolos/options/root"
+)
+
+// webhookName is the only name GitHub accepts for webhooks.
+const webhookName = "web"
+
+type hookClient interface {
+	ListOrgHooks(org string) ([]github.Hook, error)
+	CreateOrgHook(org string, req github.HookRequest) (int, error)
+	EditOrgHook(org string, id int, req github.HookRequest) error
+	DeleteOrgHook(org string, id int, req github.HookRequest) error
+	ListRepoHooks(org, repo string) ([]github.Hook, error)
+	CreateRepoHook(org, repo string, req github.HookRequest) (int, error)
+	EditRepoHook(org, repo string, id int, req github.HookRequest) error
+	DeleteRepoHook(org, repo string, id int, req github.HookRequest) error
+}
+
+// configureWebhooks reconciles the org webhooks and the webhooks of every
+// repo that declares them.
+func configureWebhooks(opt root.Options, client hookClient, orgName string, orgConfig config.Config) error {
+	var errs []error
+	scoped := newScope(opt)
+
+	if orgConfig.Webhooks != nil {
+		have, err := client.ListOrgHooks(orgName)
+		if err != nil {
+			return fmt.Errorf("failed to list %s webhooks: %w", orgName, err)
+		}
+		err = configureHooks(have, orgConfig.Webhooks,
+			func(req github.HookRequest) error {
+				if _, err := client.CreateOrgHook(orgName, req); err != nil {
+					return err
+				}
+				record(client, orgName, "org.hook.create", req.Config.URL, nil, hookEntry(req))
+				return nil
+			},
+			func(id int, req github.HookRequest) error {
+				if err := client.EditOrgHook(orgName, id, req); err != nil {
+					return err
+				}
+				record(client, orgName, "org.hook.edit", req.Config.URL, nil, hookEntry(req))
+				return nil
+			},
+			func(hook github.Hook) error {
+				if err := client.DeleteOrgHook(orgName, hook.ID, github.HookRequest{}); err != nil {
+					return err
+				}
+				record(client, orgName, "org.hook.delete", hook.Config.URL, dumpHooks([]github.Hook{hook})[0], nil)
+				return nil
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to configure %s webhooks: %w", orgName, err))
+		}
+	}
+
+	for repoName, repo := range orgConfig.Repos {
+		if repo.Webhooks == nil || (repo.Delete != nil && *repo.Delete) || repo.TransferTo != nil {
+			continue
+		}
+		if scoped.readOnlyRepo(repoName) {
+			logrus.Infof("Skipping webhooks of read-only repo %s", repoName)
+			continue
+		}
+		have, err := client.ListRepoHooks(orgName, repoName)
+		if err != nil && strings.Contains(err.Error(), "404") && !opt.Confirm {
+			logrus.Warnf("Running dry-run, repo %s does not exist yet, cannot retrieve webhooks, ignoring...", repoName)
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list %s/%s webhooks: %w", orgName, repoName, err))
+			continue
+		}
+		err = configureHooks(have, repo.Webhooks,
+			func(req github.HookRequest) error {
+				if _, err := client.CreateRepoHook(orgName, repoName, req); err != nil {
+					return err
+				}
+				record(client, orgName, "repo.hook.create", repoName+" "+req.Config.URL, nil, hookEntry(req))
+				return nil
+			},
+			func(id int, req github.HookRequest) error {
+				if err := client.EditRepoHook(orgName, repoName, id, req); err != nil {
+					return err
+				}
+				record(client, orgName, "repo.hook.edit", repoName+" "+req.Config.URL, nil, hookEntry(req))
+				return nil
+			},
+			func(hook github.Hook) error {
+				if err := client.DeleteRepoHook(orgName, repoName, hook.ID, github.HookRequest{}); err != nil {
+					return err
+				}
+				record(client, orgName, "repo.hook.delete", repoName+" "+hook.Config.URL, dumpHooks([]github.Hook{hook})[0], nil)
+				return nil
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to configure %s/%s webhooks: %w", orgName, repoName, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// configureHooks creates, edits and deletes webhooks until have matches want.
+// Webhooks are matched by URL, duplicates and undeclared webhooks are deleted.
+func configureHooks(have []github.Hook, want []config.Webhook, create func(req github.HookRequest) error, edit func(id int, req github.HookRequest) error, remove func(hook github.Hook) error) error {
+	if err := validateWebhooks(want); err != nil {
+		return err
+	}
+
+	existing := map[string]github.Hook{}
+	var extra []github.Hook
+	for _, hook := range have {
+		if _, dup := existing[hook.Config.URL]; dup {
+			extra = append(extra, hook)
+			continue
+		}
+		existing[hook.Config.URL] = hook
+	}
+
+	var errs []error
+	declared := sets.Set[string]{}
+	for _, w := range want {
+		declared.Insert(w.URL)
+		hook, exists := existing[w.URL]
+		if exists && !hookDiffers(hook, w) {
+			continue
+		}
+		req, err := newHookRequest(w)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.URL, err))
+			continue
+		}
+		if !exists {
+			logrus.Infof("Creating webhook %s", w.URL)
+			if err := create(req); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create webhook %s: %w", w.URL, err))
+			}
+			continue
+		}
+		logrus.Infof("Updating webhook %d(%s)", hook.ID, w.URL)
+		if err := edit(hook.ID, req); err != nil {
+			errs = append(errs, fmt.Errorf("failed to edit webhook %d(%s): %w", hook.ID, w.URL, err))
+		}
+	}
+
+	for url, hook := range existing {
+		if !declared.Has(url) {
+			extra = append(extra, hook)
+		}
+	}
+	for _, hook := range extra {
+		logrus.Infof("Deleting webhook %d(%s)", hook.ID, hook.Config.URL)
+		if err := remove(hook); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete webhook %d(%s): %w", hook.ID, hook.Config.URL, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateWebhooks(hooks []config.Webhook) error {
+	seen := sets.Set[string]{}
+	dups := sets.Set[string]{}
+	for _, w := range hooks {
+		if w.URL == "" {
+			return fmt.Errorf("webhooks must specify a url")
+		}
+		if seen.Has(w.URL) {
+			dups.Insert(w.URL)
+		}
+		seen.Insert(w.URL)
+	}
+	if n := len(dups); n > 0 {
+		return fmt.Errorf("%d duplicated webhook urls: %s", n, strings.Join(sets.List(dups), ", "))
+	}
+	return nil
+}
+
+// hookDiffers returns true if any of the declared webhook settings differ.
+// Secrets cannot be compared as GitHub never returns them.
+func hookDiffers(have github.Hook, want config.Webhook) bool {
+	switch {
+	case want.Active != nil && *want.Active != have.Active:
+		return true
+	case want.ContentType != nil && (have.Config.ContentType == nil || *want.ContentType != *have.Config.ContentType):
+		return true
+	case want.Events != nil && !sets.New[string](want.Events...).Equal(sets.New[string](have.Events...)):
+		return true
+	}
+	return false
+}
+
+// newHookRequest returns the request creating or editing a webhook.
+// GitHub replaces the whole webhook config on edits, so the secret is always set.
+func newHookRequest(want config.Webhook) (github.HookRequest, error) {
+	req := github.HookRequest{
+		Name:   webhookName,
+		Active: want.Active,
+		Events: want.Events,
+		Config: &github.HookConfig{
+			URL:         want.URL,
+			ContentType: want.ContentType,
+		},
+	}
+	if want.Secret != nil {
+		secret, err := want.Secret.Resolve()
+		if err != nil {
+			return req, err
+		}
+		req.Config.Secret = &secret
+	}
+	return req, nil
+}
+
+// hookEntry returns the journal entry of a webhook request, without its
+// secret.
+func hookEntry(req github.HookRequest) config.Webhook {
+	return config.Webhook{
+		URL:         req.Config.URL,
+		Events:      req.Events,
+		ContentType: req.Config.ContentType,
+		Active:      req.Active,
+	}
+}
+
+// dumpHooks returns the config of hooks, without their secrets.
+func dumpHooks(hooks []github.Hook) []config.Webhook {
+	out := make([]config.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		active := hook.Active
+		out = append(out, config.Webhook{
+			URL:         hook.Config.URL,
+			Events:      hook.Events,
+			ContentType: hook.Config.ContentType,
+			Active:      &active,
+		})
+	}
+	return out
+}